@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"context"
 	"encoding/csv"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -16,6 +17,7 @@ import (
 	"github.com/drakos74/go-ex-machina/xmachina/ml"
 	"github.com/drakos74/go-ex-machina/xmachina/net"
 	"github.com/drakos74/go-ex-machina/xmachina/net/ff"
+	"github.com/drakos74/go-ex-machina/xmachina/prep"
 	"github.com/drakos74/go-ex-machina/xmath"
 	"github.com/rs/zerolog"
 )
@@ -45,23 +47,18 @@ func main() {
 			Factory(net.NewActivationCell)).
 		Add(10, net.NewBuilder().CellFactory(net.NewSoftCell))
 
-	data := make(xmachina.DataSource)
+	config := xmachina.StreamingTraining(xmachina.Training(0.1, 1).WithEpochs(5), 1000)
 
-	config := xmachina.StreamingTraining(xmachina.Training(0.1, 1), 10, 1000)
+	source := xmachina.FileSource("examples/feedforward/mnist/data/mnist_train.csv", parseMnistLine)
 
-	ack := make(xmachina.Ack)
+	err := xmachina.TrainInStream(context.Background(), config, network, source)
 
-	ctx, cnl := context.WithCancel(context.Background())
-	go xmachina.TrainInStream(ctx, config, network, data, ack)
-
-	_, _, err := xmachina.ReadFile("examples/feedforward/mnist/data/mnist_train.csv", 0, 5, parseMnistLine, data, config.Epoch, ack)
-
-	if err != nil {
-		log.Fatalf("could not read file: %v", err)
+	if errors.Is(err, xmachina.ErrNotConverged) {
+		log.Printf("training stopped: %v", err)
+	} else if err != nil {
+		log.Fatalf("could not train network: %v", err)
 	}
 
-	cnl()
-
 	println(fmt.Sprintf("train duration = %v", time.Since(start)))
 
 	// score the network
@@ -78,8 +75,14 @@ func main() {
 			break
 		}
 		total++
-		inputs, _ := parseMnistLine(record)
-		outputs := network.Predict(inputs)
+		inputs, _, err := parseMnistLine(record)
+		if err != nil {
+			log.Fatalf("could not parse test record: %v", err)
+		}
+		outputs, err := network.Predict(inputs)
+		if err != nil {
+			log.Fatalf("could not predict test record: %v", err)
+		}
 		best := 0
 		highest := 0.0
 		for i := 0; i < len(outputs); i++ {
@@ -98,20 +101,35 @@ func main() {
 
 }
 
-func parseMnistLine(record []string) (inp, out xmath.Vector) {
+// pixelScaler scales the grayscale pixel values from [0,255] to [0.01,1]
+var pixelScaler = &prep.MinMaxScaler{
+	Low:  0.01,
+	High: 1,
+	Min:  xmath.Vec(784),
+	Max:  xmath.Vec(784).Generate(xmath.Const(255)),
+}
+
+func parseMnistLine(record []string) (inp, out xmath.Vector, err error) {
 
 	inp = xmath.Vec(784)
 	for i := range inp {
-		x, _ := strconv.ParseFloat(record[i+1], 64)
-		inp[i] = (x / 255.0 * 0.99) + 0.01
+		x, err := strconv.ParseFloat(record[i+1], 64)
+		if err != nil {
+			return nil, nil, err
+		}
+		inp[i] = x
 	}
+	inp = pixelScaler.Transform(inp)
 
 	out = make([]float64, 10)
 	for i := range out {
 		out[i] = 0.1
 	}
-	x, _ := strconv.Atoi(record[0])
+	x, err := strconv.Atoi(record[0])
+	if err != nil {
+		return nil, nil, err
+	}
 	out[x] = 0.9
 
-	return inp, out
+	return inp, out, nil
 }
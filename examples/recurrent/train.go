@@ -2,6 +2,7 @@ package recurrent
 
 import (
 	"fmt"
+	"log"
 	"math"
 	"strings"
 
@@ -43,15 +44,31 @@ type OutputCapture struct {
 	Network    net.NN
 }
 
+// train trains the network with the given input, failing if the network rejects it.
+func train(network net.NN, input, output xmath.Vector) {
+	if _, _, err := network.Train(input, output); err != nil {
+		log.Fatalf("could not train network: %v", err)
+	}
+}
+
+// predict returns the network prediction for the given input, failing if the network rejects it.
+func predict(network net.NN, input xmath.Vector) xmath.Vector {
+	output, err := network.Predict(input)
+	if err != nil {
+		log.Fatalf("could not predict: %v", err)
+	}
+	return output
+}
+
 func (c *OutputCapture) Train(i int, x, y float64) float64 {
 	next := xmath.Vec(1)
-	c.Network.Train(xmath.Vec(1).With(y), next)
+	train(c.Network, xmath.Vec(1).With(y), next)
 	c.lastOutput = next[0]
 	return c.lastOutput
 }
 
 func (c *OutputCapture) Predict(i int, x, y float64) float64 {
-	next := c.Network.Predict(xmath.Vec(1).With(y))
+	next := predict(c.Network, xmath.Vec(1).With(y))
 	c.lastOutput = next[0]
 	return c.lastOutput
 }
@@ -61,7 +78,7 @@ type EvolutionCapture struct {
 }
 
 func (c *EvolutionCapture) Predict(i int, x, y float64) float64 {
-	next := c.Network.Predict(xmath.Vec(1).With(c.lastOutput))
+	next := predict(c.Network, xmath.Vec(1).With(c.lastOutput))
 	c.lastOutput = next[0]
 	return c.lastOutput
 }
@@ -100,13 +117,13 @@ func eval(vector xmath.Vector) float64 {
 
 func (s SoftCapture) Train(i int, x, y float64) float64 {
 	next := xmath.Vec(2)
-	s.Network.Train(xmath.Vec(1).With(y), next)
+	train(s.Network, xmath.Vec(1).With(y), next)
 	s.lastOutput = eval(next)
 	return s.lastOutput
 }
 
 func (s SoftCapture) Predict(i int, x, y float64) float64 {
-	next := s.Network.Predict(xmath.Vec(1).With(y))
+	next := predict(s.Network, xmath.Vec(1).With(y))
 	s.lastOutput = eval(next)
 	return s.lastOutput
 }
@@ -123,15 +140,19 @@ type VoidNetwork struct {
 	net.NetworkConfig
 }
 
-func (v VoidNetwork) Train(input xmath.Vector, output xmath.Vector) (err xmath.Vector, weights map[net.Meta]net.Weights) {
+func (v VoidNetwork) Train(input xmath.Vector, output xmath.Vector) (loss xmath.Vector, weights map[net.Meta]net.Weights, err error) {
 	for i, inp := range input {
 		output[i] = inp
 	}
-	return xmath.Vec(len(input)), nil
+	return xmath.Vec(len(input)), nil, nil
+}
+
+func (v VoidNetwork) Predict(input xmath.Vector) (xmath.Vector, error) {
+	return input, nil
 }
 
-func (v VoidNetwork) Predict(input xmath.Vector) xmath.Vector {
-	return input
+func (v VoidNetwork) GetInfo() net.Info {
+	return net.Info{}
 }
 
 func Train(l int, xt T, yp P, graph xmachina.Data, cap map[string]Capture) {
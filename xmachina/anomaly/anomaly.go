@@ -0,0 +1,103 @@
+// Package anomaly detects anomalies in streams of values, by scoring them against baselines of the previous ones.
+package anomaly
+
+import (
+	"math"
+	"time"
+
+	"github.com/drakos74/go-ex-machina/xmath/buffer"
+)
+
+// DefaultSensitivity is the score, in standard deviations, above which values are reported as anomalies.
+const DefaultSensitivity = 3.0
+
+// Event is a value that one of the detectors found anomalous.
+type Event struct {
+	Time     time.Time
+	Value    float64
+	Expected float64
+	// Score is the distance of the value from the expected one in standard deviations,
+	// positive for values above and negative for values below it.
+	Score    float64
+	Detector string
+}
+
+// Monitor scores a stream of values with its detectors, and emits the anomalous ones as events.
+type Monitor struct {
+	detectors   []Detector
+	sensitivity float64
+	window      *buffer.TimeWindow
+	value       func(bucket *buffer.Bucket) float64
+	events      chan Event
+}
+
+// New creates a new monitor with the given detectors.
+// The events channel has the given capacity, and pushing values blocks while it is full.
+func New(capacity int, detectors ...Detector) *Monitor {
+	return &Monitor{
+		detectors:   detectors,
+		sensitivity: DefaultSensitivity,
+		value: func(bucket *buffer.Bucket) float64 {
+			return bucket.Values().Stats()[0].Avg()
+		},
+		events: make(chan Event, capacity),
+	}
+}
+
+// WithSensitivity sets the absolute score above which values are anomalies.
+// Lower values report more anomalies.
+func (m *Monitor) WithSensitivity(sensitivity float64) *Monitor {
+	m.sensitivity = sensitivity
+	return m
+}
+
+// WithWindow aggregates the values in a time window of the given duration,
+// so that the detectors score the value of each bucket, when it closes.
+// By default buckets are scored by their average.
+func (m *Monitor) WithWindow(duration time.Duration) *Monitor {
+	m.window = buffer.NewTimeWindow(duration)
+	return m
+}
+
+// WithBucketValue defines the value of each bucket for the scoring.
+func (m *Monitor) WithBucketValue(value func(bucket *buffer.Bucket) float64) *Monitor {
+	m.value = value
+	return m
+}
+
+// Events returns the channel of the anomaly events.
+func (m *Monitor) Events() <-chan Event {
+	return m.events
+}
+
+// Close closes the events channel, after the last value is pushed.
+func (m *Monitor) Close() {
+	close(m.events)
+}
+
+// Push adds the next value of the stream at the given time.
+// It returns the number of anomaly events it emitted.
+func (m *Monitor) Push(t time.Time, v float64) int {
+	if m.window != nil {
+		tt, bucket, ok := m.window.Push(t, v)
+		if !ok {
+			return 0
+		}
+		t, v = tt, m.value(bucket)
+	}
+	var count int
+	for _, d := range m.detectors {
+		score, expected, ok := d.Score(t, v)
+		if ok && math.Abs(score) >= m.sensitivity {
+			m.events <- Event{
+				Time:     t,
+				Value:    v,
+				Expected: expected,
+				Score:    score,
+				Detector: d.Name(),
+			}
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,174 @@
+package anomaly
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/drakos74/go-ex-machina/xmachina/net"
+	"github.com/drakos74/go-ex-machina/xmachina/net/rc"
+	"github.com/drakos74/go-ex-machina/xmath"
+	"github.com/stretchr/testify/assert"
+)
+
+// collect pushes the values every minute from the start, and returns the events of the monitor.
+func collect(m *Monitor, start time.Time, values []float64) []Event {
+	events := make([]Event, 0)
+	done := make(chan struct{})
+	go func() {
+		for e := range m.Events() {
+			events = append(events, e)
+		}
+		close(done)
+	}()
+	for i, v := range values {
+		m.Push(start.Add(time.Duration(i)*time.Minute), v)
+	}
+	m.Close()
+	<-done
+	return events
+}
+
+func noise(n int, level func(i int) float64) []float64 {
+	rng := rand.New(rand.NewSource(1))
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = level(i) + 0.1*rng.NormFloat64()
+	}
+	return values
+}
+
+func TestMonitor_Detectors(t *testing.T) {
+
+	type test struct {
+		detector Detector
+		level    func(i int) float64
+		spike    int
+	}
+
+	tests := map[string]test{
+		"z-score": {
+			detector: NewZScore(10),
+			level: func(i int) float64 {
+				return 1
+			},
+			spike: 500,
+		},
+		"ewma-drift": {
+			// the baseline follows the slow drift of the level
+			detector: NewEWMA(0.1, 10),
+			level: func(i int) float64 {
+				return 0.01 * float64(i)
+			},
+			spike: 500,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			values := noise(1000, tt.level)
+			values[tt.spike] += 5
+			events := collect(New(10, tt.detector).WithSensitivity(4), time.Unix(0, 0), values)
+
+			found := false
+			for _, e := range events {
+				if e.Value == values[tt.spike] {
+					found = true
+					assert.Equal(t, tt.detector.Name(), e.Detector)
+					assert.True(t, e.Score > 4)
+					assert.InDelta(t, tt.level(tt.spike), e.Expected, 2)
+				}
+			}
+			assert.True(t, found)
+			assert.Equal(t, 1, len(events))
+		})
+	}
+
+}
+
+func TestMonitor_Seasonal(t *testing.T) {
+
+	// a daily pattern with values rising throughout the day
+	level := func(i int) float64 {
+		return float64(i % 24)
+	}
+	values := noise(24*30, level)
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	// a value that would be normal in the evening, but not at night
+	spike := 24*20 + 3
+	values[spike] = 20
+
+	push := func(m *Monitor) []Event {
+		events := make([]Event, 0)
+		go func() {
+			for i, v := range values {
+				m.Push(start.Add(time.Duration(i)*time.Hour), v)
+			}
+			m.Close()
+		}()
+		for e := range m.Events() {
+			events = append(events, e)
+		}
+		return events
+	}
+
+	events := push(New(10, NewSeasonal(HourOfDay, 10)).WithSensitivity(5))
+	assert.Equal(t, 1, len(events))
+	assert.Equal(t, start.Add(time.Duration(spike)*time.Hour), events[0].Time)
+	assert.InDelta(t, 3, events[0].Expected, 0.1)
+
+	// the overall baseline does not see it
+	assert.Equal(t, 0, len(push(New(10, NewZScore(10)).WithSensitivity(5))))
+
+}
+
+func TestMonitor_Window(t *testing.T) {
+
+	values := noise(600, func(i int) float64 {
+		return 1
+	})
+	// a whole bucket of higher values
+	for i := 300; i < 310; i++ {
+		values[i] += 1
+	}
+
+	m := New(10, NewZScore(10)).WithWindow(10 * time.Minute).WithSensitivity(6)
+	events := collect(m, time.Unix(6000, 0), values)
+
+	assert.Equal(t, 1, len(events))
+	assert.Equal(t, time.Unix(6000+300*60, 0), events[0].Time)
+	assert.InDelta(t, 2, events[0].Value, 0.1)
+
+}
+
+// the recurrent networks can be used as forecasters
+var _ Forecaster = &rc.Network{}
+
+// lastValue is a forecaster that predicts the last value it has seen.
+type lastValue struct{}
+
+func (l lastValue) Train(input xmath.Vector, output xmath.Vector) (xmath.Vector, map[net.Meta]net.Weights, error) {
+	copy(output, input)
+	return xmath.Vec(1), nil, nil
+}
+
+func TestForecast(t *testing.T) {
+
+	// a sine wave that the forecaster follows closely
+	values := make([]float64, 1000)
+	for i := range values {
+		values[i] = math.Sin(0.1 * float64(i))
+	}
+	values[500] += 0.5
+
+	events := collect(New(10, NewForecast(lastValue{}, 100)), time.Unix(0, 0), values)
+
+	// the spike, and the return from it
+	assert.Equal(t, 2, len(events))
+	assert.Equal(t, values[500], events[0].Value)
+	assert.Equal(t, values[499], events[0].Expected)
+	assert.True(t, events[0].Score > 0)
+	assert.True(t, events[1].Score < 0)
+
+}
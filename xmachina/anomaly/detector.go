@@ -0,0 +1,198 @@
+package anomaly
+
+import (
+	"math"
+	"time"
+
+	"github.com/drakos74/go-ex-machina/xmachina/net"
+	"github.com/drakos74/go-ex-machina/xmath"
+	"github.com/drakos74/go-ex-machina/xmath/buffer"
+	"github.com/rs/zerolog/log"
+)
+
+// Detector scores values against a baseline it maintains out of the previous ones.
+type Detector interface {
+	// Name identifies the detector in the events.
+	Name() string
+	// Score returns how many standard deviations the value is away from the expected one,
+	// and updates the baseline with it.
+	// It returns false while the baseline is still warming up.
+	Score(t time.Time, v float64) (score, expected float64, ok bool)
+}
+
+// score returns the distance of the value from the mean in standard deviations.
+// A value off a constant baseline gets an infinite score.
+func score(v, mean, std float64) float64 {
+	if std == 0 {
+		if v == mean {
+			return 0
+		}
+		return math.Copysign(math.Inf(1), v-mean)
+	}
+	return (v - mean) / std
+}
+
+// ZScore scores values against the mean and standard deviation of all previous values.
+type ZScore struct {
+	warmup int
+	stats  *buffer.Stats
+}
+
+// NewZScore creates a z-score detector, which starts scoring after the given number of values.
+func NewZScore(warmup int) *ZScore {
+	return &ZScore{
+		warmup: warmup,
+		stats:  buffer.NewStats(),
+	}
+}
+
+// Name identifies the detector in the events.
+func (z *ZScore) Name() string {
+	return "z-score"
+}
+
+// Score returns the z-score of the value, and adds it to the baseline.
+func (z *ZScore) Score(t time.Time, v float64) (float64, float64, bool) {
+	defer z.stats.Push(v)
+	if z.stats.Count() < z.warmup || z.stats.Count() == 0 {
+		return 0, 0, false
+	}
+	return score(v, z.stats.Avg(), z.stats.StDev()), z.stats.Avg(), true
+}
+
+// EWMA scores values against exponentially weighted control limits,
+// so that the baseline follows slow changes of the level of the series.
+type EWMA struct {
+	warmup int
+	stats  *buffer.Stats
+}
+
+// NewEWMA creates an ewma control chart detector with the given smoothing factor,
+// which starts scoring after the given number of values.
+func NewEWMA(alpha float64, warmup int) *EWMA {
+	return &EWMA{
+		warmup: warmup,
+		stats:  buffer.NewStats().WithEWMA(alpha),
+	}
+}
+
+// Name identifies the detector in the events.
+func (e *EWMA) Name() string {
+	return "ewma"
+}
+
+// Score returns the distance of the value from the exponentially weighted mean, and adds it to the baseline.
+func (e *EWMA) Score(t time.Time, v float64) (float64, float64, bool) {
+	defer e.stats.Push(v)
+	if e.stats.Count() < e.warmup || e.stats.Count() == 0 {
+		return 0, 0, false
+	}
+	return score(v, e.stats.EWMA(), math.Sqrt(e.stats.EWVariance())), e.stats.EWMA(), true
+}
+
+// Season maps a time to its slot within a seasonal cycle.
+type Season func(t time.Time) int
+
+// HourOfDay is the daily season, with a slot for every hour.
+var HourOfDay Season = func(t time.Time) int {
+	return t.Hour()
+}
+
+// DayOfWeek is the weekly season, with a slot for every day.
+var DayOfWeek Season = func(t time.Time) int {
+	return int(t.Weekday())
+}
+
+// HourOfWeek is the weekly season, with a slot for every hour.
+var HourOfWeek Season = func(t time.Time) int {
+	return 24*int(t.Weekday()) + t.Hour()
+}
+
+// Seasonal scores values against a separate baseline for each slot of the season,
+// e.g. values at 09:00 are compared only with previous values at 09:00.
+type Seasonal struct {
+	season Season
+	warmup int
+	stats  map[int]*buffer.Stats
+}
+
+// NewSeasonal creates a seasonal detector, which starts scoring each slot after the given number of values in it.
+func NewSeasonal(season Season, warmup int) *Seasonal {
+	return &Seasonal{
+		season: season,
+		warmup: warmup,
+		stats:  make(map[int]*buffer.Stats),
+	}
+}
+
+// Name identifies the detector in the events.
+func (s *Seasonal) Name() string {
+	return "seasonal"
+}
+
+// Score returns the z-score of the value within its slot, and adds it to the slot baseline.
+func (s *Seasonal) Score(t time.Time, v float64) (float64, float64, bool) {
+	slot := s.season(t)
+	stats, ok := s.stats[slot]
+	if !ok {
+		stats = buffer.NewStats()
+		s.stats[slot] = stats
+	}
+	defer stats.Push(v)
+	if stats.Count() < s.warmup || stats.Count() == 0 {
+		return 0, 0, false
+	}
+	return score(v, stats.Avg(), stats.StDev()), stats.Avg(), true
+}
+
+// Forecaster is a model that predicts the next value of a series while it trains on it, as the rc.Network.
+type Forecaster interface {
+	Train(input xmath.Vector, output xmath.Vector) (loss xmath.Vector, weights map[net.Meta]net.Weights, err error)
+}
+
+// Forecast scores values by their residual from the prediction of a forecaster,
+// against the mean and standard deviation of the previous residuals.
+type Forecast struct {
+	model      Forecaster
+	warmup     int
+	count      int
+	prediction xmath.Vector
+	residuals  *buffer.Stats
+}
+
+// NewForecast creates a detector on the residuals of the forecaster, for a series of single values.
+// The forecaster keeps training on the values.
+// The residuals of the first warmup values are ignored, and the next warmup residuals build the baseline before the scoring starts.
+func NewForecast(model Forecaster, warmup int) *Forecast {
+	return &Forecast{
+		model:      model,
+		warmup:     warmup,
+		prediction: xmath.Vec(1),
+		residuals:  buffer.NewStats(),
+	}
+}
+
+// Name identifies the detector in the events.
+func (f *Forecast) Name() string {
+	return "forecast"
+}
+
+// Score returns the z-score of the residual of the value, and trains the forecaster on it.
+func (f *Forecast) Score(t time.Time, v float64) (float64, float64, bool) {
+	expected := f.prediction[0]
+	residual := v - expected
+	f.count++
+	if _, _, err := f.model.Train(xmath.Vec(1).With(v), f.prediction); err != nil {
+		log.Error().Err(err).Float64("value", v).Msg("could not train forecaster")
+		return 0, 0, false
+	}
+	if f.count <= f.warmup {
+		// the first predictions are not reliable, so we dont use them for the baseline either
+		return 0, 0, false
+	}
+	defer f.residuals.Push(residual)
+	if f.residuals.Count() < f.warmup || f.residuals.Count() == 0 {
+		return 0, 0, false
+	}
+	return score(residual, f.residuals.Avg(), f.residuals.StDev()), expected, true
+}
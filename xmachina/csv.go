@@ -0,0 +1,390 @@
+package xmachina
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/drakos74/go-ex-machina/xmath"
+	"github.com/drakos74/go-ex-machina/xmath/buffer"
+)
+
+// ErrMissingValue is returned when a record has a missing value and the column does not allow filling it.
+var ErrMissingValue = errors.New("missing value")
+
+// ColumnType defines how the values of a column are interpreted.
+type ColumnType string
+
+const (
+	// Numeric columns are parsed as floats.
+	Numeric ColumnType = "numeric"
+	// OneHot columns are categorical and encoded as a vector with a 1 at the category position.
+	OneHot ColumnType = "one-hot"
+	// Index columns are categorical and encoded as the index of the category.
+	Index ColumnType = "index"
+)
+
+// Fill defines how missing values are replaced.
+// Index columns can only drop the missing values, as any fill value would be the same as one of the categories.
+type Fill string
+
+const (
+	// FillZero replaces missing values with zero.
+	FillZero Fill = "zero"
+	// FillMean replaces missing numeric values with the mean of the column.
+	FillMean Fill = "mean"
+	// FillDrop drops the records with missing values.
+	FillDrop Fill = "drop"
+)
+
+// Column describes the position and encoding of a column within a record.
+type Column struct {
+	Name       string     `json:"name"`
+	Position   int        `json:"position"`
+	Type       ColumnType `json:"type"`
+	Categories []string   `json:"categories,omitempty"`
+	Fill       Fill       `json:"fill"`
+	Value      float64    `json:"value"`
+}
+
+// Size returns the number of vector elements the column is encoded into.
+func (c Column) Size() int {
+	if c.Type == OneHot {
+		return len(c.Categories)
+	}
+	return 1
+}
+
+// validate checks that the column type and fill are consistent.
+func (c Column) validate() error {
+	switch c.Type {
+	case Numeric, OneHot:
+	case Index:
+		if c.Fill != FillDrop {
+			return fmt.Errorf("column '%s': cannot fill missing values of index column with '%s', as it would be the same as a category", c.Name, c.Fill)
+		}
+	default:
+		return fmt.Errorf("column '%s': unknown column type '%s'", c.Name, c.Type)
+	}
+	return nil
+}
+
+func (c Column) encode(value string, missing map[string]bool, v xmath.Vector) error {
+	if missing[value] {
+		if err := c.validate(); err != nil {
+			return err
+		}
+		switch {
+		case c.Fill == FillDrop:
+			return fmt.Errorf("column '%s': %w", c.Name, ErrMissingValue)
+		case c.Type == OneHot:
+			// leave all elements to zero
+			return nil
+		default:
+			v[0] = c.Value
+			return nil
+		}
+	}
+	switch c.Type {
+	case Numeric:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("column '%s': %w", c.Name, err)
+		}
+		v[0] = f
+	case Index, OneHot:
+		i := sort.SearchStrings(c.Categories, value)
+		if i >= len(c.Categories) || c.Categories[i] != value {
+			return fmt.Errorf("column '%s': unknown category '%s'", c.Name, value)
+		}
+		if c.Type == OneHot {
+			v[i] = 1
+		} else {
+			v[0] = float64(i)
+		}
+	default:
+		return fmt.Errorf("column '%s': unknown column type '%s'", c.Name, c.Type)
+	}
+	return nil
+}
+
+// Schema describes how raw records are mapped to input and output vectors.
+// It is serializable, so that it can be persisted along with the model
+// and re-used to parse new records in the same way.
+type Schema struct {
+	Features []Column `json:"features"`
+	Targets  []Column `json:"targets"`
+	Missing  []string `json:"missing"`
+}
+
+// InputSize returns the size of the input vectors.
+func (s Schema) InputSize() int {
+	return size(s.Features)
+}
+
+// OutputSize returns the size of the output vectors.
+func (s Schema) OutputSize() int {
+	return size(s.Targets)
+}
+
+// Validate checks that the columns of the schema can be encoded.
+func (s Schema) Validate() error {
+	for _, c := range append(append([]Column{}, s.Features...), s.Targets...) {
+		if err := c.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Parse parses the record into the input and output vectors according to the schema.
+// It can be used directly as a Parse func for a Source.
+func (s Schema) Parse(record []string) (inp, out xmath.Vector, err error) {
+	missing := make(map[string]bool, len(s.Missing))
+	for _, m := range s.Missing {
+		missing[m] = true
+	}
+	inp, err = encode(s.Features, record, missing)
+	if err != nil {
+		return nil, nil, err
+	}
+	out, err = encode(s.Targets, record, missing)
+	if err != nil {
+		return nil, nil, err
+	}
+	return inp, out, nil
+}
+
+func size(columns []Column) int {
+	var s int
+	for _, c := range columns {
+		s += c.Size()
+	}
+	return s
+}
+
+func encode(columns []Column, record []string, missing map[string]bool) (xmath.Vector, error) {
+	v := xmath.Vec(size(columns))
+	var i int
+	for _, c := range columns {
+		if c.Position >= len(record) {
+			return nil, fmt.Errorf("column '%s': record has only %d fields", c.Name, len(record))
+		}
+		if err := c.encode(strings.TrimSpace(record[c.Position]), missing, v[i:i+c.Size()]); err != nil {
+			return nil, err
+		}
+		i += c.Size()
+	}
+	return v, nil
+}
+
+// Dataset is an in-memory data set of input and output vectors, along with the schema that produced it.
+type Dataset struct {
+	Schema    Schema
+	InputSet  xmath.Matrix
+	OutputSet xmath.Matrix
+}
+
+// Source returns a source for streaming training over the data set.
+func (d *Dataset) Source() Source {
+	return MemSource(d.InputSet, d.OutputSet)
+}
+
+type column struct {
+	name string
+	t    ColumnType
+	fill Fill
+}
+
+// CSVLoader loads csv data into a Dataset, based on the named feature and target columns.
+type CSVLoader struct {
+	comma    rune
+	comment  rune
+	header   bool
+	missing  []string
+	features []column
+	targets  []column
+}
+
+// NewCSVLoader creates a new csv loader expecting a header line and comma separated values.
+func NewCSVLoader() *CSVLoader {
+	return &CSVLoader{
+		comma:   ',',
+		comment: '#',
+		header:  true,
+		missing: []string{""},
+	}
+}
+
+// WithComma defines the field delimiter.
+func (l *CSVLoader) WithComma(comma rune) *CSVLoader {
+	l.comma = comma
+	return l
+}
+
+// WithComment defines the character marking comment lines.
+func (l *CSVLoader) WithComment(comment rune) *CSVLoader {
+	l.comment = comment
+	return l
+}
+
+// WithoutHeader defines that the data has no header line.
+// Columns are then named after their position e.g. "0", "1" etc ...
+func (l *CSVLoader) WithoutHeader() *CSVLoader {
+	l.header = false
+	return l
+}
+
+// WithMissing defines the values that should be treated as missing.
+func (l *CSVLoader) WithMissing(values ...string) *CSVLoader {
+	l.missing = values
+	return l
+}
+
+// Feature adds the named column to the input vectors.
+func (l *CSVLoader) Feature(name string, t ColumnType, fill Fill) *CSVLoader {
+	l.features = append(l.features, column{name: name, t: t, fill: fill})
+	return l
+}
+
+// Target adds the named column to the output vectors.
+func (l *CSVLoader) Target(name string, t ColumnType, fill Fill) *CSVLoader {
+	l.targets = append(l.targets, column{name: name, t: t, fill: fill})
+	return l
+}
+
+// LoadFile loads the data set from the given file.
+func (l *CSVLoader) LoadFile(filename string) (*Dataset, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return l.Load(f)
+}
+
+// Load loads the data set from the given reader.
+func (l *CSVLoader) Load(r io.Reader) (*Dataset, error) {
+
+	reader := csv.NewReader(r)
+	reader.Comma = l.comma
+	reader.Comment = l.comment
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("could not read csv: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("no records found")
+	}
+
+	header := make([]string, len(records[0]))
+	for i := range header {
+		header[i] = strconv.Itoa(i)
+	}
+	if l.header {
+		for i, name := range records[0] {
+			header[i] = strings.TrimSpace(name)
+		}
+		records = records[1:]
+	}
+
+	schema := Schema{
+		Missing: l.missing,
+	}
+	if schema.Features, err = l.columns(l.features, header, records); err != nil {
+		return nil, err
+	}
+	if schema.Targets, err = l.columns(l.targets, header, records); err != nil {
+		return nil, err
+	}
+	if err := schema.Validate(); err != nil {
+		return nil, fmt.Errorf("could not validate schema: %w", err)
+	}
+
+	dataset := &Dataset{
+		Schema:    schema,
+		InputSet:  xmath.Mat(0),
+		OutputSet: xmath.Mat(0),
+	}
+	for i, record := range records {
+		inp, out, err := schema.Parse(record)
+		if errors.Is(err, ErrMissingValue) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not parse record %d: %w", i, err)
+		}
+		dataset.InputSet = append(dataset.InputSet, inp)
+		dataset.OutputSet = append(dataset.OutputSet, out)
+	}
+	return dataset, nil
+}
+
+// columns resolves the column positions and derives the categories and fill values from the records.
+func (l *CSVLoader) columns(cols []column, header []string, records [][]string) ([]Column, error) {
+
+	missing := make(map[string]bool, len(l.missing))
+	for _, m := range l.missing {
+		missing[m] = true
+	}
+
+	columns := make([]Column, len(cols))
+	for i, col := range cols {
+		position := -1
+		for j, name := range header {
+			if name == col.name {
+				position = j
+				break
+			}
+		}
+		if position < 0 {
+			return nil, fmt.Errorf("column '%s' not found in %v", col.name, header)
+		}
+
+		c := Column{
+			Name:     col.name,
+			Position: position,
+			Type:     col.t,
+			Fill:     col.fill,
+		}
+
+		stats := buffer.NewStats()
+		categories := make(map[string]struct{})
+		for _, record := range records {
+			if position >= len(record) {
+				continue
+			}
+			value := strings.TrimSpace(record[position])
+			if missing[value] {
+				continue
+			}
+			switch col.t {
+			case Numeric:
+				if f, err := strconv.ParseFloat(value, 64); err == nil {
+					stats.Push(f)
+				}
+			case Index, OneHot:
+				categories[value] = struct{}{}
+			}
+		}
+
+		for category := range categories {
+			c.Categories = append(c.Categories, category)
+		}
+		sort.Strings(c.Categories)
+
+		if col.fill == FillMean && col.t == Numeric {
+			c.Value = stats.Avg()
+		}
+
+		columns[i] = c
+	}
+	return columns, nil
+}
@@ -0,0 +1,131 @@
+package xmachina
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/drakos74/go-ex-machina/xmath"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSVLoader_Load(t *testing.T) {
+
+	dataset, err := NewCSVLoader().
+		Feature("height", Numeric, FillDrop).
+		Feature("weight", Numeric, FillMean).
+		Feature("colour", OneHot, FillZero).
+		Feature("size", Index, FillDrop).
+		Target("label", Index, FillDrop).
+		LoadFile("test/testdata/schema_input.csv")
+	assert.NoError(t, err)
+
+	// the record with the missing height is dropped
+	assert.Equal(t, 4, len(dataset.InputSet))
+	assert.Equal(t, 4, len(dataset.OutputSet))
+
+	assert.Equal(t, 6, dataset.Schema.InputSize())
+	assert.Equal(t, 1, dataset.Schema.OutputSize())
+
+	assert.Equal(t, []string{"blue", "green", "red"}, dataset.Schema.Features[2].Categories)
+	assert.Equal(t, []string{"L", "M", "S"}, dataset.Schema.Features[3].Categories)
+
+	assert.Equal(t, xmath.Vec(6).With(1.5, 60, 0, 0, 1, 2), dataset.InputSet[0])
+	// missing weight is replaced with the mean
+	assert.Equal(t, xmath.Vec(6).With(1.7, 71.25, 1, 0, 0, 1), dataset.InputSet[1])
+	assert.Equal(t, xmath.Vec(6).With(1.9, 90, 0, 1, 0, 0), dataset.InputSet[2])
+	// missing colour leaves the one-hot encoding empty
+	assert.Equal(t, xmath.Vec(6).With(1.6, 65, 0, 0, 0, 2), dataset.InputSet[3])
+
+	assert.Equal(t, xmath.Mat(4).With(
+		xmath.Vec(1).With(0),
+		xmath.Vec(1).With(1),
+		xmath.Vec(1).With(1),
+		xmath.Vec(1).With(0),
+	), dataset.OutputSet)
+
+}
+
+func TestSchema_Parse(t *testing.T) {
+
+	dataset, err := NewCSVLoader().
+		Feature("colour", OneHot, FillZero).
+		Target("label", OneHot, FillDrop).
+		LoadFile("test/testdata/schema_input.csv")
+	assert.NoError(t, err)
+
+	// the schema should be re-usable after persisting it
+	b, err := json.Marshal(dataset.Schema)
+	assert.NoError(t, err)
+
+	var schema Schema
+	err = json.Unmarshal(b, &schema)
+	assert.NoError(t, err)
+	assert.Equal(t, dataset.Schema, schema)
+
+	inp, out, err := schema.Parse([]string{"1.8", "80", "green", "L", "yes"})
+	assert.NoError(t, err)
+	assert.Equal(t, xmath.Vec(3).With(0, 1, 0), inp)
+	assert.Equal(t, xmath.Vec(2).With(0, 1), out)
+
+	_, _, err = schema.Parse([]string{"1.8", "80", "black", "L", "yes"})
+	assert.Error(t, err)
+
+	_, _, err = schema.Parse([]string{"1.8", "80", "green", "L", ""})
+	assert.True(t, errors.Is(err, ErrMissingValue))
+
+	_, _, err = schema.Parse([]string{"1.8", "80"})
+	assert.Error(t, err)
+
+	// a missing category cannot be filled in for an index column, as it would look like a real one
+	schema.Features = append(schema.Features, Column{Name: "size", Position: 3, Type: Index, Categories: []string{"L", "M", "S"}, Fill: FillZero})
+	assert.Error(t, schema.Validate())
+	_, _, err = schema.Parse([]string{"1.8", "80", "green", "", "yes"})
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, ErrMissingValue))
+
+}
+
+func TestCSVLoader_Errors(t *testing.T) {
+
+	type test struct {
+		data   string
+		loader *CSVLoader
+	}
+
+	tests := map[string]test{
+		"empty": {
+			data:   "",
+			loader: NewCSVLoader().Feature("x", Numeric, FillZero),
+		},
+		"unknown-column": {
+			data:   "x,y\n1,2\n",
+			loader: NewCSVLoader().Feature("z", Numeric, FillZero),
+		},
+		"non-numeric": {
+			data:   "x,y\n1,a\n",
+			loader: NewCSVLoader().Feature("x", Numeric, FillZero).Target("y", Numeric, FillZero),
+		},
+		"index-fill-zero": {
+			data:   "x,y\n1,a\n2,\n",
+			loader: NewCSVLoader().Feature("x", Numeric, FillZero).Target("y", Index, FillZero),
+		},
+		"index-fill-mean": {
+			data:   "x,y\n1,a\n2,b\n",
+			loader: NewCSVLoader().Feature("y", Index, FillMean).Target("x", Numeric, FillZero),
+		},
+		"unknown-type": {
+			data:   "x,y\n1,a\n",
+			loader: NewCSVLoader().Feature("y", "text", FillDrop).Target("x", Numeric, FillZero),
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := tt.loader.Load(strings.NewReader(tt.data))
+			assert.Error(t, err)
+		})
+	}
+
+}
@@ -5,71 +5,155 @@ import (
 	"encoding/csv"
 	"fmt"
 	"io"
-	"log"
 	"os"
-	"sync/atomic"
 
 	"github.com/drakos74/go-ex-machina/xmath"
 )
 
-func ReadFile(filename string, lines int, iterations int, parse func(record []string) (inp, out xmath.Vector), data DataSource, epoch Epoch, ack Ack) (inputSet, outputSet xmath.Matrix, readErr error) {
+// Parse parses a raw record into an input and output vector pair.
+type Parse func(record []string) (inp, out xmath.Vector, err error)
 
-	// TODO: fix this ugliness with the line parameter
-	if lines > 0 {
-		inputSet = xmath.Mat(lines)
-		outputSet = xmath.Mat(lines)
+// Iterator iterates over the input and output pairs of a data set.
+type Iterator interface {
+	// Next returns the next input and output pair.
+	// It returns io.EOF when there are no more elements.
+	Next() (inp, out xmath.Vector, err error)
+	// Close releases the underlying resources.
+	Close() error
+}
+
+// Source is a re-usable source of training data.
+// Every call to Open starts a new pass over the data e.g. a new epoch.
+type Source interface {
+	Open() (Iterator, error)
+}
+
+// ReaderSource creates a csv source out of the given open func.
+// The open func is called at the start of each pass over the data.
+func ReaderSource(open func() (io.ReadCloser, error), parse Parse) Source {
+	return &csvSource{
+		open:  open,
+		parse: parse,
 	}
+}
 
-	for i := 0; i < iterations; i++ {
+// FileSource creates a csv source reading from the given file.
+func FileSource(filename string, parse Parse) Source {
+	return ReaderSource(func() (io.ReadCloser, error) {
+		return os.Open(filename)
+	}, parse)
+}
 
-		var c int32
+type csvSource struct {
+	open  func() (io.ReadCloser, error)
+	parse Parse
+}
 
-		testFile, err := os.Open(filename)
-		if err != nil {
-			return inputSet, outputSet, err
-		}
-		rTrain := csv.NewReader(bufio.NewReader(testFile))
+// Open opens the underlying reader and returns an iterator over its records.
+func (s *csvSource) Open() (Iterator, error) {
+	rc, err := s.open()
+	if err != nil {
+		return nil, fmt.Errorf("could not open source: %w", err)
+	}
+	return &csvIterator{
+		closer: rc,
+		reader: csv.NewReader(bufio.NewReader(rc)),
+		parse:  s.parse,
+	}, nil
+}
 
-		for {
+type csvIterator struct {
+	line   int
+	closer io.Closer
+	reader *csv.Reader
+	parse  Parse
+}
 
-			record, err := rTrain.Read()
+// Next reads and parses the next csv record.
+func (it *csvIterator) Next() (inp, out xmath.Vector, err error) {
+	record, err := it.reader.Read()
+	if err == io.EOF {
+		return nil, nil, io.EOF
+	}
+	it.line++
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not read record %d: %w", it.line, err)
+	}
+	inp, out, err = it.parse(record)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not parse record %d: %w", it.line, err)
+	}
+	return inp, out, nil
+}
 
-			if err == io.EOF {
-				break
-			}
+// Close closes the underlying reader.
+func (it *csvIterator) Close() error {
+	return it.closer.Close()
+}
 
-			inp, out := parse(record)
+// MemSource creates a source out of the given in-memory data set.
+func MemSource(inputSet, outputSet xmath.Matrix) Source {
+	xmath.MustHaveDim(outputSet, len(inputSet))
+	return &memSource{
+		inputSet:  inputSet,
+		outputSet: outputSet,
+	}
+}
 
-			if lines > 0 {
-				inputSet[c] = inp
-				outputSet[c] = out
-			}
+type memSource struct {
+	inputSet, outputSet xmath.Matrix
+}
 
-			atomic.AddInt32(&c, 1)
+// Open returns an iterator starting from the first element of the data set.
+func (s *memSource) Open() (Iterator, error) {
+	return &memIterator{source: s}, nil
+}
 
-			data <- Pair{
-				input:  inp,
-				output: out,
-			}
+type memIterator struct {
+	i      int
+	source *memSource
+}
 
-		}
+// Next returns the next element of the data set.
+func (it *memIterator) Next() (inp, out xmath.Vector, err error) {
+	if it.i >= len(it.source.inputSet) {
+		return nil, nil, io.EOF
+	}
+	inp = it.source.inputSet[it.i]
+	out = it.source.outputSet[it.i]
+	it.i++
+	return inp, out, nil
+}
 
-		readErr = testFile.Close()
-		if readErr != nil {
-			return inputSet, outputSet, fmt.Errorf("could not close file: %w", readErr)
-		}
+// Close does nothing for in-memory data sets.
+func (it *memIterator) Close() error {
+	return nil
+}
 
-		epoch <- i
+// ReadAll reads a full pass of the given source into memory.
+func ReadAll(source Source) (inputSet, outputSet xmath.Matrix, err error) {
+	it, err := source.Open()
+	if err != nil {
+		return nil, nil, err
+	}
 
-		// wait for the acknowledgement
-		err = <-ack
-		log.Println(fmt.Sprintf("err = %v", err))
-		if err == nil {
+	inputSet = xmath.Mat(0)
+	outputSet = xmath.Mat(0)
+	for {
+		inp, out, err := it.Next()
+		if err == io.EOF {
 			break
 		}
-
+		if err != nil {
+			_ = it.Close()
+			return nil, nil, err
+		}
+		inputSet = append(inputSet, inp)
+		outputSet = append(outputSet, out)
 	}
 
+	if err := it.Close(); err != nil {
+		return nil, nil, fmt.Errorf("could not close source: %w", err)
+	}
 	return inputSet, outputSet, nil
-
 }
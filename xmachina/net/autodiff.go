@@ -0,0 +1,92 @@
+package net
+
+import (
+	"github.com/drakos74/go-ex-machina/xmachina/ml"
+	"github.com/drakos74/go-ex-machina/xmath"
+	"github.com/drakos74/go-ex-machina/xmath/autodiff"
+)
+
+// Record applies the operation on the node and records it on the tape,
+// so that its backward pass is driven by the tape.
+func Record(op Op, x *autodiff.Node) *autodiff.Node {
+	return autodiff.Apply(op.Fwd(x.Value()), func(dy xmath.Vector) []xmath.Vector {
+		return []xmath.Vector{op.Bwd(dy)}
+	}, x)
+}
+
+// RecordBi applies the operation on the nodes and records it on the tape,
+// so that its backward pass is driven by the tape.
+func RecordBi(op BiOp, a, b *autodiff.Node) *autodiff.Node {
+	return autodiff.Apply(op.Fwd(a.Value(), b.Value()), func(dy xmath.Vector) []xmath.Vector {
+		da, db := op.Bwd(dy)
+		return []xmath.Vector{da, db}
+	}, a, b)
+}
+
+// Activate applies the activation function of the module on the node.
+func Activate(x *autodiff.Node, activation ml.Activation) *autodiff.Node {
+	return autodiff.Map(x, activation.F, activation.D)
+}
+
+// Forward defines the forward computation of a neuron out of its weights, bias and input.
+type Forward func(module ml.Module, w, b, x *autodiff.Node) *autodiff.Node
+
+// Perceptron is the forward computation of the activation cell e.g. F(W*x + b).
+var Perceptron Forward = func(module ml.Module, w, b, x *autodiff.Node) *autodiff.Node {
+	return Activate(autodiff.Add(autodiff.MatMul(w, x), b), module)
+}
+
+// AutoCell is a neuron defined only by its forward computation.
+// The backward pass, and the weight updates, are derived from the gradients on the tape.
+type AutoCell struct {
+	learning   ml.Module
+	weights    *Weights
+	meta       Meta
+	forward    Forward
+	tape       *autodiff.Tape
+	w, b, x, y *autodiff.Node
+}
+
+// NewAutoCell creates a neuron constructor for the given forward computation.
+func NewAutoCell(forward Forward) NeuronConstructor {
+	return func(n, m int, module ml.Module, weights *Weights, meta Meta) Neuron {
+		return &AutoCell{
+			learning: module,
+			weights:  weights,
+			meta:     meta,
+			forward:  forward,
+			tape:     autodiff.NewTape(),
+		}
+	}
+}
+
+// Fwd records the forward computation of the neuron on a new tape.
+func (n *AutoCell) Fwd(v xmath.Vector) xmath.Vector {
+	_, cols := n.weights.W.Dims()
+	xmath.MustHaveSize(v, cols)
+	n.tape.Reset()
+	n.w = n.tape.Dense(n.weights.W)
+	n.b = n.tape.Var(n.weights.B.Copy())
+	n.x = n.tape.Var(v)
+	n.y = n.forward(n.learning, n.w, n.b, n.x)
+	return n.y.Value().Copy()
+}
+
+// Bwd propagates the diff through the tape of the last forward pass,
+// while it also updates the weights and biases accordingly.
+func (n *AutoCell) Bwd(diff xmath.Vector) xmath.Vector {
+	n.tape.Backward(n.y, diff)
+	n.weights.W.AddInPlace(xmath.DenseOf(n.w.GradMatrix()).ScaleInPlace(n.learning.WRate()))
+	n.weights.B.AddScaledInPlace(n.learning.BRate(), n.b.Grad())
+	return n.x.Grad().Copy()
+}
+
+// Meta returns the metadata for the neuron.
+func (n *AutoCell) Meta() Meta {
+	return n.meta
+}
+
+// Weights returns all the required constants needed to re-build the neuron state.
+func (n *AutoCell) Weights() *Weights {
+	return n.weights
+}
@@ -0,0 +1,76 @@
+package net
+
+import (
+	"testing"
+
+	"github.com/drakos74/go-ex-machina/xmachina/ml"
+	"github.com/drakos74/go-ex-machina/xmath"
+	"github.com/drakos74/go-ex-machina/xmath/autodiff"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAutoCell(t *testing.T) {
+
+	type test struct {
+		activation ml.Activation
+	}
+
+	tests := map[string]test{
+		"sigmoid": {activation: ml.Sigmoid},
+		"tanh":    {activation: ml.TanH},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			n, m := 4, 3
+			builder := NewBuilder().
+				WithModule(ml.Base().WithRate(ml.Learn(0.5, 0.5)).WithActivation(tt.activation)).
+				WithWeights(xmath.Range(-1, 1)(1), xmath.Const(0.1))
+			cell := builder.Factory(NewActivationCell)(n, m, Meta{})
+			auto := builder.Factory(NewAutoCell(Perceptron))(n, m, Meta{})
+			// start from the same weights
+			auto.Weights().W = cell.Weights().W.Copy()
+
+			x := xmath.Vec(n).With(0.1, 0.2, 0.3, 0.4)
+			diff := xmath.Vec(m).With(0.1, -0.2, 0.3)
+			for i := 0; i < 5; i++ {
+				assert.InDeltaSlice(t, cell.Fwd(x), auto.Fwd(x), 1e-12)
+				assert.InDeltaSlice(t, cell.Bwd(diff), auto.Bwd(diff), 1e-12)
+				for r := 0; r < m; r++ {
+					assert.InDeltaSlice(t, cell.Weights().W.Row(r), auto.Weights().W.Row(r), 1e-12)
+				}
+				assert.InDeltaSlice(t, cell.Weights().B, auto.Weights().B, 1e-12)
+			}
+		})
+	}
+
+}
+
+func TestRecord(t *testing.T) {
+
+	newCell := func() Neuron {
+		return NewBuilder().
+			WithModule(ml.Base().WithRate(ml.Learn(0, 0))).
+			WithWeights(xmath.Const(0.2), xmath.Const(0.1)).
+			Factory(NewActivationCell)(2, 2, Meta{})
+	}
+
+	x := xmath.Vec(2).With(1, 2)
+	seed := xmath.Vec(2).With(1, -1)
+
+	// the cell recorded on the tape, with a multiplication after it
+	tape := autodiff.NewTape()
+	input := tape.Var(x)
+	y := RecordBi(NewMulCell(), Record(newCell(), input), tape.Var(xmath.Vec(2).With(2, 3)))
+	tape.Backward(y, seed)
+
+	// the same computation, by hand
+	cell := newCell()
+	mul := NewMulCell()
+	out := mul.Fwd(cell.Fwd(x), xmath.Vec(2).With(2, 3))
+	da, _ := mul.Bwd(seed)
+
+	assert.Equal(t, out, y.Value())
+	assert.Equal(t, cell.Bwd(da), input.Grad())
+
+}
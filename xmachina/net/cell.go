@@ -10,6 +10,13 @@ type Op interface {
 	Bwd(dy xmath.Vector) xmath.Vector
 }
 
+// SparseOp represents an operation that can also be applied on sparse vectors,
+// touching only their non-zero elements.
+type SparseOp interface {
+	Op
+	FwdSparse(x xmath.SparseVector) xmath.Vector
+}
+
 // BiOp represents a generic operation on two vectors producing one vector as output.
 type BiOp interface {
 	Fwd(a, b xmath.Vector) xmath.Vector
@@ -32,6 +32,15 @@ func (l *Layer) Forward(v xmath.Vector) xmath.Vector {
 	return l.neuron.Fwd(v)
 }
 
+// ForwardSparse takes a sparse input e.g. one-hot encoded features,
+// and forwards it through the neuron touching only its non-zero elements, if the neuron supports it.
+func (l *Layer) ForwardSparse(v xmath.SparseVector) xmath.Vector {
+	if op, ok := l.neuron.(net.SparseOp); ok {
+		return op.FwdSparse(v)
+	}
+	return l.neuron.Fwd(v.Dense())
+}
+
 // Backward receives all the errors/diffs from the following layer
 // it returns the errors/diffs for the previous layer
 func (l *Layer) Backward(err xmath.Vector) xmath.Vector {
@@ -41,7 +50,7 @@ func (l *Layer) Backward(err xmath.Vector) xmath.Vector {
 // Weights returns the weights of the current layer for storing the network state.
 func (l *Layer) Weights() map[net.Meta]net.Weights {
 	return map[net.Meta]net.Weights{
-		l.neuron.Meta(): *l.neuron.Weights(),
+		l.neuron.Meta(): l.neuron.Weights().Copy(),
 	}
 }
 
@@ -144,7 +153,7 @@ func (xl *xLayer) Weights() map[net.Meta]net.Weights {
 	}
 	return map[net.Meta]net.Weights{
 		net.Meta{}: {
-			W: m,
+			W: xmath.DenseOf(m),
 			B: n,
 		},
 	}
@@ -50,6 +50,25 @@ func (n *Network) forward(input xmath.Vector) xmath.Vector {
 	return output
 }
 
+// forwardSparse passes the sparse input through the first layer, and the resulting dense vector through the rest.
+func (n *Network) forwardSparse(input xmath.SparseVector) xmath.Vector {
+	if len(n.layers) == 0 {
+		return input.Dense()
+	}
+	var output xmath.Vector
+	for i, l := range n.layers {
+		if i == 0 {
+			if sl, ok := l.(*Layer); ok {
+				output = sl.ForwardSparse(input)
+				continue
+			}
+			output = input.Dense()
+		}
+		output = l.Forward(output)
+	}
+	return output
+}
+
 func (n *Network) backward(err xmath.Vector) {
 	// we go through the layers in reverse order
 	for i := len(n.layers) - 1; i >= 0; i-- {
@@ -58,14 +77,38 @@ func (n *Network) backward(err xmath.Vector) {
 
 }
 
-func (n *Network) Train(input xmath.Vector, expected xmath.Vector) (err xmath.Vector, weights map[net.Meta]net.Weights) {
+func (n *Network) Train(input xmath.Vector, expected xmath.Vector) (loss xmath.Vector, weights map[net.Meta]net.Weights, err error) {
+	if err := n.CheckInput(input); err != nil {
+		return nil, nil, err
+	}
+	return n.train(n.forward(input), expected)
+}
 
-	out := n.forward(input)
+// TrainSparse trains the network with a sparse input e.g. one-hot encoded features.
+// The first layer only touches the weights of the non-zero input elements, in both the forward and backward pass.
+func (n *Network) TrainSparse(input xmath.SparseVector, expected xmath.Vector) (loss xmath.Vector, weights map[net.Meta]net.Weights, err error) {
+	if err := xmath.ExpectSparse("input", input, n.InputSize); err != nil {
+		return nil, nil, err
+	}
+	return n.train(n.forwardSparse(input), expected)
+}
+
+// train applies the backward pass for the output of the forward pass.
+func (n *Network) train(out xmath.Vector, expected xmath.Vector) (loss xmath.Vector, weights map[net.Meta]net.Weights, err error) {
+
+	if err := n.CheckOutput(expected); err != nil {
+		return nil, nil, err
+	}
+
+	// dont propagate invalid numbers back to the weights
+	if err := xmath.ExpectValid("prediction", out); err != nil {
+		return nil, nil, err
+	}
 
 	diff := expected.Diff(out)
 
 	// quadratic error
-	err = n.loss(expected, out)
+	loss = n.loss(expected, out)
 	// cross entropy
 	//err = expected.Dop(func(x, y float64) float64 {
 	//	return -1 * x * math.Log(y)
@@ -85,12 +128,23 @@ func (n *Network) Train(input xmath.Vector, expected xmath.Vector) (err xmath.Ve
 		}
 	}
 
-	return err, weights
+	return loss, weights, nil
 
 }
 
-func (n *Network) Predict(input xmath.Vector) xmath.Vector {
-	return n.forward(input)
+func (n *Network) Predict(input xmath.Vector) (xmath.Vector, error) {
+	if err := n.CheckInput(input); err != nil {
+		return nil, err
+	}
+	return n.forward(input), nil
+}
+
+// PredictSparse predicts the output for a sparse input e.g. one-hot encoded features.
+func (n *Network) PredictSparse(input xmath.SparseVector) (xmath.Vector, error) {
+	if err := xmath.ExpectSparse("input", input, n.InputSize); err != nil {
+		return nil, err
+	}
+	return n.forwardSparse(input), nil
 }
 
 func (n *Network) GetInfo() net.Info {
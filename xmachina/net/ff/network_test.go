@@ -45,14 +45,14 @@ func TestNetwork_Train_NoActivation(t *testing.T) {
 		// neuron : [1,0] -> [0.17,0.17]
 		map[net.Meta]net.Weights{
 			net.Meta{}: {
-				W: xmath.Mat(2).With(
+				W: xmath.DenseOf(xmath.Mat(2).With(
 					xmath.Vec(2).With(0.11, 0.21),
 					xmath.Vec(2).With(0.12, 0.08),
-				),
+				)),
 				B: xmath.Vec(2).With(0.11, 0.21),
 			},
 			net.Meta{Layer: 1}: {
-				W: xmath.Mat(1).With(xmath.Vec(2).With(0.15, 0.16)),
+				W: xmath.DenseOf(xmath.Mat(1).With(xmath.Vec(2).With(0.15, 0.16))),
 				B: xmath.Vec(1).With(0.16),
 			},
 		},
@@ -92,14 +92,14 @@ func TestXNetwork_Train_NoActivation(t *testing.T) {
 		// neuron : [1,0] -> [0.17,0.17]
 		map[net.Meta]net.Weights{
 			net.Meta{}: {
-				W: xmath.Mat(2).With(
+				W: xmath.DenseOf(xmath.Mat(2).With(
 					xmath.Vec(2).With(0.12, 0.23),
 					xmath.Vec(2).With(0.13, 0.10),
-				),
+				)),
 				B: xmath.Vec(2).With(0.17, 0.17),
 			},
 			net.Meta{Layer: 1}: {
-				W: xmath.Mat(1).With(xmath.Vec(2).With(0.15, 0.15)),
+				W: xmath.DenseOf(xmath.Mat(1).With(xmath.Vec(2).With(0.15, 0.15))),
 				B: xmath.Vec(1).With(0.02),
 			},
 		},
@@ -109,17 +109,127 @@ func TestXNetwork_Train_NoActivation(t *testing.T) {
 
 func assertTrain(t *testing.T, n net.NN, inp, out xmath.Vector, expErr []string, expWeights map[net.Meta]net.Weights) {
 
-	err, weights := n.Train(inp, out)
+	loss, weights, err := n.Train(inp, out)
+	assert.NoError(t, err)
 
-	for i := range err {
-		assert.Equal(t, expErr[i], strconv.FormatFloat(err[i], 'f', 4, 64))
+	for i := range loss {
+		assert.Equal(t, expErr[i], strconv.FormatFloat(loss[i], 'f', 4, 64))
 	}
 
 	println(fmt.Sprintf("weights = %v", weights))
 
 	println(fmt.Sprintf("expWeights = %v", expWeights))
 	for i, ww := range weights {
-		assert.Equal(t, expWeights[i].W, ww.W.Op(xmath.Round(2)), fmt.Sprintf("%+v", i))
+		assert.Equal(t, expWeights[i].W.Matrix(), ww.W.Op(xmath.Round(2)).Matrix(), fmt.Sprintf("%+v", i))
 		assert.Equal(t, expWeights[i].B, ww.B.Op(xmath.Round(2)), fmt.Sprintf("%+v", i))
 	}
 }
+
+func TestNetwork_TrainSparse(t *testing.T) {
+
+	newNetwork := func() *Network {
+		builder := net.NewBuilder().
+			WithModule(ml.Base().
+				WithRate(ml.Learn(0.5, 0.5)).
+				WithActivation(ml.Sigmoid))
+		return New(5, 1).
+			Add(3, builder.WithWeights(xmath.Const(0.1), xmath.Const(0.2)).Factory(net.NewActivationCell)).
+			Add(1, builder.WithWeights(xmath.Const(0.3), xmath.Const(0.4)).Factory(net.NewActivationCell))
+	}
+
+	dense := newNetwork()
+	dense.Trace()
+	sparse := newNetwork()
+	sparse.Trace()
+
+	input := xmath.Vec(5).With(0, 1, 0, 0, 0.5)
+	expected := xmath.Vec(1).With(1)
+
+	for i := 0; i < 10; i++ {
+		denseLoss, denseWeights, err := dense.Train(input, expected)
+		assert.NoError(t, err)
+		sparseLoss, sparseWeights, err := sparse.TrainSparse(xmath.SparseOf(input), expected)
+		assert.NoError(t, err)
+		assert.InDeltaSlice(t, denseLoss, sparseLoss, 1e-12)
+		assert.Equal(t, 2, len(denseWeights))
+		assert.Equal(t, len(denseWeights), len(sparseWeights))
+		for meta, w := range denseWeights {
+			sw, ok := sparseWeights[meta]
+			assert.True(t, ok, fmt.Sprintf("%+v", meta))
+			assert.InDeltaSlice(t, w.B, sw.B, 1e-12)
+			rows := w.W.Matrix()
+			sparseRows := sw.W.Matrix()
+			assert.Equal(t, len(rows), len(sparseRows))
+			for r := range rows {
+				assert.InDeltaSlice(t, rows[r], sparseRows[r], 1e-12)
+			}
+		}
+	}
+
+	denseOut, err := dense.Predict(input)
+	assert.NoError(t, err)
+	sparseOut, err := sparse.PredictSparse(xmath.SparseOf(input))
+	assert.NoError(t, err)
+	assert.InDeltaSlice(t, denseOut, sparseOut, 1e-12)
+
+	_, err = sparse.PredictSparse(xmath.OneHot(4, 0))
+	assert.Error(t, err)
+
+}
+
+func TestNetwork_WeightsSnapshot(t *testing.T) {
+
+	type test struct {
+		network func() net.NN
+	}
+
+	tests := map[string]test{
+		"network": {
+			network: func() net.NN {
+				return New(2, 1).
+					Add(1, net.NewBuilder().
+						WithModule(ml.Base().
+							WithRate(ml.Learn(0.5, 0.5)).
+							WithActivation(ml.Sigmoid)).
+						WithWeights(xmath.Const(0.1), xmath.Const(0.2)).
+						Factory(net.NewActivationCell))
+			},
+		},
+		"x-network": {
+			network: func() net.NN {
+				return XNew(2, 1).
+					Add(1, Perceptron(ml.Base().
+						WithRate(ml.Learn(0.5, 0.5)).
+						WithActivation(ml.Sigmoid), xmath.Const(0.1)))
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			n := tt.network()
+			n.Trace()
+
+			input := xmath.Vec(2).With(1, 0.5)
+			expected := xmath.Vec(1).With(1)
+
+			_, weights, err := n.Train(input, expected)
+			assert.NoError(t, err)
+			snapshot := make(map[net.Meta]net.Weights, len(weights))
+			for meta, w := range weights {
+				snapshot[meta] = w.Copy()
+			}
+
+			_, next, err := n.Train(input, expected)
+			assert.NoError(t, err)
+
+			// the weights returned earlier do not change with the next training
+			for meta, w := range weights {
+				assert.Equal(t, snapshot[meta].W.Matrix(), w.W.Matrix())
+				assert.Equal(t, snapshot[meta].B, w.B)
+				assert.NotEqual(t, w.B, next[meta].B)
+			}
+		})
+	}
+
+}
@@ -4,10 +4,13 @@ import (
 	"github.com/drakos74/go-ex-machina/xmath"
 )
 
+// NN is the common interface for all networks.
+// Invalid inputs to Train and Predict e.g. vectors of the wrong size or with NaN elements,
+// are reported as xmath.ShapeError or xmath.NaNError, without updating the network weights.
 type NN interface {
 	NetworkConfig
-	Train(input xmath.Vector, output xmath.Vector) (err xmath.Vector, weights map[Meta]Weights)
-	Predict(input xmath.Vector) xmath.Vector
+	Train(input xmath.Vector, output xmath.Vector) (loss xmath.Vector, weights map[Meta]Weights, err error)
+	Predict(input xmath.Vector) (xmath.Vector, error)
 	GetInfo() Info
 }
 
@@ -19,6 +22,22 @@ type Info struct {
 	Iterations int
 }
 
+// CheckInput returns an error if the input does not have the network input size or contains invalid numbers.
+func (info Info) CheckInput(input xmath.Vector) error {
+	if err := xmath.ExpectSize("input", input, info.InputSize); err != nil {
+		return err
+	}
+	return xmath.ExpectValid("input", input)
+}
+
+// CheckOutput returns an error if the output does not have the network output size or contains invalid numbers.
+func (info Info) CheckOutput(output xmath.Vector) error {
+	if err := xmath.ExpectSize("output", output, info.OutputSize); err != nil {
+		return err
+	}
+	return xmath.ExpectValid("output", output)
+}
+
 type NetworkConfig interface {
 	Debug()
 	HasDebugEnabled() bool
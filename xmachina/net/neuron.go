@@ -11,18 +11,30 @@ import (
 
 // Weights encapsulates all needed parameters to apply to the neuron attributes
 type Weights struct {
-	W xmath.Matrix
+	W xmath.Dense
 	B xmath.Vector
 }
 
 // NewWeights creates a new set of weights and bias.
 func NewWeights(n, m int, weightsGenerator, biasGenerator xmath.VectorGenerator) *Weights {
 	return &Weights{
-		W: xmath.Mat(m).Generate(n, weightsGenerator),
+		W: xmath.NewDense(m, n).Generate(weightsGenerator),
 		B: xmath.Vec(m).Generate(biasGenerator),
 	}
 }
 
+// Copy returns a deep copy of the weights,
+// so that it does not change with the in-place updates of the neuron e.g. for storing the network state.
+func (w Weights) Copy() Weights {
+	return Weights{
+		W: w.W.Copy(),
+		B: w.B.Copy(),
+	}
+}
+
+// buffers is a pool of short-lived vectors used within the neuron computations.
+var buffers xmath.Pool
+
 // Neuron is a minimal computation unit with an activation function.
 // It is effectively a collection of perceptrons so not the smallest unit after all,
 // but it allows for extension in more general cases than feed forward neural nets.
@@ -53,6 +65,7 @@ type ActivationCell struct {
 	weights       *Weights
 	meta          Meta
 	input, output xmath.Vector
+	sparse        *xmath.SparseVector
 }
 
 // NewActivationCell creates a new ml neuron.
@@ -71,12 +84,26 @@ func (n *ActivationCell) Fwd(v xmath.Vector) xmath.Vector {
 	xmath.MustHaveSameSize(v, n.input)
 	// keep a copy of the input in memory
 	n.input = v
-	// combine with the weights
-	w := n.weights.W.Prod(v)
-	// add bias
-	z := w.Add(n.weights.B)
-	// apply activation
-	n.output = z.Op(n.learning.F)
+	n.sparse = nil
+	// combine with the weights, add bias and apply activation
+	// note : the output is a new vector every time, as it is handed over to the next layer
+	n.output = n.weights.W.ProdInto(xmath.Vec(len(n.weights.B)), v).
+		AddInPlace(n.weights.B).
+		OpInPlace(n.learning.F)
+	return n.output
+}
+
+// FwdSparse applies the forward pass logic for the neuron on a sparse input,
+// touching only the weights of its non-zero elements.
+// The following backward pass will update only these weights as well.
+func (n *ActivationCell) FwdSparse(v xmath.SparseVector) xmath.Vector {
+	if v.Size() != len(n.input) {
+		panic(fmt.Sprintf("sparse input must have size '%v' vs '%v'", v.Size(), len(n.input)))
+	}
+	n.sparse = &v
+	n.output = n.weights.W.ProdSparse(v).
+		AddInPlace(n.weights.B).
+		OpInPlace(n.learning.F)
 	return n.output
 }
 
@@ -88,31 +115,54 @@ func (n *ActivationCell) Bwd(diff xmath.Vector) xmath.Vector {
 		Floats64("diff", diff).
 		Msg("train-diff")
 	// find the derivative of the output
-	deriv := n.output.Op(n.learning.D)
+	grad := buffers.Get(len(n.output))
+	defer buffers.Put(grad)
+	copy(grad, n.output)
+	grad.OpInPlace(n.learning.D)
 	log.Trace().
 		Str("meta", fmt.Sprintf("%+v", n.meta)).
-		Floats64("deriv", deriv).
+		Floats64("deriv", grad).
 		Msg("de-activation")
 	// find the gradient compared to the diff
-	grad := deriv.X(diff)
+	grad.XInPlace(diff)
 	log.Trace().
 		Str("meta", fmt.Sprintf("%+v", n.meta)).
 		Floats64("grad", grad).
 		Msg("gradient-descent")
 	// compute loss for previous layer
-	loss := n.weights.W.T().Prod(grad)
+	loss := backward(n.weights.W, grad, n.sparse)
 	log.Trace().
 		Str("meta", fmt.Sprintf("%+v", n.meta)).
 		Floats64("loss", loss).
 		Msg("loss")
 	// update weights and bias
-	dW := grad.Prod(n.input)
-	n.weights.W = n.weights.W.Add(dW.Mult(n.learning.WRate()))
-	n.weights.B = n.weights.B.Add(grad.Mult(n.learning.BRate()))
+	if n.sparse != nil {
+		n.weights.W.AddOuterSparseInPlace(n.learning.WRate(), grad, *n.sparse)
+	} else {
+		n.weights.W.AddOuterInPlace(n.learning.WRate(), grad, n.input)
+	}
+	n.weights.B.AddScaledInPlace(n.learning.BRate(), grad)
 	// return the loss to the previous layer
 	return loss
 }
 
+// backward computes the loss for the previous layer out of the gradient.
+// For sparse inputs, the loss is computed only for the non-zero input elements,
+// as these come directly from the data, rather than a previous layer that would need the full loss.
+func backward(w xmath.Dense, grad xmath.Vector, sparse *xmath.SparseVector) xmath.Vector {
+	if sparse == nil {
+		return w.T().Prod(grad)
+	}
+	rows, cols := w.Dims()
+	loss := xmath.Vec(cols)
+	for _, j := range sparse.Indices() {
+		for i := 0; i < rows; i++ {
+			loss[j] += w.At(i, j) * grad[i]
+		}
+	}
+	return loss
+}
+
 // Meta returns the metadata for the neuron.
 func (n ActivationCell) Meta() Meta {
 	return n.meta
@@ -209,6 +259,7 @@ type WeightCell struct {
 	weights       *Weights
 	meta          Meta
 	input, output xmath.Vector
+	sparse        *xmath.SparseVector
 }
 
 // NewWeightCell creates a new ml neuron.
@@ -232,9 +283,26 @@ func (w *WeightCell) Fwd(v xmath.Vector) xmath.Vector {
 	xmath.MustHaveSameSize(v, w.input)
 	// keep a copy of the input in memory
 	w.input = v
+	w.sparse = nil
 	// combine with the weights
 	m := w.weights.W.Prod(v)
-	w.output = m.Add(w.weights.B)
+	w.output = xmath.Vec(len(m))
+	copy(w.output, m)
+	w.output.AddInPlace(w.weights.B)
+	return m
+}
+
+// FwdSparse applies the forward pass logic for the neuron on a sparse input,
+// touching only the weights of its non-zero elements.
+func (w *WeightCell) FwdSparse(v xmath.SparseVector) xmath.Vector {
+	if v.Size() != len(w.input) {
+		panic(fmt.Sprintf("sparse input must have size '%v' vs '%v'", v.Size(), len(w.input)))
+	}
+	w.sparse = &v
+	m := w.weights.W.ProdSparse(v)
+	w.output = xmath.Vec(len(m))
+	copy(w.output, m)
+	w.output.AddInPlace(w.weights.B)
 	return m
 }
 
@@ -246,15 +314,18 @@ func (w *WeightCell) Bwd(diff xmath.Vector) xmath.Vector {
 		Floats64("diff", diff).
 		Msg("train-diff")
 	// compute loss for previous layer
-	dw := w.weights.W.T().Prod(diff)
+	dw := backward(w.weights.W, diff, w.sparse)
 	log.Trace().
 		Str("meta", fmt.Sprintf("%+v", w.meta)).
 		Floats64("loss", dw).
 		Msg("loss")
 	// update weights and bias
-	dW := diff.Prod(w.input)
-	w.weights.W = w.weights.W.Add(dW.Mult(w.learning.WRate()))
-	w.weights.B = w.weights.B.Add(diff.Mult(w.learning.BRate()))
+	if w.sparse != nil {
+		w.weights.W.AddOuterSparseInPlace(w.learning.WRate(), diff, *w.sparse)
+	} else {
+		w.weights.W.AddOuterInPlace(w.learning.WRate(), diff, w.input)
+	}
+	w.weights.B.AddScaledInPlace(w.learning.BRate(), diff)
 
 	// return the loss to the previous layer
 	return dw
@@ -275,9 +346,10 @@ type NeuronFactory func(n, m int, meta Meta) Neuron
 
 // NeuronBuilder is a helper struct to create a neuron factory
 type NeuronBuilder struct {
-	module           *ml.Module
-	weightsGenerator xmath.VectorGenerator
-	biasGenerator    xmath.VectorGenerator
+	module             *ml.Module
+	weightsGenerator   xmath.VectorGenerator
+	weightsInitializer xmath.Initializer
+	biasGenerator      xmath.VectorGenerator
 }
 
 // NewBuilder creates a default neuron factory builder with everything set,
@@ -293,10 +365,28 @@ func NewBuilder() *NeuronBuilder {
 // WithWeights specifies the starting weights for the neuron.
 func (nb *NeuronBuilder) WithWeights(weightsGenerator xmath.VectorGenerator, biasGenerator xmath.VectorGenerator) *NeuronBuilder {
 	nb.weightsGenerator = weightsGenerator
+	nb.weightsInitializer = nil
 	nb.biasGenerator = biasGenerator
 	return nb
 }
 
+// WithInitializer specifies the starting weights for the neuron out of its input and output size
+// e.g. xmath.GlorotUniform, so that the builder can be used for layers of any size.
+func (nb *NeuronBuilder) WithInitializer(weightsInitializer xmath.Initializer, biasGenerator xmath.VectorGenerator) *NeuronBuilder {
+	nb.weightsInitializer = weightsInitializer
+	nb.biasGenerator = biasGenerator
+	return nb
+}
+
+// weights creates the starting weights for a neuron with n inputs and m outputs.
+func (nb *NeuronBuilder) weights(n, m int) *Weights {
+	generator := nb.weightsGenerator
+	if nb.weightsInitializer != nil {
+		generator = nb.weightsInitializer(n, m)
+	}
+	return NewWeights(n, m, generator, nb.biasGenerator)
+}
+
 // WithModule specifies the ml module to use.
 func (nb *NeuronBuilder) WithModule(module *ml.Module) *NeuronBuilder {
 	nb.module = module
@@ -313,7 +403,7 @@ func (nb *NeuronBuilder) Factory(constr NeuronConstructor) NeuronFactory {
 		return constr(
 			n, m,
 			*nb.module,
-			NewWeights(n, m, nb.weightsGenerator, nb.biasGenerator),
+			nb.weights(n, m),
 			meta,
 		)
 	}
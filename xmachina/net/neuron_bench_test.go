@@ -0,0 +1,47 @@
+package net
+
+import (
+	"math"
+	"testing"
+
+	"github.com/drakos74/go-ex-machina/xmachina/ml"
+	"github.com/drakos74/go-ex-machina/xmath"
+)
+
+// benchmarkCell runs a forward and backward pass on a cell of the size of the mnist hidden layer.
+func benchmarkCell(b *testing.B, constr NeuronConstructor) {
+	n, m := 784, 200
+	cell := constr(n, m, *ml.Base().WithRate(ml.Learn(0.1, 0)).WithActivation(ml.TanH),
+		NewWeights(n, m, xmath.Rand(-1, 1, math.Sqrt), xmath.Rand(-1, 1, math.Sqrt)), Meta{})
+	x := xmath.Vec(n).Generate(xmath.Rand(0, 1, xmath.Unit))
+	diff := xmath.Vec(m).Generate(xmath.Rand(-0.1, 0.1, xmath.Unit))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cell.Fwd(x)
+		cell.Bwd(diff)
+	}
+}
+
+func BenchmarkActivationCell(b *testing.B) {
+	benchmarkCell(b, NewActivationCell)
+}
+
+func BenchmarkWeightCell(b *testing.B) {
+	benchmarkCell(b, NewWeightCell)
+}
+
+// BenchmarkActivationCell_Sparse runs the same cell with a one-hot input.
+func BenchmarkActivationCell_Sparse(b *testing.B) {
+	n, m := 784, 200
+	cell := NewActivationCell(n, m, *ml.Base().WithRate(ml.Learn(0.1, 0)).WithActivation(ml.TanH),
+		NewWeights(n, m, xmath.Rand(-1, 1, math.Sqrt), xmath.Rand(-1, 1, math.Sqrt)), Meta{}).(SparseOp)
+	x := xmath.OneHot(n, 42)
+	diff := xmath.Vec(m).Generate(xmath.Rand(-0.1, 0.1, xmath.Unit))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cell.FwdSparse(x)
+		cell.Bwd(diff)
+	}
+}
@@ -3,6 +3,7 @@ package net
 import (
 	"fmt"
 	"math"
+	"math/rand"
 	"testing"
 
 	"github.com/drakos74/go-ex-machina/xmachina/ml"
@@ -30,7 +31,7 @@ func TestActivationCell_FwdAndBwd(t *testing.T) {
 	weights := neuron.Weights()
 	vec := xmath.Vec(xDim).With(0.5, 0.5)
 	// weights should be a 2x3 matrix
-	assert.Equal(t, xmath.Mat(yDim).With(vec, vec, vec), weights.W)
+	assert.Equal(t, xmath.Mat(yDim).With(vec, vec, vec), weights.W.Matrix())
 	// bias should be same as the output dimension
 	assert.Equal(t, xmath.Vec(yDim).With(0.5, 0.5, 0.5), weights.B)
 
@@ -54,12 +55,12 @@ func TestActivationCell_FwdAndBwd(t *testing.T) {
 	ty = neuron.Fwd(x)
 	weights = neuron.Weights()
 	// left and right most elements should be equal, because we enforced symmetry on the expected output
-	assert.Equal(t, weights.W[0], weights.W[2])
+	assert.Equal(t, weights.W.Row(0), weights.W.Row(2))
 	assert.Equal(t, weights.B[0], weights.B[2])
 	assert.Equal(t, ty[0], ty[2])
 
 	// middle element should be larger
-	assert.True(t, weights.W[1].Sum() > weights.W[0].Sum())
+	assert.True(t, weights.W.Row(1).Sum() > weights.W.Row(0).Sum())
 	assert.True(t, weights.B[1] > weights.B[0])
 	assert.True(t, ty[1] > ty[0])
 
@@ -187,7 +188,7 @@ func TestWeightCell_FwdAndBwd(t *testing.T) {
 	weights := neuronWeights(neuron)
 	vec := xmath.Vec(xDim).With(0.5, 0.5)
 	// weights should be a 2x3 matrix
-	assert.Equal(t, xmath.Mat(yDim).With(vec, vec, vec), weights.W)
+	assert.Equal(t, xmath.Mat(yDim).With(vec, vec, vec), weights.W.Matrix())
 	// bias should be same as the output dimension
 	assert.Equal(t, xmath.Vec(yDim).With(0.5, 0.5, 0.5), weights.B)
 
@@ -213,11 +214,11 @@ func TestWeightCell_FwdAndBwd(t *testing.T) {
 	println(fmt.Sprintf("ty = %v", ty))
 	weights = neuronWeights(neuron)
 	// left and right most elements should be equal, because we enforced symmetry on the expected output
-	assert.Equal(t, weights.W[0], weights.W[2])
+	assert.Equal(t, weights.W.Row(0), weights.W.Row(2))
 	assert.Equal(t, ty[0], ty[2])
 
 	// middle element should be larger
-	assert.True(t, weights.W[1].Sum() > weights.W[0].Sum())
+	assert.True(t, weights.W.Row(1).Sum() > weights.W.Row(0).Sum())
 	assert.True(t, ty[1] > ty[0])
 
 	// train for a econd epoch
@@ -437,3 +438,108 @@ func runTest(t *testing.T, neuron Neuron, inp, outp xmath.Vector, threshold int)
 	println(fmt.Sprintf("loss = %v , diff = %v", diff.Op(math.Abs).Sum(), diff))
 	assert.True(t, diff.Op(math.Abs).Sum() < 0.01)
 }
+
+func TestCell_FwdSparse(t *testing.T) {
+
+	type test struct {
+		constr NeuronConstructor
+	}
+
+	tests := map[string]test{
+		"activation-cell": {constr: NewActivationCell},
+		"weight-cell":     {constr: NewWeightCell},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			n, m := 6, 3
+			newCell := func() Neuron {
+				return tt.constr(n, m, *ml.Base().WithRate(ml.Learn(0.5, 0.5)).WithActivation(ml.Sigmoid),
+					NewWeights(n, m, xmath.Range(0, 1)(1), xmath.Const(0.1)), Meta{})
+			}
+			denseCell := newCell()
+			sparseCell := newCell()
+			// start from the same weights
+			sparseCell.Weights().W = denseCell.Weights().W.Copy()
+			initial := denseCell.Weights().W.Copy()
+
+			x := xmath.Vec(n).With(0, 0.5, 0, 0, 1, 0)
+			diff := xmath.Vec(m).With(0.1, -0.2, 0.3)
+
+			assert.Equal(t, denseCell.Fwd(x), sparseCell.(SparseOp).FwdSparse(xmath.SparseOf(x)))
+
+			denseLoss := denseCell.Bwd(diff)
+			sparseLoss := sparseCell.Bwd(diff)
+			// the loss is only computed for the non-zero inputs
+			for _, j := range []int{1, 4} {
+				assert.InDelta(t, denseLoss[j], sparseLoss[j], 1e-12)
+			}
+			assert.Equal(t, 0.0, sparseLoss[0])
+
+			// the weights end up the same, as the zero inputs dont contribute to the update
+			for i := 0; i < m; i++ {
+				assert.InDeltaSlice(t, denseCell.Weights().W.Row(i), sparseCell.Weights().W.Row(i), 1e-12)
+				// and only the non-zero columns were touched
+				for _, j := range []int{0, 2, 3, 5} {
+					assert.Equal(t, initial.At(i, j), sparseCell.Weights().W.At(i, j))
+				}
+			}
+			assert.Equal(t, denseCell.Weights().B, sparseCell.Weights().B)
+		})
+	}
+
+}
+
+func TestNeuronBuilder_SeededWeights(t *testing.T) {
+
+	newNeuron := func(seed int64) Neuron {
+		rng := rand.New(rand.NewSource(seed))
+		return NewBuilder().
+			WithInitializer(xmath.GlorotUniform(rng), xmath.Const(0)).
+			Factory(NewActivationCell)(4, 3, Meta{})
+	}
+
+	assert.Equal(t, newNeuron(1).Weights(), newNeuron(1).Weights())
+	assert.NotEqual(t, newNeuron(1).Weights(), newNeuron(2).Weights())
+
+}
+
+func TestNeuronBuilder_WithInitializer(t *testing.T) {
+
+	// the same builder is used for layers of different sizes
+	factory := NewBuilder().
+		WithInitializer(xmath.Orthogonal(1, rand.New(rand.NewSource(1))), xmath.Const(0)).
+		Factory(NewActivationCell)
+
+	for _, size := range [][2]int{{4, 3}, {3, 1}, {2, 5}} {
+		n, m := size[0], size[1]
+		weights := factory(n, m, Meta{}).Weights()
+		rows, cols := weights.W.Dims()
+		assert.Equal(t, m, rows)
+		assert.Equal(t, n, cols)
+		// the rows or columns, whichever are fewer, are orthonormal
+		w := weights.W.Matrix()
+		p := w.Dot(w)
+		k := m
+		if m > n {
+			p = w.T().Dot(w.T())
+			k = n
+		}
+		for i := 0; i < k; i++ {
+			for j := 0; j < k; j++ {
+				expected := 0.0
+				if i == j {
+					expected = 1
+				}
+				assert.InDelta(t, expected, p[i][j], 1e-9)
+			}
+		}
+	}
+
+	// the fixed weights replace the initializer
+	builder := NewBuilder().
+		WithInitializer(xmath.GlorotUniform(rand.New(rand.NewSource(1))), xmath.Const(0)).
+		WithWeights(xmath.Const(0.3), xmath.Const(0))
+	assert.Equal(t, xmath.Vec(2).With(0.3, 0.3), builder.Factory(NewActivationCell)(2, 1, Meta{}).Weights().W.Row(0))
+
+}
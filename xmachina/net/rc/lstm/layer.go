@@ -27,7 +27,7 @@ func (l *Layer) Weights() map[net.Meta]net.Weights {
 	weights := make(map[net.Meta]net.Weights)
 	for _, neuron := range l.neurons {
 		for _, cell := range neuron.cells {
-			weights[cell.Meta()] = *cell.Weights()
+			weights[cell.Meta()] = cell.Weights().Copy()
 		}
 	}
 	return weights
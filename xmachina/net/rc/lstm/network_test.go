@@ -9,6 +9,7 @@ import (
 	"github.com/drakos74/go-ex-machina/xmachina/net"
 	"github.com/drakos74/go-ex-machina/xmachina/net/rc"
 	"github.com/drakos74/go-ex-machina/xmath"
+	"github.com/stretchr/testify/assert"
 )
 
 func Test_LSTMNetworkSineFunc(t *testing.T) {
@@ -22,13 +23,15 @@ func Test_LSTMNetworkSineFunc(t *testing.T) {
 	f := 0.025
 
 	var err xmath.Vector
+	var e error
 	for i := 0; i < 1000; i++ {
 
 		x := f * float64(i)
 
 		s := math.Sin(x)
 		output := xmath.Vec(1)
-		err, _ = network.Train(xmath.Vec(1).With(s), output)
+		err, _, e = network.Train(xmath.Vec(1).With(s), output)
+		assert.NoError(t, e)
 		println(fmt.Sprintf("err = %v", err.Op(math.Abs).Sum()))
 	}
 
@@ -114,7 +114,8 @@ func (n *neuron) forward(x, prev_h, prev_s xmath.Vector) (y, next_h, next_s xmat
 	s := n.biOps[forgetCell].Fwd(f, prev_s)
 	i := n.biOps[inputCell].Fwd(il, ir)
 
-	next_s = s.Add(i)
+	// s is a new vector created by the forget cell, so we can accumulate on it directly
+	next_s = s.AddInPlace(i)
 	c := n.cells[stateNeuron].Fwd(next_s)
 
 	next_h = n.biOps[stateCell].Fwd(c, o)
@@ -148,7 +149,8 @@ func (n *neuron) backward(dyh, dh, ds xmath.Vector) (x, h, s xmath.Vector) {
 	dvi2 := n.cells[inputRightNeuron].Bwd(di2)
 	dvf := n.cells[forgetNeuron].Bwd(df)
 
-	dv := dvo.Add(dvi1).Add(dvi2).Add(dvf)
+	// dvo is a new vector created by the output neuron, so we can accumulate on it directly
+	dv := dvo.AddInPlace(dvi1).AddInPlace(dvi2).AddInPlace(dvf)
 
 	// split memory and input delta vectors
 	x, h = n.biOps[inputStackCell].Bwd(dv)
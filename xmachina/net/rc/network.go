@@ -10,6 +10,7 @@ import (
 )
 
 type Network struct {
+	net.Info
 	net.Config
 	Layer
 	*buffer.Stats
@@ -48,12 +49,47 @@ func (net *Network) OutputTransform(transform func(matrix xmath.Matrix) xmath.Ma
 	return net
 }
 
-func (net *Network) Train(data xmath.Vector, outputData xmath.Vector) (err xmath.Vector, weights map[net.Meta]net.Weights) {
+// check verifies the input and the output buffer, fixing the input size of the network with the first input.
+// The network predicts the next element of the sequence, so its output has the input size,
+// and the output buffer may keep only the first elements of it.
+// Nothing is updated if the check fails.
+func (net *Network) check(data xmath.Vector, outputData xmath.Vector) error {
+	size := net.InputSize
+	if !net.Init {
+		size = len(data)
+	}
+	if err := xmath.ExpectSize("input", data, size); err != nil {
+		return err
+	}
+	if err := xmath.ExpectValid("input", data); err != nil {
+		return err
+	}
+	if len(outputData) > size {
+		return &xmath.ShapeError{
+			Location: "output",
+			Expected: []int{size},
+			Actual:   []int{len(outputData)},
+		}
+	}
+	if !net.Init {
+		net.InputSize = size
+		net.OutputSize = size
+		net.Init = true
+	}
+	return nil
+}
+
+// Train pushes the data to the training batch, and trains the network once the batch is full.
+// The prediction for the next element of the sequence is written to the outputData.
+func (net *Network) Train(data xmath.Vector, outputData xmath.Vector) (loss xmath.Vector, weights map[net.Meta]net.Weights, err error) {
+	if err := net.check(data, outputData); err != nil {
+		return nil, nil, err
+	}
 	// add our trainInput & trainOutput to the batch
 	batch, batchIsReady := net.trainOutput.Push(data)
 	// be ready for predictions ... from the start
 	net.predictInput.Push(data)
-	loss := xmath.Vec(len(data))
+	loss = xmath.Vec(len(data))
 	if batchIsReady {
 		// we can actually train now ...
 		inp := net.inputTransform(batch)
@@ -61,11 +97,8 @@ func (net *Network) Train(data xmath.Vector, outputData xmath.Vector) (err xmath
 		// forward pass
 		out := net.Forward(inp)
 
-		// pass the data to the output vector
-		for i := 0; i < len(outputData); i++ {
-			// keep the last row as output data
-			outputData[i] = out[len(out)-1][i]
-		}
+		// pass the data to the output vector, keeping the last row as output data
+		copy(outputData, out[len(out)-1])
 
 		// add the cross entropy loss for each of the vectors
 		loss = net.loss(exp, out).Op(math.Abs)
@@ -85,24 +118,35 @@ func (net *Network) Train(data xmath.Vector, outputData xmath.Vector) (err xmath
 		//}
 	}
 
+	net.Iterations++
+
 	if net.HasTraceEnabled() {
 		weights = gatherWeights(net.Layer)
 	}
 
-	return loss, weights
+	return loss, weights, nil
 
 }
 
-func (net *Network) Predict(input xmath.Vector) xmath.Vector {
+// Predict pushes the input to the prediction batch, and returns the prediction for the next element of the sequence.
+func (net *Network) Predict(input xmath.Vector) (xmath.Vector, error) {
+	if err := net.check(input, nil); err != nil {
+		return nil, err
+	}
 
 	batch, batchIsReady := net.predictInput.Push(input)
 
 	if batchIsReady {
 		out := net.Forward(batch)
-		return out[len(out)-1]
+		return out[len(out)-1], nil
 	}
 
-	return xmath.Vec(len(input))
+	return xmath.Vec(len(input)), nil
+}
+
+// GetInfo returns the network metadata.
+func (net *Network) GetInfo() net.Info {
+	return net.Info
 }
 
 // TODO: make it the corresponding one compared to the above ..
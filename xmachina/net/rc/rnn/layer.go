@@ -25,10 +25,10 @@ func (r *Layer) Size() (n, x, h int) {
 func (r *Layer) Weights() map[net.Meta]net.Weights {
 	weights := make(map[net.Meta]net.Weights)
 	neuron := r.neurons[0]
-	weights[neuron.input.Meta()] = *neuron.input.Weights()
-	weights[neuron.hidden.Meta()] = *neuron.input.Weights()
-	weights[neuron.activation.Meta()] = *neuron.input.Weights()
-	weights[neuron.output.Meta()] = *neuron.input.Weights()
+	weights[neuron.input.Meta()] = neuron.input.Weights().Copy()
+	weights[neuron.hidden.Meta()] = neuron.input.Weights().Copy()
+	weights[neuron.activation.Meta()] = neuron.input.Weights().Copy()
+	weights[neuron.output.Meta()] = neuron.input.Weights().Copy()
 	return weights
 }
 
@@ -1,6 +1,7 @@
 package rnn
 
 import (
+	"errors"
 	"fmt"
 	"math"
 	"testing"
@@ -9,6 +10,7 @@ import (
 	"github.com/drakos74/go-ex-machina/xmachina/net"
 	"github.com/drakos74/go-ex-machina/xmachina/net/rc"
 	"github.com/drakos74/go-ex-machina/xmath"
+	"github.com/stretchr/testify/assert"
 )
 
 func Test_RNNetworkSineFunc(t *testing.T) {
@@ -23,16 +25,82 @@ func Test_RNNetworkSineFunc(t *testing.T) {
 	f := 0.025
 
 	var err xmath.Vector
+	var e error
 	for i := 0; i < 1000; i++ {
 
 		x := f * float64(i)
 
 		s := math.Sin(x)
 		output := xmath.Vec(1)
-		err, _ = network.Train(xmath.Vec(1).With(s), output)
+		err, _, e = network.Train(xmath.Vec(1).With(s), output)
+		assert.NoError(t, e)
 		println(fmt.Sprintf("err = %v", err.Op(math.Abs).Sum()))
 	}
 
 	println(fmt.Sprintf("err = %v", err.Op(math.Abs).Sum()))
 
 }
+
+func TestNetwork_TrainInvalid(t *testing.T) {
+
+	newNetwork := func() *rc.Network {
+		builder := rc.NewNeuronBuilder(1, 1, 5).
+			WithRate(*ml.Rate(0.05)).
+			WithWeights(xmath.Const(0.1), xmath.Const(0.2)).
+			WithActivation(ml.TanH, ml.Sigmoid)
+		return rc.New(3, New(*builder), net.NewClip(0.5, 0.5))
+	}
+
+	type test struct {
+		input, output xmath.Vector
+		location      string
+	}
+
+	tests := map[string]test{
+		"input-size": {
+			input:    xmath.Vec(2),
+			output:   xmath.Vec(1),
+			location: "input",
+		},
+		"input-nan": {
+			input:    xmath.Vec(1).With(math.NaN()),
+			output:   xmath.Vec(1),
+			location: "input",
+		},
+		"output-size": {
+			input:    xmath.Vec(1).With(0.5),
+			output:   xmath.Vec(2),
+			location: "output",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			valid := newNetwork()
+			invalid := newNetwork()
+
+			for i := 0; i < 10; i++ {
+				input := xmath.Vec(1).With(math.Sin(0.1 * float64(i)))
+				// once before and once after the training batch of 4 elements is full
+				if i == 1 || i == 5 {
+					_, _, err := invalid.Train(tt.input, tt.output)
+					var shapeErr *xmath.ShapeError
+					var nanErr *xmath.NaNError
+					assert.True(t, errors.As(err, &shapeErr) || errors.As(err, &nanErr), fmt.Sprintf("unexpected error %v", err))
+				}
+
+				// the rejected calls leave no trace in the training of the network
+				expected := xmath.Vec(1)
+				expectedLoss, _, err := valid.Train(input, expected)
+				assert.NoError(t, err)
+				output := xmath.Vec(1)
+				loss, _, err := invalid.Train(input, output)
+				assert.NoError(t, err)
+				assert.Equal(t, expectedLoss, loss)
+				assert.Equal(t, expected, output)
+				assert.Equal(t, valid.GetInfo(), invalid.GetInfo())
+			}
+		})
+	}
+
+}
@@ -0,0 +1,130 @@
+package prep
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/drakos74/go-ex-machina/xmath"
+)
+
+// categories returns the sorted distinct values of the given column.
+func categories(data xmath.Matrix, column int) ([]float64, error) {
+	dim, err := mustFit(data)
+	if err != nil {
+		return nil, err
+	}
+	if column < 0 || column >= dim {
+		return nil, fmt.Errorf("column %d out of range for dimension %d", column, dim)
+	}
+	values := make(map[float64]struct{})
+	for _, row := range data {
+		values[row[column]] = struct{}{}
+	}
+	cats := make([]float64, 0, len(values))
+	for v := range values {
+		cats = append(cats, v)
+	}
+	sort.Float64s(cats)
+	return cats, nil
+}
+
+// index returns the index of the value within the sorted categories, or -1 if it is not found.
+func index(cats []float64, v float64) int {
+	i := sort.SearchFloat64s(cats, v)
+	if i < len(cats) && cats[i] == v {
+		return i
+	}
+	return -1
+}
+
+// LabelEncoder replaces the values of a column with the index of the corresponding label.
+type LabelEncoder struct {
+	Column int       `json:"column"`
+	Labels []float64 `json:"labels"`
+}
+
+// NewLabelEncoder creates a new label encoder for the given column.
+func NewLabelEncoder(column int) *LabelEncoder {
+	return &LabelEncoder{
+		Column: column,
+	}
+}
+
+// Fit collects the distinct labels of the column.
+func (e *LabelEncoder) Fit(data xmath.Matrix) error {
+	labels, err := categories(data, e.Column)
+	if err != nil {
+		return err
+	}
+	e.Labels = labels
+	return nil
+}
+
+// Transform replaces the column value with the label index.
+// Unknown labels are encoded as -1.
+func (e *LabelEncoder) Transform(v xmath.Vector) xmath.Vector {
+	w := v.Copy()
+	w[e.Column] = float64(index(e.Labels, v[e.Column]))
+	return w
+}
+
+// Inverse replaces the label index with the original label.
+func (e *LabelEncoder) Inverse(v xmath.Vector) xmath.Vector {
+	w := v.Copy()
+	i := int(xmath.Clip(0, float64(len(e.Labels)-1))(v[e.Column]))
+	w[e.Column] = e.Labels[i]
+	return w
+}
+
+// OneHotEncoder replaces a column with a one-hot encoded vector of its categories.
+type OneHotEncoder struct {
+	Column     int       `json:"column"`
+	Categories []float64 `json:"categories"`
+}
+
+// NewOneHotEncoder creates a new one-hot encoder for the given column.
+func NewOneHotEncoder(column int) *OneHotEncoder {
+	return &OneHotEncoder{
+		Column: column,
+	}
+}
+
+// Fit collects the distinct categories of the column.
+func (e *OneHotEncoder) Fit(data xmath.Matrix) error {
+	cats, err := categories(data, e.Column)
+	if err != nil {
+		return err
+	}
+	e.Categories = cats
+	return nil
+}
+
+// Transform replaces the column with the one-hot encoding of its value.
+// Unknown categories are encoded with all elements set to zero.
+func (e *OneHotEncoder) Transform(v xmath.Vector) xmath.Vector {
+	hot := xmath.Vec(len(e.Categories))
+	if i := index(e.Categories, v[e.Column]); i >= 0 {
+		hot[i] = 1
+	}
+	w := xmath.Vec(len(v) - 1 + len(e.Categories))
+	copy(w, v[:e.Column])
+	copy(w[e.Column:], hot)
+	copy(w[e.Column+len(hot):], v[e.Column+1:])
+	return w
+}
+
+// Inverse replaces the one-hot encoding with the category of the highest element.
+func (e *OneHotEncoder) Inverse(v xmath.Vector) xmath.Vector {
+	hot := v[e.Column : e.Column+len(e.Categories)]
+	max := 0
+	for i := range hot {
+		if hot[i] > hot[max] {
+			max = i
+		}
+	}
+	w := xmath.Vec(len(v) + 1 - len(e.Categories))
+	copy(w, v[:e.Column])
+	w[e.Column] = e.Categories[max]
+	copy(w[e.Column+1:], v[e.Column+len(hot):])
+	return w
+}
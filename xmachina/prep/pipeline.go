@@ -0,0 +1,151 @@
+package prep
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/drakos74/go-ex-machina/xmath"
+)
+
+// Kind identifies a transformer type for serialization.
+type Kind string
+
+const (
+	// Standard is the kind of the StandardScaler.
+	Standard Kind = "standard"
+	// MinMax is the kind of the MinMaxScaler.
+	MinMax Kind = "min-max"
+	// Robust is the kind of the RobustScaler.
+	Robust Kind = "robust"
+	// OneHot is the kind of the OneHotEncoder.
+	OneHot Kind = "one-hot"
+	// Label is the kind of the LabelEncoder.
+	Label Kind = "label"
+)
+
+// kindOf returns the kind of the given transformer.
+func kindOf(t Transformer) (Kind, error) {
+	switch t.(type) {
+	case *StandardScaler:
+		return Standard, nil
+	case *MinMaxScaler:
+		return MinMax, nil
+	case *RobustScaler:
+		return Robust, nil
+	case *OneHotEncoder:
+		return OneHot, nil
+	case *LabelEncoder:
+		return Label, nil
+	case *Pipeline:
+		return "", fmt.Errorf("nested pipelines cannot be serialized")
+	}
+	return "", fmt.Errorf("unknown transformer %T", t)
+}
+
+// newOfKind creates a new empty transformer of the given kind.
+func newOfKind(kind Kind) (Transformer, error) {
+	switch kind {
+	case Standard:
+		return NewStandardScaler(), nil
+	case MinMax:
+		return &MinMaxScaler{}, nil
+	case Robust:
+		return NewRobustScaler(), nil
+	case OneHot:
+		return &OneHotEncoder{}, nil
+	case Label:
+		return &LabelEncoder{}, nil
+	}
+	return nil, fmt.Errorf("unknown transformer kind '%s'", kind)
+}
+
+// Pipeline chains a sequence of transformers.
+// Each step is fitted on the output of the previous one.
+type Pipeline struct {
+	steps []Transformer
+}
+
+// NewPipeline creates a new pipeline out of the given steps.
+func NewPipeline(steps ...Transformer) *Pipeline {
+	return &Pipeline{
+		steps: steps,
+	}
+}
+
+// Steps returns the transformers of the pipeline.
+func (p *Pipeline) Steps() []Transformer {
+	return p.steps
+}
+
+// Fit fits all steps in order.
+func (p *Pipeline) Fit(data xmath.Matrix) error {
+	for i, step := range p.steps {
+		m, err := FitApply(step, data)
+		if err != nil {
+			return fmt.Errorf("could not fit step %d: %w", i, err)
+		}
+		data = m
+	}
+	return nil
+}
+
+// Transform applies all steps in order.
+func (p *Pipeline) Transform(v xmath.Vector) xmath.Vector {
+	for _, step := range p.steps {
+		v = step.Transform(v)
+	}
+	return v
+}
+
+// Inverse reverses all steps in reverse order.
+func (p *Pipeline) Inverse(v xmath.Vector) xmath.Vector {
+	for i := len(p.steps) - 1; i >= 0; i-- {
+		v = p.steps[i].Inverse(v)
+	}
+	return v
+}
+
+type step struct {
+	Kind   Kind            `json:"kind"`
+	Params json.RawMessage `json:"params"`
+}
+
+// MarshalJSON serializes the pipeline steps along with their kind.
+func (p *Pipeline) MarshalJSON() ([]byte, error) {
+	steps := make([]step, len(p.steps))
+	for i, t := range p.steps {
+		kind, err := kindOf(t)
+		if err != nil {
+			return nil, err
+		}
+		params, err := json.Marshal(t)
+		if err != nil {
+			return nil, err
+		}
+		steps[i] = step{
+			Kind:   kind,
+			Params: params,
+		}
+	}
+	return json.Marshal(steps)
+}
+
+// UnmarshalJSON restores the pipeline steps.
+func (p *Pipeline) UnmarshalJSON(b []byte) error {
+	var steps []step
+	if err := json.Unmarshal(b, &steps); err != nil {
+		return err
+	}
+	p.steps = make([]Transformer, len(steps))
+	for i, s := range steps {
+		t, err := newOfKind(s.Kind)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(s.Params, t); err != nil {
+			return fmt.Errorf("could not restore step %d: %w", i, err)
+		}
+		p.steps[i] = t
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package prep
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/drakos74/go-ex-machina/xmath"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncoders(t *testing.T) {
+
+	m := xmath.Mat(3).With(
+		xmath.Vec(3).With(1, 7, 10),
+		xmath.Vec(3).With(2, 3, 20),
+		xmath.Vec(3).With(3, 7, 30),
+	)
+
+	oneHot := NewOneHotEncoder(1)
+	encoded, err := FitApply(oneHot, m)
+	assert.NoError(t, err)
+	assert.Equal(t, xmath.Vec(4).With(1, 0, 1, 10), encoded[0])
+	assert.Equal(t, xmath.Vec(4).With(2, 1, 0, 20), encoded[1])
+	// unknown categories have no hot element
+	assert.Equal(t, xmath.Vec(4).With(1, 0, 0, 10), oneHot.Transform(xmath.Vec(3).With(1, 5, 10)))
+
+	label := NewLabelEncoder(1)
+	encoded, err = FitApply(label, m)
+	assert.NoError(t, err)
+	assert.Equal(t, xmath.Vec(3).With(1, 1, 10), encoded[0])
+	assert.Equal(t, xmath.Vec(3).With(2, 0, 20), encoded[1])
+	assert.Equal(t, -1.0, label.Transform(xmath.Vec(3).With(1, 5, 10))[1])
+
+	for i := range m {
+		assert.Equal(t, m[i], oneHot.Inverse(oneHot.Transform(m[i])))
+		assert.Equal(t, m[i], label.Inverse(label.Transform(m[i])))
+	}
+
+	// predictions should be mapped to the most probable category
+	assert.Equal(t, xmath.Vec(3).With(1, 3, 10), oneHot.Inverse(xmath.Vec(4).With(1, 0.8, 0.3, 10)))
+
+}
+
+func TestPipeline(t *testing.T) {
+
+	pipeline := NewPipeline(
+		NewOneHotEncoder(2),
+		NewStandardScaler(),
+	)
+
+	m, err := FitApply(pipeline, data())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(m[0]))
+
+	b, err := json.Marshal(pipeline)
+	assert.NoError(t, err)
+
+	restored := NewPipeline()
+	err = json.Unmarshal(b, restored)
+	assert.NoError(t, err)
+	assert.Equal(t, pipeline, restored)
+
+	for i, row := range data() {
+		assert.Equal(t, m[i], restored.Transform(row))
+		assert.Equal(t, row.Op(xmath.Round(8)), restored.Inverse(m[i]).Op(xmath.Round(8)))
+	}
+
+	_, err = json.Marshal(NewPipeline(NewPipeline()))
+	assert.Error(t, err)
+
+}
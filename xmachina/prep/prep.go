@@ -0,0 +1,49 @@
+package prep
+
+import (
+	"fmt"
+
+	"github.com/drakos74/go-ex-machina/xmath"
+)
+
+// Transformer is a preprocessing step that learns its parameters from a data set
+// and applies them to individual vectors.
+type Transformer interface {
+	// Fit learns the transformer parameters from the rows of the given matrix.
+	Fit(data xmath.Matrix) error
+	// Transform applies the transformation to the given vector.
+	Transform(v xmath.Vector) xmath.Vector
+	// Inverse reverses the transformation e.g. to bring predictions back to the original scale.
+	Inverse(v xmath.Vector) xmath.Vector
+}
+
+// Apply transforms all rows of the given matrix.
+func Apply(t Transformer, data xmath.Matrix) xmath.Matrix {
+	m := xmath.Mat(len(data))
+	for i := range data {
+		m[i] = t.Transform(data[i])
+	}
+	return m
+}
+
+// FitApply fits the transformer to the data and transforms all its rows.
+func FitApply(t Transformer, data xmath.Matrix) (xmath.Matrix, error) {
+	if err := t.Fit(data); err != nil {
+		return nil, err
+	}
+	return Apply(t, data), nil
+}
+
+// mustFit checks that the data can be used to fit a transformer and returns its dimension.
+func mustFit(data xmath.Matrix) (int, error) {
+	if len(data) == 0 {
+		return 0, fmt.Errorf("cannot fit on empty data set")
+	}
+	dim := len(data[0])
+	for i := range data {
+		if len(data[i]) != dim {
+			return 0, fmt.Errorf("inconsistent dimensions at row %d: %d vs %d", i, len(data[i]), dim)
+		}
+	}
+	return dim, nil
+}
@@ -0,0 +1,177 @@
+package prep
+
+import (
+	"sort"
+
+	"github.com/drakos74/go-ex-machina/xmath"
+	"github.com/drakos74/go-ex-machina/xmath/buffer"
+)
+
+// collect pushes each column of the data set to its own stats.
+// The columns are kept independent, so that the cost stays linear in the dimensions e.g. for wide image data.
+func collect(data xmath.Matrix) ([]*buffer.Stats, error) {
+	dim, err := mustFit(data)
+	if err != nil {
+		return nil, err
+	}
+	stats := make([]*buffer.Stats, dim)
+	for i := range stats {
+		stats[i] = buffer.NewStats()
+	}
+	for _, row := range data {
+		for i, v := range row {
+			stats[i].Push(v)
+		}
+	}
+	return stats, nil
+}
+
+// nonZero replaces zero scales with 1, so that constant dimensions are left untouched.
+var nonZero xmath.Op = func(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	return x
+}
+
+// div divides the given numbers, without the smoothing of xmath.Div, so that transformations are exactly invertible.
+var div xmath.Dop = func(x, y float64) float64 {
+	return x / y
+}
+
+// StandardScaler scales each dimension to zero mean and unit variance.
+type StandardScaler struct {
+	Mean  xmath.Vector `json:"mean"`
+	StDev xmath.Vector `json:"stdev"`
+}
+
+// NewStandardScaler creates a new standard scaler.
+func NewStandardScaler() *StandardScaler {
+	return &StandardScaler{}
+}
+
+// Fit calculates the mean and standard deviation of each dimension.
+func (s *StandardScaler) Fit(data xmath.Matrix) error {
+	stats, err := collect(data)
+	if err != nil {
+		return err
+	}
+	s.Mean = xmath.Vec(len(stats))
+	s.StDev = xmath.Vec(len(stats))
+	for i, st := range stats {
+		s.Mean[i] = st.Avg()
+		s.StDev[i] = st.StDev()
+	}
+	s.StDev = s.StDev.Op(nonZero)
+	return nil
+}
+
+// Transform scales the vector.
+func (s *StandardScaler) Transform(v xmath.Vector) xmath.Vector {
+	return v.Diff(s.Mean).Dop(div, s.StDev)
+}
+
+// Inverse brings the vector back to the original scale.
+func (s *StandardScaler) Inverse(v xmath.Vector) xmath.Vector {
+	return v.X(s.StDev).Add(s.Mean)
+}
+
+// MinMaxScaler scales each dimension to the given range.
+type MinMaxScaler struct {
+	Low  float64      `json:"low"`
+	High float64      `json:"high"`
+	Min  xmath.Vector `json:"min"`
+	Max  xmath.Vector `json:"max"`
+}
+
+// NewMinMaxScaler creates a new min-max scaler for the range [low,high].
+func NewMinMaxScaler(low, high float64) *MinMaxScaler {
+	return &MinMaxScaler{
+		Low:  low,
+		High: high,
+	}
+}
+
+// Fit calculates the min and max of each dimension.
+func (s *MinMaxScaler) Fit(data xmath.Matrix) error {
+	stats, err := collect(data)
+	if err != nil {
+		return err
+	}
+	s.Min = xmath.Vec(len(stats))
+	s.Max = xmath.Vec(len(stats))
+	for i, st := range stats {
+		s.Min[i] = st.Min()
+		s.Max[i] = st.Max()
+	}
+	return nil
+}
+
+func (s *MinMaxScaler) scale() xmath.Vector {
+	return s.Max.Diff(s.Min).Op(nonZero).Op(func(x float64) float64 {
+		return (s.High - s.Low) / x
+	})
+}
+
+// Transform scales the vector.
+func (s *MinMaxScaler) Transform(v xmath.Vector) xmath.Vector {
+	return v.Diff(s.Min).X(s.scale()).Op(xmath.Add(s.Low))
+}
+
+// Inverse brings the vector back to the original scale.
+func (s *MinMaxScaler) Inverse(v xmath.Vector) xmath.Vector {
+	return v.Op(xmath.Add(-1*s.Low)).Dop(div, s.scale()).Add(s.Min)
+}
+
+// RobustScaler scales each dimension by removing the median and dividing by the inter-quartile range.
+// It is less sensitive to outliers than the StandardScaler.
+type RobustScaler struct {
+	Median xmath.Vector `json:"median"`
+	IQR    xmath.Vector `json:"iqr"`
+}
+
+// NewRobustScaler creates a new robust scaler.
+func NewRobustScaler() *RobustScaler {
+	return &RobustScaler{}
+}
+
+// Fit calculates the median and inter-quartile range of each dimension.
+func (s *RobustScaler) Fit(data xmath.Matrix) error {
+	dim, err := mustFit(data)
+	if err != nil {
+		return err
+	}
+	s.Median = xmath.Vec(dim)
+	s.IQR = xmath.Vec(dim)
+	column := make([]float64, len(data))
+	for j := 0; j < dim; j++ {
+		for i := range data {
+			column[i] = data[i][j]
+		}
+		sort.Float64s(column)
+		s.Median[j] = quantile(column, 0.5)
+		s.IQR[j] = quantile(column, 0.75) - quantile(column, 0.25)
+	}
+	s.IQR = s.IQR.Op(nonZero)
+	return nil
+}
+
+// Transform scales the vector.
+func (s *RobustScaler) Transform(v xmath.Vector) xmath.Vector {
+	return v.Diff(s.Median).Dop(div, s.IQR)
+}
+
+// Inverse brings the vector back to the original scale.
+func (s *RobustScaler) Inverse(v xmath.Vector) xmath.Vector {
+	return v.X(s.IQR).Add(s.Median)
+}
+
+// quantile returns the q-quantile of the sorted values, using linear interpolation.
+func quantile(sorted []float64, q float64) float64 {
+	pos := q * float64(len(sorted)-1)
+	i := int(pos)
+	if i+1 >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[i] + (pos-float64(i))*(sorted[i+1]-sorted[i])
+}
@@ -0,0 +1,98 @@
+package prep
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/drakos74/go-ex-machina/xmath"
+	"github.com/drakos74/go-ex-machina/xmath/buffer"
+	"github.com/stretchr/testify/assert"
+)
+
+func data() xmath.Matrix {
+	return xmath.Mat(5).With(
+		xmath.Vec(3).With(1, 10, 5),
+		xmath.Vec(3).With(2, 20, 5),
+		xmath.Vec(3).With(3, 30, 5),
+		xmath.Vec(3).With(4, 40, 5),
+		xmath.Vec(3).With(100, 50, 5),
+	)
+}
+
+func TestScalers(t *testing.T) {
+
+	type test struct {
+		transformer Transformer
+		check       func(t *testing.T, m xmath.Matrix)
+	}
+
+	tests := map[string]test{
+		"standard": {
+			transformer: NewStandardScaler(),
+			check: func(t *testing.T, m xmath.Matrix) {
+				for _, st := range collectT(t, m) {
+					assert.Equal(t, "0.00", fmt.Sprintf("%.2f", st.Avg()))
+				}
+				// constant dimensions should not be scaled
+				assert.Equal(t, 0.0, m[0][2])
+				assert.Equal(t, "1.00", fmt.Sprintf("%.2f", collectT(t, m)[1].StDev()))
+			},
+		},
+		"min-max": {
+			transformer: NewMinMaxScaler(0.01, 1),
+			check: func(t *testing.T, m xmath.Matrix) {
+				stats := collectT(t, m)
+				for i := 0; i < 2; i++ {
+					assert.Equal(t, 0.01, stats[i].Min())
+					assert.Equal(t, 1.0, stats[i].Max())
+				}
+			},
+		},
+		"robust": {
+			transformer: NewRobustScaler(),
+			check: func(t *testing.T, m xmath.Matrix) {
+				// median should be at zero
+				assert.Equal(t, xmath.Vec(3).With(0, 0, 0), m[2])
+				// outliers should not affect the scaling of the other elements
+				assert.Equal(t, xmath.Vec(3).With(-1, -1, 0), m[0])
+				assert.Equal(t, xmath.Vec(3).With(48.5, 1, 0), m[4])
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			m, err := FitApply(tt.transformer, data())
+			assert.NoError(t, err)
+			tt.check(t, m)
+			// make sure we can invert the transformation
+			for i, row := range data() {
+				assert.Equal(t, row.Op(xmath.Round(8)), tt.transformer.Inverse(m[i]).Op(xmath.Round(8)))
+			}
+		})
+	}
+
+}
+
+func TestScalers_FitErrors(t *testing.T) {
+
+	for name, transformer := range map[string]Transformer{
+		"standard": NewStandardScaler(),
+		"min-max":  NewMinMaxScaler(0, 1),
+		"robust":   NewRobustScaler(),
+		"one-hot":  NewOneHotEncoder(0),
+		"label":    NewLabelEncoder(0),
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, transformer.Fit(xmath.Mat(0)))
+			assert.Error(t, transformer.Fit(xmath.Mat(2).With(xmath.Vec(2), xmath.Vec(3))))
+		})
+	}
+
+}
+
+func collectT(t *testing.T, m xmath.Matrix) []*buffer.Stats {
+	stats, err := collect(m)
+	assert.NoError(t, err)
+	return stats
+}
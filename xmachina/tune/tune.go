@@ -0,0 +1,251 @@
+package tune
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/drakos74/go-ex-machina/xmachina/ml"
+	"github.com/drakos74/go-ex-machina/xmath/series"
+)
+
+// Params holds the parameter values of a configuration by name.
+type Params map[string]float64
+
+// Int returns the named parameter rounded to an integer e.g. for layer sizes.
+func (p Params) Int(name string) int {
+	return int(math.Round(p[name]))
+}
+
+// Activation returns the activation at the index of the named parameter.
+func (p Params) Activation(name string, activations ...ml.Activation) ml.Activation {
+	return activations[p.Int(name)]
+}
+
+// String prints the parameters in a stable order.
+func (p Params) String() string {
+	names := make([]string, 0, len(p))
+	for name := range p {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	builder := strings.Builder{}
+	for i, name := range names {
+		if i > 0 {
+			builder.WriteString(" , ")
+		}
+		builder.WriteString(fmt.Sprintf("%s = %v", name, p[name]))
+	}
+	return builder.String()
+}
+
+// Objective evaluates a configuration and returns its score.
+type Objective func(params Params) (float64, error)
+
+// Result is the outcome of evaluating a configuration.
+type Result struct {
+	Params Params
+	Score  float64
+	Err    error
+}
+
+// Leaderboard is a list of results ranked from best to worst.
+type Leaderboard []Result
+
+// Best returns the best result, or false if there are no results.
+func (l Leaderboard) Best() (Result, bool) {
+	if len(l) == 0 {
+		return Result{}, false
+	}
+	return l[0], true
+}
+
+type parameter struct {
+	name     string
+	value    *float64
+	sequence *series.Sequence
+}
+
+// Tuner searches the parameter space defined by its sequences for the configuration with the best score.
+type Tuner struct {
+	parameters  []parameter
+	parallelism int
+	maximize    bool
+}
+
+// New creates a new tuner that minimises the objective score.
+func New() *Tuner {
+	return &Tuner{
+		parameters:  make([]parameter, 0),
+		parallelism: 1,
+	}
+}
+
+// Parallel evaluates up to n configurations concurrently.
+// The objective must then be safe for concurrent use.
+func (t *Tuner) Parallel(n int) *Tuner {
+	if n < 1 {
+		n = 1
+	}
+	t.parallelism = n
+	return t
+}
+
+// Maximize ranks higher scores first e.g. for accuracy objectives.
+func (t *Tuner) Maximize() *Tuner {
+	t.maximize = true
+	return t
+}
+
+// Sequence adds a parameter evolving from the start value with the given transform, for limit values.
+func (t *Tuner) Sequence(name string, start float64, transform series.Transform, limit int) *Tuner {
+	value := start
+	return t.add(name, &value, series.NewSequence(&value, transform, limit))
+}
+
+// Range adds a parameter taking limit values from start towards end.
+func (t *Tuner) Range(name string, start, end float64, limit, rounding int) *Tuner {
+	var value float64
+	return t.add(name, &value, series.RangeSequence(&value, start, end, limit, rounding))
+}
+
+// Perturbation adds a parameter taking limit values around the given value.
+func (t *Tuner) Perturbation(name string, value, step float64, limit, rounding int) *Tuner {
+	v := value
+	return t.add(name, &v, series.PerturbationSequence(&v, step, limit, rounding))
+}
+
+// Choice adds a categorical parameter taking the indices of n options e.g. activation functions.
+func (t *Tuner) Choice(name string, n int) *Tuner {
+	return t.Range(name, 0, float64(n), n, 0)
+}
+
+func (t *Tuner) add(name string, value *float64, sequence *series.Sequence) *Tuner {
+	t.parameters = append(t.parameters, parameter{
+		name:     name,
+		value:    value,
+		sequence: sequence,
+	})
+	return t
+}
+
+// current captures the current values of the parameters.
+func (t *Tuner) current() Params {
+	params := make(Params, len(t.parameters))
+	for _, p := range t.parameters {
+		params[p.name] = *p.value
+	}
+	return params
+}
+
+// Grid evaluates the objective on all combinations of the parameter values.
+func (t *Tuner) Grid(objective Objective) Leaderboard {
+	sequences := make([]*series.Sequence, len(t.parameters))
+	for i, p := range t.parameters {
+		sequences[i] = p.sequence
+	}
+	evolution := series.NewEvolution(sequences...)
+
+	configs := make([]Params, 0, evolution.Limit())
+	for evolution.Next() {
+		configs = append(configs, t.current())
+	}
+	return t.run(configs, objective)
+}
+
+// Random evaluates the objective on n random combinations of the parameter values.
+func (t *Tuner) Random(n int, seed int64, objective Objective) Leaderboard {
+	rnd := rand.New(rand.NewSource(seed))
+
+	values := make([][]float64, len(t.parameters))
+	for i, p := range t.parameters {
+		values[i] = p.sequence.Run()
+	}
+
+	configs := make([]Params, n)
+	for i := 0; i < n; i++ {
+		params := make(Params, len(t.parameters))
+		for j, p := range t.parameters {
+			params[p.name] = values[j][rnd.Intn(len(values[j]))]
+		}
+		configs[i] = params
+	}
+	return t.run(configs, objective)
+}
+
+// Bayesian evaluates the objective on n combinations of the parameter values,
+// each one proposed out of the scores of the previous ones, so that it needs far fewer evaluations than Grid.
+// The evaluations are sequential, regardless of the parallelism.
+func (t *Tuner) Bayesian(n int, seed int64, objective Objective) Leaderboard {
+	params := make([]*series.Param, len(t.parameters))
+	for i, p := range t.parameters {
+		params[i] = p.sequence.Param()
+	}
+	search := series.NewBayesian(n, seed, params...)
+
+	results := make(Leaderboard, 0, n)
+	for search.Next() {
+		config := t.current()
+		score, err := objective(config)
+		results = append(results, Result{
+			Params: config,
+			Score:  score,
+			Err:    err,
+		})
+		// the search minimises the score, and skips the failed evaluations
+		switch {
+		case err != nil:
+			score = math.NaN()
+		case t.maximize:
+			score = -score
+		}
+		search.Report(score)
+	}
+	return t.rank(results)
+}
+
+// run evaluates the configurations and ranks the results.
+func (t *Tuner) run(configs []Params, objective Objective) Leaderboard {
+	results := make(Leaderboard, len(configs))
+
+	jobs := make(chan int)
+	wg := sync.WaitGroup{}
+	for w := 0; w < t.parallelism; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				score, err := objective(configs[i])
+				results[i] = Result{
+					Params: configs[i],
+					Score:  score,
+					Err:    err,
+				}
+			}
+		}()
+	}
+	for i := range configs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return t.rank(results)
+}
+
+// rank sorts the results from best to worst.
+func (t *Tuner) rank(results Leaderboard) Leaderboard {
+	sort.SliceStable(results, func(i, j int) bool {
+		// failed evaluations go last
+		if (results[i].Err == nil) != (results[j].Err == nil) {
+			return results[i].Err == nil
+		}
+		if t.maximize {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Score < results[j].Score
+	})
+	return results
+}
@@ -0,0 +1,176 @@
+package tune
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync/atomic"
+	"testing"
+
+	"github.com/drakos74/go-ex-machina/xmachina"
+	"github.com/drakos74/go-ex-machina/xmachina/ml"
+	"github.com/drakos74/go-ex-machina/xmachina/net"
+	"github.com/drakos74/go-ex-machina/xmachina/net/ff"
+	"github.com/drakos74/go-ex-machina/xmath"
+	"github.com/drakos74/go-ex-machina/xmath/series"
+	"github.com/stretchr/testify/assert"
+)
+
+// parabola has its minimum at x = 2 , y = -1
+var parabola Objective = func(params Params) (float64, error) {
+	return math.Pow(params["x"]-2, 2) + math.Pow(params["y"]+1, 2), nil
+}
+
+func TestTuner_Grid(t *testing.T) {
+
+	type test struct {
+		parallelism int
+	}
+
+	tests := map[string]test{
+		"sequential": {parallelism: 1},
+		"parallel":   {parallelism: 4},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var count int32
+			leaderboard := New().
+				Parallel(tt.parallelism).
+				Range("x", 0, 5, 5, 0).
+				Sequence("y", -3, series.IncNum(1, 0), 5).
+				Grid(func(params Params) (float64, error) {
+					atomic.AddInt32(&count, 1)
+					return parabola(params)
+				})
+
+			assert.Equal(t, int32(25), count)
+			assert.Equal(t, 25, len(leaderboard))
+			best, ok := leaderboard.Best()
+			assert.True(t, ok)
+			assert.Equal(t, Params{"x": 2, "y": -1}, best.Params)
+			assert.Equal(t, 0.0, best.Score)
+			for i := 1; i < len(leaderboard); i++ {
+				assert.True(t, leaderboard[i-1].Score <= leaderboard[i].Score)
+			}
+		})
+	}
+
+}
+
+func TestTuner_Random(t *testing.T) {
+
+	tuner := New().
+		Maximize().
+		Perturbation("x", 2, 1, 10, 0).
+		Choice("c", 3)
+
+	objective := func(params Params) (float64, error) {
+		if params.Int("c") == 2 {
+			return 0, fmt.Errorf("invalid choice")
+		}
+		return -1 * math.Abs(params["x"]-2), nil
+	}
+
+	leaderboard := tuner.Random(20, 1, objective)
+	assert.Equal(t, 20, len(leaderboard))
+
+	// same seed should produce the same configurations
+	assert.Equal(t, leaderboard, tuner.Random(20, 1, objective))
+
+	var failed bool
+	for i, result := range leaderboard {
+		assert.True(t, result.Params["x"] >= -3 && result.Params["x"] <= 6, result.Params.String())
+		assert.True(t, result.Params.Int("c") >= 0 && result.Params.Int("c") < 3, result.Params.String())
+		if result.Err != nil {
+			failed = true
+		} else {
+			// failed evaluations should be ranked last
+			assert.False(t, failed)
+		}
+		if i > 0 && result.Err == nil {
+			assert.True(t, leaderboard[i-1].Score >= result.Score)
+		}
+	}
+
+}
+
+func TestTuner_Bayesian(t *testing.T) {
+
+	var count int
+	tuner := New().
+		Range("x", 0, 10, 100, 1).
+		Range("y", -5, 5, 100, 1).
+		Choice("c", 2)
+
+	// a grid would need 100 * 100 * 2 evaluations
+	objective := func(params Params) (float64, error) {
+		count++
+		if params.Int("c") == 1 {
+			return 0, fmt.Errorf("invalid choice")
+		}
+		return parabola(params)
+	}
+	leaderboard := tuner.Bayesian(30, 1, objective)
+
+	assert.Equal(t, 30, count)
+	assert.Equal(t, 30, len(leaderboard))
+	best, ok := leaderboard.Best()
+	assert.True(t, ok)
+	assert.NoError(t, best.Err)
+	assert.True(t, best.Score < 0.1, best.Params.String())
+
+	// same seed should produce the same configurations
+	assert.Equal(t, leaderboard, tuner.Bayesian(30, 1, objective))
+
+	// without any evaluations there is no best result
+	_, ok = tuner.Bayesian(0, 1, objective).Best()
+	assert.False(t, ok)
+
+}
+
+func TestTuner_Network(t *testing.T) {
+
+	inputSet := xmath.Mat(2).With([]float64{1, 0}, []float64{0, 1})
+	outputSet := xmath.Mat(2).With([]float64{0, 1}, []float64{1, 0})
+
+	activations := []ml.Activation{ml.Sigmoid, ml.TanH}
+
+	leaderboard := New().
+		Parallel(2).
+		Range("rate", 0.1, 1.1, 2, 1).
+		Range("hidden", 2, 4, 2, 0).
+		Choice("activation", len(activations)).
+		Grid(func(params Params) (float64, error) {
+			builder := func() net.NeuronFactory {
+				return net.NewBuilder().
+					WithModule(ml.Base().
+						WithRate(ml.Learn(params["rate"], params["rate"])).
+						WithActivation(params.Activation("activation", activations...))).
+					WithWeights(xmath.Rand(-1, 1, xmath.Unit), xmath.Rand(-1, 1, xmath.Unit)).
+					Factory(net.NewActivationCell)
+			}
+			network := ff.New(2, 2).
+				Add(params.Int("hidden"), builder()).
+				Add(2, builder())
+			err := xmachina.TrainInMem(xmachina.Training(0.001, 0).WithEpochs(100), network, inputSet, outputSet)
+			if err != nil && !errors.Is(err, xmachina.ErrNotConverged) {
+				return 0, err
+			}
+			var loss float64
+			for i := range inputSet {
+				predicted, err := network.Predict(inputSet[i])
+				if err != nil {
+					return 0, err
+				}
+				loss += xmachina.MSE(outputSet[i], predicted)
+			}
+			return loss, nil
+		})
+
+	assert.Equal(t, 8, len(leaderboard))
+	for _, result := range leaderboard {
+		assert.NoError(t, result.Err)
+	}
+
+}
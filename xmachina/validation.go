@@ -0,0 +1,228 @@
+package xmachina
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/drakos74/go-ex-machina/xmachina/net"
+	"github.com/drakos74/go-ex-machina/xmath"
+	"github.com/drakos74/go-ex-machina/xmath/buffer"
+)
+
+// Constructor creates a new network with fresh weights.
+type Constructor func() net.NN
+
+// Metric scores a prediction against the expected output.
+type Metric func(expected, predicted xmath.Vector) float64
+
+// MAE is the mean absolute error of the prediction.
+var MAE Metric = func(expected, predicted xmath.Vector) float64 {
+	return expected.Diff(predicted).Op(math.Abs).Sum() / float64(len(expected))
+}
+
+// MSE is the mean squared error of the prediction.
+var MSE Metric = func(expected, predicted xmath.Vector) float64 {
+	return expected.Diff(predicted).Op(xmath.Square).Sum() / float64(len(expected))
+}
+
+// Accuracy is 1 if the predicted class matches the expected one, 0 otherwise.
+var Accuracy Metric = func(expected, predicted xmath.Vector) float64 {
+	if Class(expected) == Class(predicted) {
+		return 1
+	}
+	return 0
+}
+
+// Class returns the class label of the given output vector.
+// For one-dimensional outputs this is the rounded value, otherwise the index of the highest element.
+func Class(v xmath.Vector) int {
+	if len(v) == 1 {
+		return int(math.Round(v[0]))
+	}
+	max := 0
+	for i := range v {
+		if v[i] > v[max] {
+			max = i
+		}
+	}
+	return max
+}
+
+// Fold holds the evaluation results of a single cross-validation fold.
+// Converged is false if the training ran out of epochs before reaching the loss threshold.
+type Fold struct {
+	Index     int
+	TrainSize int
+	TestSize  int
+	Converged bool
+	Scores    map[string]float64
+}
+
+// Report holds the results of a cross-validation run.
+type Report struct {
+	Folds  []Fold
+	Scores map[string]*buffer.Stats
+}
+
+// CrossValidation runs k-fold cross-validation of a network on a data set.
+type CrossValidation struct {
+	k          int
+	training   InMemTraining
+	metrics    map[string]Metric
+	stratified bool
+	rnd        *rand.Rand
+}
+
+// KFold creates a new k-fold cross-validation, training each fold with the given config.
+// By default the data is split in order and the networks are scored with the MSE metric.
+func KFold(k int, training InMemTraining) *CrossValidation {
+	return &CrossValidation{
+		k:        k,
+		training: training,
+		metrics: map[string]Metric{
+			"mse": MSE,
+		},
+	}
+}
+
+// WithMetric adds a metric to evaluate on each fold.
+func (cv *CrossValidation) WithMetric(name string, metric Metric) *CrossValidation {
+	cv.metrics[name] = metric
+	return cv
+}
+
+// Stratified splits the data so that each fold has the same class proportions as the full data set.
+// The class of each sample is derived from its output vector.
+func (cv *CrossValidation) Stratified() *CrossValidation {
+	cv.stratified = true
+	return cv
+}
+
+// Shuffle shuffles the data set with the given seed before splitting it.
+func (cv *CrossValidation) Shuffle(seed int64) *CrossValidation {
+	cv.rnd = rand.New(rand.NewSource(seed))
+	return cv
+}
+
+// Run trains a fresh network for each fold on the remaining folds and scores it on the fold itself.
+// Networks that do not converge within the epochs are still scored, and their fold is marked accordingly.
+func (cv *CrossValidation) Run(constructor Constructor, inputSet, outputSet xmath.Matrix) (*Report, error) {
+
+	if len(inputSet) != len(outputSet) {
+		return nil, fmt.Errorf("inconsistent data set sizes %d vs %d", len(inputSet), len(outputSet))
+	}
+	if cv.k < 2 || cv.k > len(inputSet) {
+		return nil, fmt.Errorf("cannot split %d samples into %d folds", len(inputSet), cv.k)
+	}
+
+	folds := cv.split(outputSet)
+
+	report := &Report{
+		Folds:  make([]Fold, cv.k),
+		Scores: make(map[string]*buffer.Stats, len(cv.metrics)),
+	}
+	for name := range cv.metrics {
+		report.Scores[name] = buffer.NewStats()
+	}
+
+	for i, test := range folds {
+
+		trainInput := xmath.Mat(0)
+		trainOutput := xmath.Mat(0)
+		for j, fold := range folds {
+			if j == i {
+				continue
+			}
+			for _, idx := range fold {
+				trainInput = append(trainInput, inputSet[idx])
+				trainOutput = append(trainOutput, outputSet[idx])
+			}
+		}
+
+		network := constructor()
+		err := TrainInMem(cv.training, network, trainInput, trainOutput)
+		if err != nil && !errors.Is(err, ErrNotConverged) {
+			return nil, fmt.Errorf("could not train fold %d: %w", i, err)
+		}
+
+		stats := make(map[string]*buffer.Stats, len(cv.metrics))
+		for name := range cv.metrics {
+			stats[name] = buffer.NewStats()
+		}
+		for _, idx := range test {
+			predicted, err := network.Predict(inputSet[idx])
+			if err != nil {
+				return nil, fmt.Errorf("could not evaluate fold %d: %w", i, err)
+			}
+			for name, metric := range cv.metrics {
+				stats[name].Push(metric(outputSet[idx], predicted))
+			}
+		}
+
+		fold := Fold{
+			Index:     i,
+			TrainSize: len(trainInput),
+			TestSize:  len(test),
+			Converged: err == nil,
+			Scores:    make(map[string]float64, len(cv.metrics)),
+		}
+		for name, s := range stats {
+			fold.Scores[name] = s.Avg()
+			report.Scores[name].Push(s.Avg())
+		}
+		report.Folds[i] = fold
+	}
+
+	return report, nil
+}
+
+// split assigns the sample indices to the folds.
+func (cv *CrossValidation) split(outputSet xmath.Matrix) [][]int {
+
+	indices := make([]int, len(outputSet))
+	for i := range indices {
+		indices[i] = i
+	}
+	if cv.rnd != nil {
+		cv.rnd.Shuffle(len(indices), func(i, j int) {
+			indices[i], indices[j] = indices[j], indices[i]
+		})
+	}
+
+	folds := make([][]int, cv.k)
+
+	if !cv.stratified {
+		// split in contiguous chunks
+		for i, idx := range indices {
+			f := i * cv.k / len(indices)
+			folds[f] = append(folds[f], idx)
+		}
+		return folds
+	}
+
+	// group the indices by class
+	classes := make(map[int][]int)
+	for _, idx := range indices {
+		c := Class(outputSet[idx])
+		classes[c] = append(classes[c], idx)
+	}
+	labels := make([]int, 0, len(classes))
+	for c := range classes {
+		labels = append(labels, c)
+	}
+	sort.Ints(labels)
+
+	// deal the samples of each class to the folds in turn,
+	// continuing from the last fold, so that the fold sizes stay balanced
+	var f int
+	for _, c := range labels {
+		for _, idx := range classes[c] {
+			folds[f] = append(folds[f], idx)
+			f = (f + 1) % cv.k
+		}
+	}
+	return folds
+}
@@ -0,0 +1,133 @@
+package xmachina
+
+import (
+	"testing"
+
+	"github.com/drakos74/go-ex-machina/xmachina/ml"
+	"github.com/drakos74/go-ex-machina/xmachina/net"
+	"github.com/drakos74/go-ex-machina/xmachina/net/ff"
+	"github.com/drakos74/go-ex-machina/xmath"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCrossValidation_Split(t *testing.T) {
+
+	// 6 samples of class 0 and 3 of class 1
+	outputSet := xmath.Mat(9)
+	for i := range outputSet {
+		if i < 6 {
+			outputSet[i] = xmath.Vec(2).With(0.9, 0.1)
+		} else {
+			outputSet[i] = xmath.Vec(2).With(0.1, 0.9)
+		}
+	}
+
+	type test struct {
+		cv    *CrossValidation
+		check func(t *testing.T, folds [][]int)
+	}
+
+	tests := map[string]test{
+		"in-order": {
+			cv: KFold(3, Training(0.1, 0)),
+			check: func(t *testing.T, folds [][]int) {
+				assert.Equal(t, [][]int{{0, 1, 2}, {3, 4, 5}, {6, 7, 8}}, folds)
+			},
+		},
+		"stratified": {
+			cv: KFold(3, Training(0.1, 0)).Stratified(),
+			check: func(t *testing.T, folds [][]int) {
+				for _, fold := range folds {
+					classes := make(map[int]int)
+					for _, idx := range fold {
+						classes[Class(outputSet[idx])]++
+					}
+					// every fold should keep the 2:1 class ratio
+					assert.Equal(t, map[int]int{0: 2, 1: 1}, classes)
+				}
+			},
+		},
+		"shuffled-stratified": {
+			cv: KFold(3, Training(0.1, 0)).Stratified().Shuffle(1),
+			check: func(t *testing.T, folds [][]int) {
+				for _, fold := range folds {
+					classes := make(map[int]int)
+					for _, idx := range fold {
+						classes[Class(outputSet[idx])]++
+					}
+					assert.Equal(t, map[int]int{0: 2, 1: 1}, classes)
+				}
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			folds := tt.cv.split(outputSet)
+			assert.Equal(t, 3, len(folds))
+			// every sample should be in exactly one fold
+			seen := make(map[int]bool)
+			for _, fold := range folds {
+				for _, idx := range fold {
+					assert.False(t, seen[idx])
+					seen[idx] = true
+				}
+			}
+			assert.Equal(t, 9, len(seen))
+			tt.check(t, folds)
+		})
+	}
+
+}
+
+func TestCrossValidation_Run(t *testing.T) {
+
+	dataset, err := NewCSVLoader().
+		WithoutHeader().
+		Feature("0", Numeric, FillDrop).
+		Feature("1", Numeric, FillDrop).
+		Target("2", Numeric, FillDrop).
+		LoadFile("test/testdata/bin_class_input.csv")
+	assert.NoError(t, err)
+
+	constructed := 0
+	constructor := func() net.NN {
+		constructed++
+		return ff.New(2, 1).
+			Add(2, net.NewBuilder().
+				WithModule(ml.Base().
+					WithRate(ml.Learn(0.5, 0.05)).
+					WithActivation(ml.Sigmoid)).
+				WithWeights(xmath.Rand(-1, 1, xmath.Unit), xmath.Rand(-1, 1, xmath.Unit)).
+				Factory(net.NewActivationCell)).
+			Add(1, net.NewBuilder().
+				WithModule(ml.Base().
+					WithRate(ml.Learn(0.5, 0.05)).
+					WithActivation(ml.Sigmoid)).
+				WithWeights(xmath.Rand(-1, 1, xmath.Unit), xmath.Rand(-1, 1, xmath.Unit)).
+				Factory(net.NewActivationCell))
+	}
+
+	report, err := KFold(5, Training(0.001, 0).WithEpochs(500)).
+		Stratified().
+		Shuffle(1).
+		WithMetric("accuracy", Accuracy).
+		Run(constructor, dataset.InputSet, dataset.OutputSet)
+	assert.NoError(t, err)
+
+	// every fold should get a fresh network
+	assert.Equal(t, 5, constructed)
+	assert.Equal(t, 5, len(report.Folds))
+	for _, fold := range report.Folds {
+		assert.Equal(t, 2, fold.TestSize)
+		assert.Equal(t, 8, fold.TrainSize)
+		assert.Contains(t, fold.Scores, "mse")
+		assert.Contains(t, fold.Scores, "accuracy")
+	}
+	assert.Equal(t, 5, report.Scores["mse"].Count())
+	assert.Equal(t, 5, report.Scores["accuracy"].Count())
+
+	_, err = KFold(20, Training(0.001, 0)).Run(constructor, dataset.InputSet, dataset.OutputSet)
+	assert.Error(t, err)
+
+}
@@ -2,7 +2,9 @@ package xmachina
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"math"
 
@@ -10,16 +12,8 @@ import (
 	"github.com/drakos74/go-ex-machina/xmath"
 )
 
-type Pair struct {
-	input  xmath.Vector
-	output xmath.Vector
-}
-
-type Ack chan error
-
-type DataSource chan Pair
-
-type Epoch chan int
+// ErrNotConverged is returned when the epochs are exhausted before the loss falls below the threshold.
+var ErrNotConverged = errors.New("network did not converge")
 
 type InMemTraining struct {
 	lossThreshold    float64
@@ -52,41 +46,59 @@ func (t *InMemTraining) init() InMemTraining {
 	return *t
 }
 
+// WithEpochs limits the training to the given number of epochs.
+func (t InMemTraining) WithEpochs(epochs int) InMemTraining {
+	t.epochs = epochs
+	return t
+}
+
+// InStreamTraining defines the configuration for training a network from a streaming Source.
 type InStreamTraining struct {
 	InMemTraining
-	Epoch              Epoch
 	inputCountInterval int
-	outputSize         int
 }
 
-func StreamingTraining(cfg InMemTraining, outputSize int, inputLogInterval int) InStreamTraining {
+// StreamingTraining creates a new streaming training configuration.
+// inputLogInterval defines every how many inputs within an epoch the progress will be logged.
+func StreamingTraining(cfg InMemTraining, inputLogInterval int) InStreamTraining {
 	return InStreamTraining{
 		InMemTraining:      cfg,
-		Epoch:              make(Epoch),
-		outputSize:         outputSize,
 		inputCountInterval: inputLogInterval,
 	}
 }
 
 func (cfg *InStreamTraining) init() {
 	cfg.InMemTraining = cfg.InMemTraining.init()
-	if cfg.outputSize == 0 {
-		panic("cannot init streaming training without an predefined output size")
+	if cfg.inputCountInterval == 0 {
+		cfg.inputCountInterval = math.MaxInt32
 	}
 }
 
-func TrainInMem(config InMemTraining, network net.NN, inputSet xmath.Matrix, outputSet xmath.Matrix) {
+// TrainInMem trains the network on the given data set, until the loss falls below the threshold or the epochs are exhausted.
+// Invalid samples stop the training, and are reported with their index,
+// and ErrNotConverged is returned if the epochs are exhausted before the loss falls below the threshold.
+func TrainInMem(config InMemTraining, network net.NN, inputSet xmath.Matrix, outputSet xmath.Matrix) error {
 
 	config = config.init()
 
+	if err := xmath.ExpectDim("output set", outputSet, len(inputSet)); err != nil {
+		return err
+	}
+	if len(inputSet) == 0 {
+		return fmt.Errorf("cannot train network on an empty data set")
+	}
+
 	loss := math.MaxFloat64
 
 	for epoch := 0; epoch < config.epochs; epoch++ {
 		sumErr := xmath.Vec(len(outputSet[0]))
 		var finalWeights map[net.Meta]net.Weights
 		for i, input := range inputSet {
-			err, weights := network.Train(input, outputSet[i])
-			sumErr = sumErr.Add(err)
+			loss, weights, err := network.Train(input, outputSet[i])
+			if err != nil {
+				return fmt.Errorf("could not train on sample %d in epoch %d: %w", i, epoch, err)
+			}
+			sumErr = accumulate(sumErr, loss)
 			finalWeights = weights
 		}
 
@@ -100,61 +112,106 @@ func TrainInMem(config InMemTraining, network net.NN, inputSet xmath.Matrix, out
 
 		if sumErr.Norm() < config.lossThreshold {
 			log.Println(fmt.Sprintf("Epoch = %v ,error => %v < %v , weights = %v ", epoch, sumErr.Norm(), config.lossThreshold, finalWeights))
-			return
+			return nil
 		}
 
 	}
+	return fmt.Errorf("loss %v is above %v after %d epochs: %w", loss, config.lossThreshold, config.epochs, ErrNotConverged)
 }
 
-func TrainInStream(ctx context.Context, config InStreamTraining, network net.NN, data DataSource, ack Ack) {
-
-	defer close(ack)
+// TrainInStream trains the network by iterating over the source once for every epoch.
+// It stops when the loss falls below the threshold, the epochs are exhausted, or the context is cancelled.
+// Errors from the source and the context are returned to the caller,
+// and ErrNotConverged if the epochs are exhausted before the loss falls below the threshold.
+func TrainInStream(ctx context.Context, config InStreamTraining, network net.NN, source Source) error {
 
 	config.init()
 
-	sumErr := xmath.Vec(config.outputSize)
-	e := 0
-	score := 0.0
-	i := 0
+	loss := math.MaxFloat64
+
+	for e := 0; e < config.epochs; e++ {
+
+		sumErr, finalWeights, err := config.epoch(ctx, network, source, e)
+		if err != nil {
+			return fmt.Errorf("could not complete epoch %d: %w", e, err)
+		}
+
+		// log the iteration performance for monitoring
+		if config.debug && e%config.epochLogInterval == 0 {
+			score := loss - sumErr.Norm()
+			log.Println(fmt.Sprintf("Epoch = %v , error = %v , learningScore = %v , weights = %v ... ", e, sumErr.Norm(), score, finalWeights))
+		}
+
+		loss = sumErr.Norm()
+
+		if loss < config.lossThreshold {
+			log.Println(fmt.Sprintf("Epoch = %v ,error => %v < %v , weights = %v.", e, loss, config.lossThreshold, finalWeights))
+			return nil
+		}
+	}
+
+	return fmt.Errorf("loss %v is above %v after %d epochs: %w", loss, config.lossThreshold, config.epochs, ErrNotConverged)
+
+}
+
+// epoch runs a full pass over the source and returns the accumulated error.
+func (cfg InStreamTraining) epoch(ctx context.Context, network net.NN, source Source, e int) (xmath.Vector, map[net.Meta]net.Weights, error) {
+
+	it, err := source.Open()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var sumErr xmath.Vector
 	var finalWeights map[net.Meta]net.Weights
 
-	loss := math.MaxFloat64
+	for i := 1; ; i++ {
 
-	for {
 		select {
 		case <-ctx.Done():
-			log.Println(fmt.Sprintf("Epoch = %v , error = %v , learningScore = %v , weights = %v. Stopped!", e, sumErr.Norm(), score, finalWeights))
-			return
-		case pair := <-data:
-			i++
-			err, weights := network.Train(pair.input, pair.output)
-			sumErr = sumErr.Add(err.Op(math.Abs))
-			finalWeights = weights
-			if config.debug && i%config.inputCountInterval == 0 {
-				log.Println(fmt.Sprintf("e = %v , i = %v , err = %v", e, i, sumErr.Norm()))
-			}
-		case <-config.Epoch:
-			e++
-			// log the iteration performance for monitoring
-			if config.debug && e%config.epochLogInterval == 0 {
-				score = loss - sumErr.Norm()
-				log.Println(fmt.Sprintf("Epoch = %v , error = %v , learningScore = %v , weights = %v ... ", e, sumErr.Norm(), score, finalWeights))
-			}
+			_ = it.Close()
+			return nil, nil, ctx.Err()
+		default:
+		}
 
-			loss = sumErr.Norm()
+		inp, out, err := it.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			_ = it.Close()
+			return nil, nil, err
+		}
 
-			err := fmt.Errorf(fmt.Sprintf("Epoch = %v ,error => %v < %v , weights = %v.", e, sumErr.Norm(), config.lossThreshold, finalWeights))
+		loss, weights, err := network.Train(inp, out)
+		if err != nil {
+			_ = it.Close()
+			return nil, nil, fmt.Errorf("could not train on sample %d: %w", i, err)
+		}
+		if sumErr == nil {
+			sumErr = xmath.Vec(len(loss))
+		}
+		sumErr = accumulate(sumErr, loss)
+		finalWeights = weights
 
-			if sumErr.Norm() < config.lossThreshold {
-				err = nil
-			}
+		if cfg.debug && i%cfg.inputCountInterval == 0 {
+			log.Println(fmt.Sprintf("e = %v , i = %v , err = %v", e, i, sumErr.Norm()))
+		}
+	}
 
-			ack <- err
+	if err := it.Close(); err != nil {
+		return nil, nil, fmt.Errorf("could not close source: %w", err)
+	}
 
-			// reset the error
-			sumErr = xmath.Vec(config.outputSize)
-			i = 0
-		}
+	if sumErr == nil {
+		return nil, nil, fmt.Errorf("source did not produce any data")
 	}
 
+	return sumErr, finalWeights, nil
+}
+
+// accumulate adds the loss of a sample to the epoch error.
+// The absolute loss is used, so that the errors of different samples do not cancel out.
+func accumulate(sumErr, loss xmath.Vector) xmath.Vector {
+	return sumErr.Add(loss.Op(math.Abs))
 }
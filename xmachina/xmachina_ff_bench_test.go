@@ -3,6 +3,7 @@ package xmachina
 import (
 	"bytes"
 	"encoding/csv"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"strconv"
@@ -64,13 +65,17 @@ func TestNetwork_BinaryClassificationInMem_Benchmark(t *testing.T) {
 		outputSet[i] = out
 	}
 
-	TrainInMem(Training(0.001, 10000), network, inputSet, outputSet)
+	// the predictions are checked even if the loss did not fall below the threshold
+	if err := TrainInMem(Training(0.001, 10000).WithEpochs(5000), network, inputSet, outputSet); err != nil {
+		assert.True(t, errors.Is(err, ErrNotConverged), fmt.Sprintf("unexpected error %v", err))
+	}
 
 	// check trained network performance
 	for i, input := range inputSet {
-		o := network.Predict(input).Round()
+		o, err := network.Predict(input)
+		assert.NoError(t, err)
 		r := outputSet[i]
-		assert.Equal(t, o, r)
+		assert.Equal(t, o.Round(), r)
 	}
 
 }
@@ -4,8 +4,12 @@ import (
 	"bytes"
 	"context"
 	"encoding/csv"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"math"
+	"os"
 	"strconv"
 	"strings"
 	"testing"
@@ -26,7 +30,7 @@ func init() {
 func TestNetwork_BinaryClassificationSimple(t *testing.T) {
 
 	// build the network
-	network := ff.New(2, 1).
+	network := ff.New(2, 2).
 		Add(2,
 			net.NewBuilder().
 				WithModule(ml.Base().
@@ -39,14 +43,18 @@ func TestNetwork_BinaryClassificationSimple(t *testing.T) {
 	inputSet := xmath.Mat(2).With([]float64{1, 0}, []float64{0, 1})
 	outputSet := xmath.Mat(2).With([]float64{0, 1}, []float64{1, 0})
 
-	TrainInMem(Training(0.001, 10000), network, inputSet, outputSet)
+	// the predictions are checked even if the loss did not fall below the threshold
+	if err := TrainInMem(Training(0.001, 10000).WithEpochs(5000), network, inputSet, outputSet); err != nil {
+		assert.True(t, errors.Is(err, ErrNotConverged), fmt.Sprintf("unexpected error %v", err))
+	}
 
 	// check trained network performance
 
 	for i, input := range inputSet {
-		o := network.Predict(input).Round()
+		o, err := network.Predict(input)
+		assert.NoError(t, err)
 		r := outputSet[i]
-		assert.Equal(t, o, r)
+		assert.Equal(t, o.Round(), r)
 	}
 
 }
@@ -69,44 +77,29 @@ func TestNetwork_BinaryClassificationInMem(t *testing.T) {
 			Factory(net.NewActivationCell)) // output layer
 
 	// parse the input data
-	b, err := ioutil.ReadFile("test/testdata/bin_class_input.csv")
+	dataset, err := NewCSVLoader().
+		WithoutHeader().
+		Feature("0", Numeric, FillDrop).
+		Feature("1", Numeric, FillDrop).
+		Target("2", Numeric, FillDrop).
+		LoadFile("test/testdata/bin_class_input.csv")
 	assert.NoError(t, err)
 
-	reader := csv.NewReader(bytes.NewBuffer(b))
-
-	records, err := reader.ReadAll()
-	assert.NoError(t, err)
-
-	inputSet := xmath.Mat(len(records))
-	outputSet := xmath.Mat(len(records))
-
-	for i, record := range records {
-		inp := xmath.Vec(len(record) - 1)
-		out := xmath.Vec(len(record) - 2)
+	inputSet := dataset.InputSet
+	outputSet := dataset.OutputSet
 
-		for j, value := range record {
-			f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
-			if err != nil {
-				panic(fmt.Sprintf("cannot Train with non-numeric value %v: %v", value, err))
-			}
-			if j < 2 {
-				inp[j] = f
-			} else {
-				out[j-2] = f
-			}
-		}
-		inputSet[i] = inp
-		outputSet[i] = out
+	// the predictions are checked even if the loss did not fall below the threshold
+	if err := TrainInMem(Training(0.001, 10000).WithEpochs(5000), network, inputSet, outputSet); err != nil {
+		assert.True(t, errors.Is(err, ErrNotConverged), fmt.Sprintf("unexpected error %v", err))
 	}
 
-	TrainInMem(Training(0.001, 10000), network, inputSet, outputSet)
-
 	// check trained network performance
 
 	for i, input := range inputSet {
-		o := network.Predict(input).Round()
+		o, err := network.Predict(input)
+		assert.NoError(t, err)
 		r := outputSet[i]
-		assert.Equal(t, o, r)
+		assert.Equal(t, o.Round(), r)
 	}
 
 }
@@ -130,30 +123,25 @@ func TestNetwork_BinaryClassificationStream(t *testing.T) {
 			WithWeights(xmath.Rand(0, 1, xmath.Unit), xmath.Rand(0, 1, xmath.Unit)).
 			Factory(net.NewActivationCell)) // output layer
 
-	data := make(DataSource)
-	defer close(data)
-
-	config := StreamingTraining(Training(0.0001, 100), 1, 1000)
-	defer close(config.Epoch)
-
-	ack := make(Ack)
-
-	ctx, cnl := context.WithCancel(context.Background())
-	go TrainInStream(ctx, config, network, data, ack)
+	source := FileSource("test/testdata/bin_class_input.csv", parseBinClassLine)
 
-	inputSet, outputSet, err := ReadFile("test/testdata/bin_class_input.csv", 10, 500, parseBinClassLine, data, config.Epoch, ack)
+	config := StreamingTraining(Training(0.0001, 100).WithEpochs(500), 1000)
 
+	// the predictions are checked even if the loss did not fall below the threshold
+	err := TrainInStream(context.Background(), config, network, source)
 	if err != nil {
-		t.Fail()
+		assert.True(t, errors.Is(err, ErrNotConverged), fmt.Sprintf("unexpected error %v", err))
 	}
 
-	cnl()
+	inputSet, outputSet, err := ReadAll(source)
+	assert.NoError(t, err)
 
 	// check trained network performance
 	for i, input := range inputSet {
-		o := network.Predict(input).Round()
+		o, err := network.Predict(input)
+		assert.NoError(t, err)
 		r := outputSet[i]
-		assert.Equal(t, o, r)
+		assert.Equal(t, o.Round(), r)
 	}
 
 	d := time.Now().UnixNano() - start
@@ -169,30 +157,25 @@ func TestXNetwork_BinaryClassificationStream(t *testing.T) {
 		Add(2, ff.Perceptron(ml.Base(), xmath.Rand(0, 1, xmath.Unit))). // hidden layer
 		Add(1, ff.Perceptron(ml.Base(), xmath.Rand(0, 1, xmath.Unit)))  // output layer
 
-	data := make(DataSource)
-	defer close(data)
-
-	config := StreamingTraining(Training(0.0001, 100), 1, 1000)
-	defer close(config.Epoch)
-
-	ack := make(Ack)
+	source := FileSource("test/testdata/bin_class_input.csv", parseBinClassLine)
 
-	ctx, cnl := context.WithCancel(context.Background())
-	go TrainInStream(ctx, config, network, data, ack)
-
-	inputSet, outputSet, err := ReadFile("test/testdata/bin_class_input.csv", 10, 500, parseBinClassLine, data, config.Epoch, ack)
+	config := StreamingTraining(Training(0.0001, 100).WithEpochs(500), 1000)
 
+	// the predictions are checked even if the loss did not fall below the threshold
+	err := TrainInStream(context.Background(), config, network, source)
 	if err != nil {
-		t.Fail()
+		assert.True(t, errors.Is(err, ErrNotConverged), fmt.Sprintf("unexpected error %v", err))
 	}
 
-	cnl()
+	inputSet, outputSet, err := ReadAll(source)
+	assert.NoError(t, err)
 
 	// check trained network performance
 	for i, input := range inputSet {
-		o := network.Predict(input).Round()
+		o, err := network.Predict(input)
+		assert.NoError(t, err)
 		r := outputSet[i]
-		assert.Equal(t, o, r)
+		assert.Equal(t, o.Round(), r)
 	}
 
 	d := time.Now().UnixNano() - start
@@ -257,12 +240,16 @@ func TestNetwork_BinaryClassification2D_InMem(t *testing.T) {
 		outputSet[i] = out
 	}
 
-	TrainInMem(Training(0.00001, 10000), network, inputSet, outputSet)
+	// the predictions are checked even if the loss did not fall below the threshold
+	if err := TrainInMem(Training(0.00001, 10000).WithEpochs(5000), network, inputSet, outputSet); err != nil {
+		assert.True(t, errors.Is(err, ErrNotConverged), fmt.Sprintf("unexpected error %v", err))
+	}
 
 	// check trained network performance
 
 	for i, input := range inputSet {
-		o := network.Predict(input)
+		o, err := network.Predict(input)
+		assert.NoError(t, err)
 		r := outputSet[i]
 		assert.Equal(t, fmt.Sprintf("%.2f", r[0]), fmt.Sprintf("%.2f", o[0]))
 		assert.Equal(t, fmt.Sprintf("%.2f", r[1]), fmt.Sprintf("%.2f", o[1]))
@@ -270,14 +257,14 @@ func TestNetwork_BinaryClassification2D_InMem(t *testing.T) {
 
 }
 
-func parseBinClassLine(record []string) (inp, out xmath.Vector) {
+func parseBinClassLine(record []string) (inp, out xmath.Vector, err error) {
 	inp = xmath.Vec(len(record) - 1)
 	out = xmath.Vec(len(record) - 2)
 
 	for j, value := range record {
 		f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
 		if err != nil {
-			panic(fmt.Sprintf("cannot Train with non-numeric value %v: %v", value, err))
+			return nil, nil, fmt.Errorf("cannot Train with non-numeric value %v: %w", value, err)
 		}
 		if j < 2 {
 			inp[j] = f
@@ -285,5 +272,181 @@ func parseBinClassLine(record []string) (inp, out xmath.Vector) {
 			out[j-2] = f
 		}
 	}
-	return inp, out
+	return inp, out, nil
+}
+
+func TestTrainInStream_Errors(t *testing.T) {
+
+	network := ff.New(2, 1).
+		Add(1, net.NewBuilder().
+			WithModule(ml.Base().
+				WithRate(ml.Learn(0.05, 0.05)).
+				WithActivation(ml.Sigmoid)).
+			Factory(net.NewActivationCell))
+
+	type test struct {
+		source Source
+		ctx    func() context.Context
+		err    error
+	}
+
+	tests := map[string]test{
+		"missing-file": {
+			source: FileSource("test/testdata/missing.csv", parseBinClassLine),
+			ctx:    context.Background,
+			err:    os.ErrNotExist,
+		},
+		"parse-error": {
+			source: ReaderSource(func() (io.ReadCloser, error) {
+				return ioutil.NopCloser(strings.NewReader("1,2,0\n1,x,1\n")), nil
+			}, parseBinClassLine),
+			ctx: context.Background,
+			err: strconv.ErrSyntax,
+		},
+		"cancelled": {
+			source: FileSource("test/testdata/bin_class_input.csv", parseBinClassLine),
+			ctx: func() context.Context {
+				ctx, cnl := context.WithCancel(context.Background())
+				cnl()
+				return ctx
+			},
+			err: context.Canceled,
+		},
+		"not-converged": {
+			source: MemSource(xmath.Mat(2).With([]float64{1, 0}, []float64{0, 1}), xmath.Mat(2).With([]float64{0}, []float64{1})),
+			ctx:    context.Background,
+			err:    ErrNotConverged,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// no log interval should not cause any issues
+			config := StreamingTraining(Training(0.0001, 0).WithEpochs(10), 0)
+			err := TrainInStream(tt.ctx(), config, network, tt.source)
+			assert.True(t, errors.Is(err, tt.err), fmt.Sprintf("unexpected error %v", err))
+		})
+	}
+
+}
+
+func TestTrain_InvalidInput(t *testing.T) {
+
+	network := ff.New(2, 1).
+		Add(1, net.NewBuilder().
+			WithModule(ml.Base().
+				WithRate(ml.Learn(0.05, 0.05)).
+				WithActivation(ml.Sigmoid)).
+			Factory(net.NewActivationCell))
+
+	type test struct {
+		inputSet, outputSet xmath.Matrix
+		location            string
+		nan                 bool
+	}
+
+	tests := map[string]test{
+		"input-size": {
+			inputSet:  xmath.Mat(2).With([]float64{1, 0}, []float64{0, 1, 1}),
+			outputSet: xmath.Mat(2).With([]float64{0}, []float64{1}),
+			location:  "input",
+		},
+		"output-size": {
+			inputSet:  xmath.Mat(2).With([]float64{1, 0}, []float64{0, 1}),
+			outputSet: xmath.Mat(2).With([]float64{0}, []float64{1, 0}),
+			location:  "output",
+		},
+		"input-nan": {
+			inputSet:  xmath.Mat(2).With([]float64{1, 0}, []float64{math.NaN(), 1}),
+			outputSet: xmath.Mat(2).With([]float64{0}, []float64{1}),
+			location:  "input",
+			nan:       true,
+		},
+		"output-inf": {
+			inputSet:  xmath.Mat(2).With([]float64{1, 0}, []float64{0, 1}),
+			outputSet: xmath.Mat(2).With([]float64{0}, []float64{math.Inf(1)}),
+			location:  "output",
+			nan:       true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assertInvalid := func(err error) {
+				if tt.nan {
+					var nanErr *xmath.NaNError
+					assert.True(t, errors.As(err, &nanErr), fmt.Sprintf("unexpected error %v", err))
+					assert.Equal(t, tt.location, nanErr.Location)
+				} else {
+					var shapeErr *xmath.ShapeError
+					assert.True(t, errors.As(err, &shapeErr), fmt.Sprintf("unexpected error %v", err))
+					assert.Equal(t, tt.location, shapeErr.Location)
+				}
+			}
+			assertInvalid(TrainInMem(Training(0.0001, 0).WithEpochs(10), network, tt.inputSet, tt.outputSet))
+			config := StreamingTraining(Training(0.0001, 0).WithEpochs(10), 0)
+			assertInvalid(TrainInStream(context.Background(), config, network, MemSource(tt.inputSet, tt.outputSet)))
+		})
+	}
+
+	_, err := network.Predict(xmath.Vec(3))
+	var shapeErr *xmath.ShapeError
+	assert.True(t, errors.As(err, &shapeErr))
+
+	err = TrainInMem(Training(0.0001, 0), network, xmath.Mat(2), xmath.Mat(1))
+	assert.True(t, errors.As(err, &shapeErr))
+
+}
+
+func TestTrain_Converged(t *testing.T) {
+
+	network := ff.New(2, 1).
+		Add(1, net.NewBuilder().
+			WithModule(ml.Base().
+				WithRate(ml.Learn(0.05, 0.05)).
+				WithActivation(ml.Sigmoid)).
+			Factory(net.NewActivationCell))
+
+	inputSet := xmath.Mat(2).With([]float64{1, 0}, []float64{0, 1})
+	outputSet := xmath.Mat(2).With([]float64{0}, []float64{1})
+
+	type test struct {
+		threshold float64
+		err       error
+	}
+
+	tests := map[string]test{
+		// any loss is below the threshold
+		"converged": {threshold: 10},
+		// no loss is below the threshold within a single epoch
+		"not-converged": {threshold: 0.0001, err: ErrNotConverged},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// both training paths agree on the outcome
+			err := TrainInMem(Training(tt.threshold, 0).WithEpochs(1), network, inputSet, outputSet)
+			assert.True(t, errors.Is(err, tt.err), fmt.Sprintf("unexpected error %v", err))
+			config := StreamingTraining(Training(tt.threshold, 0).WithEpochs(1), 0)
+			err = TrainInStream(context.Background(), config, network, MemSource(inputSet, outputSet))
+			assert.True(t, errors.Is(err, tt.err), fmt.Sprintf("unexpected error %v", err))
+		})
+	}
+
+}
+
+func TestMemSource_MultipleEpochs(t *testing.T) {
+
+	inputSet := xmath.Mat(2).With([]float64{1, 0}, []float64{0, 1})
+	outputSet := xmath.Mat(2).With([]float64{0, 1}, []float64{1, 0})
+
+	source := MemSource(inputSet, outputSet)
+
+	for i := 0; i < 3; i++ {
+		inp, out, err := ReadAll(source)
+		assert.NoError(t, err)
+		assert.Equal(t, inputSet, inp)
+		assert.Equal(t, outputSet, out)
+	}
+
 }
@@ -39,8 +39,6 @@ func CartesianProduct(data [][]float64, current int, length int) [][]float64 {
 	return result
 }
 
-// TODO : clarify which methods mutate the vector and which not
-
 type Cube []Matrix
 
 func Cb(d int) Cube {
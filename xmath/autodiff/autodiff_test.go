@@ -0,0 +1,187 @@
+package autodiff
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/drakos74/go-ex-machina/xmath"
+	"github.com/stretchr/testify/assert"
+)
+
+// assertGradients compares the gradients of the tape with the numerical ones, for the sum of the function output.
+func assertGradients(t *testing.T, f func(inputs ...*Node) *Node, inputs ...xmath.Matrix) {
+	eval := func(tape *Tape) (*Node, []*Node) {
+		nodes := make([]*Node, len(inputs))
+		for i, m := range inputs {
+			nodes[i] = tape.Mat(m)
+		}
+		return Sum(f(nodes...)), nodes
+	}
+
+	tape := NewTape()
+	out, nodes := eval(tape)
+	tape.Backward(out, nil)
+
+	eps := 1e-6
+	for k, m := range inputs {
+		grad := nodes[k].GradMatrix()
+		for i := range m {
+			for j := range m[i] {
+				x := m[i][j]
+				m[i][j] = x + eps
+				up, _ := eval(NewTape())
+				m[i][j] = x - eps
+				down, _ := eval(NewTape())
+				m[i][j] = x
+				assert.InDelta(t, (up.Scalar()-down.Scalar())/(2*eps), grad[i][j], 1e-6, "input %d at [%d,%d]", k, i, j)
+			}
+		}
+	}
+}
+
+func randInput(rows, cols int, rng *rand.Rand) xmath.Matrix {
+	m := xmath.Mat(rows).Of(cols)
+	for i := range m {
+		for j := range m[i] {
+			m[i][j] = 0.5 + rng.Float64()
+		}
+	}
+	return m
+}
+
+func TestGradients(t *testing.T) {
+
+	type test struct {
+		f      func(inputs ...*Node) *Node
+		inputs [][2]int
+	}
+
+	tests := map[string]test{
+		"add": {
+			f: func(in ...*Node) *Node {
+				return Mul(Add(in[0], in[1]), in[0])
+			},
+			inputs: [][2]int{{3, 2}, {3, 2}},
+		},
+		"sub": {
+			f: func(in ...*Node) *Node {
+				return Mul(Sub(in[0], in[1]), in[1])
+			},
+			inputs: [][2]int{{4, 1}, {4, 1}},
+		},
+		"scale": {
+			f: func(in ...*Node) *Node {
+				return Mul(Scale(in[0], -3), in[0])
+			},
+			inputs: [][2]int{{2, 2}},
+		},
+		"matmul": {
+			f: func(in ...*Node) *Node {
+				return Mul(MatMul(in[0], in[1]), MatMul(in[0], in[1]))
+			},
+			inputs: [][2]int{{3, 4}, {4, 2}},
+		},
+		"matvec": {
+			f: func(in ...*Node) *Node {
+				return Sigmoid(Add(MatMul(in[0], in[1]), in[2]))
+			},
+			inputs: [][2]int{{3, 4}, {4, 1}, {3, 1}},
+		},
+		"transpose": {
+			f: func(in ...*Node) *Node {
+				return MatMul(Transpose(in[0]), in[0])
+			},
+			inputs: [][2]int{{3, 2}},
+		},
+		"activations": {
+			f: func(in ...*Node) *Node {
+				return Concat(Sigmoid(in[0]), Tanh(in[0]), ReLU(Scale(in[0], -1)), ReLU(in[0]), Exp(in[0]), Log(in[0]))
+			},
+			inputs: [][2]int{{3, 1}},
+		},
+		"concat-split": {
+			f: func(in ...*Node) *Node {
+				parts := Split(Concat(in[0], in[1]), 1, 4)
+				return Mul(parts[1], parts[1])
+			},
+			inputs: [][2]int{{2, 1}, {3, 1}},
+		},
+		"reductions": {
+			f: func(in ...*Node) *Node {
+				return Concat(Mul(Mean(in[0]), Max(in[0])), Dot(in[0], in[0]), Mul(SumRows(in[0]), SumRows(in[0])))
+			},
+			inputs: [][2]int{{2, 3}},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			rng := rand.New(rand.NewSource(1))
+			inputs := make([]xmath.Matrix, len(tt.inputs))
+			for i, dims := range tt.inputs {
+				inputs[i] = randInput(dims[0], dims[1], rng)
+			}
+			assertGradients(t, tt.f, inputs...)
+		})
+	}
+
+}
+
+func TestTape(t *testing.T) {
+
+	tape := NewTape()
+	x := tape.Var(xmath.Vec(2).With(1, 2))
+	w := tape.Mat(xmath.Mat(2).With(
+		xmath.Vec(2).With(1, 2),
+		xmath.Vec(2).With(3, 4),
+	))
+	y := MatMul(w, x)
+
+	assert.Equal(t, xmath.Vec(2).With(5, 11), y.Value())
+	rows, cols := y.Dims()
+	assert.Equal(t, 2, rows)
+	assert.Equal(t, 1, cols)
+
+	tape.Backward(y, xmath.Vec(2).With(1, -1))
+	assert.Equal(t, xmath.Vec(2).With(-2, -2), x.Grad())
+	assert.Equal(t, xmath.Mat(2).With(
+		xmath.Vec(2).With(1, 2),
+		xmath.Vec(2).With(-1, -2),
+	), w.GradMatrix())
+
+	// a new backward pass starts from clean gradients
+	tape.Backward(y, xmath.Vec(2).With(1, -1))
+	assert.Equal(t, xmath.Vec(2).With(-2, -2), x.Grad())
+
+	// the vector inputs share their data
+	x.Value()[0] = 0
+	tape.Reset()
+	assert.Equal(t, xmath.Vec(2).With(4, 8), MatMul(tape.Dense(xmath.DenseOf(w.Matrix())), tape.Var(xmath.Vec(2).With(0, 2))).Value())
+
+	assert.Panics(t, func() {
+		MatMul(x, w)
+	})
+	assert.Panics(t, func() {
+		Add(x, NewTape().Var(xmath.Vec(2)))
+	})
+
+}
+
+func TestApply(t *testing.T) {
+
+	tape := NewTape()
+	a := tape.Var(xmath.Vec(2).With(1, 2))
+	b := tape.Var(xmath.Vec(2).With(3, 4))
+	// an element-wise product with its own backward pass
+	c := Apply(a.Value().X(b.Value()), func(dy xmath.Vector) []xmath.Vector {
+		return []xmath.Vector{dy.X(b.Value()), dy.X(a.Value())}
+	}, a, b)
+	out := Sum(Mul(c, c))
+	tape.Backward(out, nil)
+
+	assert.Equal(t, 3.0*3+8*8, out.Scalar())
+	// d(a*b)^2/da = 2*a*b*b
+	assert.Equal(t, xmath.Vec(2).With(18, 64), a.Grad())
+	assert.Equal(t, xmath.Vec(2).With(6, 32), b.Grad())
+
+}
@@ -0,0 +1,268 @@
+package autodiff
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/drakos74/go-ex-machina/xmath"
+)
+
+// mustHaveSameDims will check and make sure that the nodes have the same dimensions.
+func mustHaveSameDims(a, b *Node) {
+	b.mustHaveDims(a.rows, a.cols)
+}
+
+// Add adds the two nodes element-wise.
+func Add(a, b *Node) *Node {
+	mustHaveSameDims(a, b)
+	return sameTape(a, b).record(a.rows, a.cols, a.value.Add(b.value), func(n *Node) {
+		a.grad.AddInPlace(n.grad)
+		b.grad.AddInPlace(n.grad)
+	})
+}
+
+// Sub subtracts the second node from the first element-wise.
+func Sub(a, b *Node) *Node {
+	mustHaveSameDims(a, b)
+	return sameTape(a, b).record(a.rows, a.cols, a.value.Diff(b.value), func(n *Node) {
+		a.grad.AddInPlace(n.grad)
+		b.grad.DiffInPlace(n.grad)
+	})
+}
+
+// Mul multiplies the two nodes element-wise.
+func Mul(a, b *Node) *Node {
+	mustHaveSameDims(a, b)
+	return sameTape(a, b).record(a.rows, a.cols, a.value.X(b.value), func(n *Node) {
+		for i, g := range n.grad {
+			a.grad[i] += g * b.value[i]
+			b.grad[i] += g * a.value[i]
+		}
+	})
+}
+
+// Scale multiplies the node with a constant number.
+func Scale(a *Node, s float64) *Node {
+	return a.tape.record(a.rows, a.cols, a.value.Mult(s), func(n *Node) {
+		a.grad.AddScaledInPlace(s, n.grad)
+	})
+}
+
+// MatMul returns the matrix product of the two nodes e.g. a (r x k) with b (k x c) gives an (r x c) node.
+// A matrix multiplied with a vector gives a vector.
+func MatMul(a, b *Node) *Node {
+	if a.cols != b.rows {
+		panic(fmt.Sprintf("cannot multiply nodes with dimensions '%vx%v' and '%vx%v'", a.rows, a.cols, b.rows, b.cols))
+	}
+	r, k, c := a.rows, a.cols, b.cols
+	value := xmath.Vec(r * c)
+	for i := 0; i < r; i++ {
+		for p := 0; p < k; p++ {
+			aip := a.value[i*k+p]
+			if aip == 0 {
+				continue
+			}
+			value[i*c:(i+1)*c].AddScaledInPlace(aip, b.value[p*c:(p+1)*c])
+		}
+	}
+	return sameTape(a, b).record(r, c, value, func(n *Node) {
+		for i := 0; i < r; i++ {
+			gi := n.grad[i*c : (i+1)*c]
+			for p := 0; p < k; p++ {
+				// da = dn * b^T
+				a.grad[i*k+p] += gi.Dot(b.value[p*c : (p+1)*c])
+				// db = a^T * dn
+				b.grad[p*c:(p+1)*c].AddScaledInPlace(a.value[i*k+p], gi)
+			}
+		}
+	})
+}
+
+// Transpose returns the transpose of the node.
+func Transpose(a *Node) *Node {
+	value := xmath.Vec(len(a.value))
+	for i := 0; i < a.rows; i++ {
+		for j := 0; j < a.cols; j++ {
+			value[j*a.rows+i] = a.value[i*a.cols+j]
+		}
+	}
+	return a.tape.record(a.cols, a.rows, value, func(n *Node) {
+		for i := 0; i < a.rows; i++ {
+			for j := 0; j < a.cols; j++ {
+				a.grad[i*a.cols+j] += n.grad[j*a.rows+i]
+			}
+		}
+	})
+}
+
+// Map applies the function f on every element of the node.
+// The derivative d is expressed in terms of the output of f, as for the ml activation functions e.g. y*(1-y) for the sigmoid.
+func Map(a *Node, f, d xmath.Op) *Node {
+	return a.tape.record(a.rows, a.cols, a.value.Op(f), func(n *Node) {
+		for i, g := range n.grad {
+			a.grad[i] += g * d(n.value[i])
+		}
+	})
+}
+
+// Sigmoid applies the sigmoid function on every element of the node.
+func Sigmoid(a *Node) *Node {
+	return Map(a, func(x float64) float64 {
+		return 1 / (1 + math.Exp(-x))
+	}, func(y float64) float64 {
+		return y * (1 - y)
+	})
+}
+
+// Tanh applies the hyperbolic tangent on every element of the node.
+func Tanh(a *Node) *Node {
+	return Map(a, math.Tanh, func(y float64) float64 {
+		return 1 - y*y
+	})
+}
+
+// ReLU applies the rectified linear function on every element of the node.
+func ReLU(a *Node) *Node {
+	return Map(a, func(x float64) float64 {
+		return math.Max(0, x)
+	}, func(y float64) float64 {
+		if y > 0 {
+			return 1
+		}
+		return 0
+	})
+}
+
+// Exp applies the exponential function on every element of the node.
+func Exp(a *Node) *Node {
+	return Map(a, math.Exp, func(y float64) float64 {
+		return y
+	})
+}
+
+// Log applies the natural logarithm on every element of the node.
+func Log(a *Node) *Node {
+	return a.tape.record(a.rows, a.cols, a.value.Op(math.Log), func(n *Node) {
+		for i, g := range n.grad {
+			a.grad[i] += g / a.value[i]
+		}
+	})
+}
+
+// Concat stacks the rows of the nodes one after the other.
+// All nodes must have the same number of columns e.g. vectors are concatenated into a longer vector.
+func Concat(nodes ...*Node) *Node {
+	t := sameTape(nodes...)
+	cols := nodes[0].cols
+	var rows int
+	for _, a := range nodes {
+		if a.cols != cols {
+			panic(fmt.Sprintf("cannot concat nodes with '%v' and '%v' columns", cols, a.cols))
+		}
+		rows += a.rows
+	}
+	value := xmath.Vec(rows * cols)
+	var offset int
+	for _, a := range nodes {
+		offset += copy(value[offset:], a.value)
+	}
+	return t.record(rows, cols, value, func(n *Node) {
+		offset := 0
+		for _, a := range nodes {
+			a.grad.AddInPlace(n.grad[offset : offset+len(a.value)])
+			offset += len(a.value)
+		}
+	})
+}
+
+// Split splits the rows of the node into consecutive nodes with the given number of rows.
+// It is the inverse of Concat.
+func Split(a *Node, rows ...int) []*Node {
+	var total int
+	for _, r := range rows {
+		total += r
+	}
+	if total != a.rows {
+		panic(fmt.Sprintf("cannot split node with '%v' rows into %v", a.rows, rows))
+	}
+	nodes := make([]*Node, len(rows))
+	var offset int
+	for i, r := range rows {
+		from, to := offset*a.cols, (offset+r)*a.cols
+		nodes[i] = a.tape.record(r, a.cols, a.value[from:to].Copy(), func(n *Node) {
+			a.grad[from:to].AddInPlace(n.grad)
+		})
+		offset += r
+	}
+	return nodes
+}
+
+// Sum returns the sum of all elements of the node.
+func Sum(a *Node) *Node {
+	var s float64
+	for _, x := range a.value {
+		s += x
+	}
+	return a.tape.record(1, 1, xmath.Vec(1).With(s), func(n *Node) {
+		for i := range a.grad {
+			a.grad[i] += n.grad[0]
+		}
+	})
+}
+
+// Mean returns the average of all elements of the node.
+func Mean(a *Node) *Node {
+	return Scale(Sum(a), 1/float64(len(a.value)))
+}
+
+// Max returns the largest element of the node, which is the only one to receive the gradient.
+func Max(a *Node) *Node {
+	max := 0
+	for i, x := range a.value {
+		if x > a.value[max] {
+			max = i
+		}
+	}
+	return a.tape.record(1, 1, xmath.Vec(1).With(a.value[max]), func(n *Node) {
+		a.grad[max] += n.grad[0]
+	})
+}
+
+// SumRows returns the sum of each row of the node as a vector.
+func SumRows(a *Node) *Node {
+	value := xmath.Vec(a.rows)
+	for i := range value {
+		for _, x := range a.value[i*a.cols : (i+1)*a.cols] {
+			value[i] += x
+		}
+	}
+	return a.tape.record(a.rows, 1, value, func(n *Node) {
+		for i := 0; i < a.rows; i++ {
+			for j := 0; j < a.cols; j++ {
+				a.grad[i*a.cols+j] += n.grad[i]
+			}
+		}
+	})
+}
+
+// Dot returns the dot product of the two nodes.
+func Dot(a, b *Node) *Node {
+	return Sum(Mul(a, b))
+}
+
+// Apply records an operation computed outside of the tape, which provides its own backward pass.
+// The backward function receives the gradient of the output vector and returns the gradients of the inputs, in the same order.
+func Apply(value xmath.Vector, backward func(dy xmath.Vector) []xmath.Vector, inputs ...*Node) *Node {
+	if len(inputs) == 0 {
+		panic("cannot apply an operation without inputs")
+	}
+	return sameTape(inputs...).record(len(value), 1, value, func(n *Node) {
+		grads := backward(n.grad)
+		if len(grads) != len(inputs) {
+			panic(fmt.Sprintf("operation must return a gradient for each input '%v' vs '%v'", len(inputs), len(grads)))
+		}
+		for i, a := range inputs {
+			a.grad.AddInPlace(grads[i])
+		}
+	})
+}
@@ -0,0 +1,175 @@
+package autodiff
+
+import (
+	"fmt"
+
+	"github.com/drakos74/go-ex-machina/xmath"
+)
+
+// Tape records the operations of a forward computation,
+// so that the gradients can be propagated back through them in reverse order.
+type Tape struct {
+	nodes []*Node
+}
+
+// NewTape creates a new empty tape.
+func NewTape() *Tape {
+	return &Tape{
+		nodes: make([]*Node, 0),
+	}
+}
+
+// Node is a value on the tape, together with the gradient of the output with respect to it.
+// Values are kept as matrices in row-major order, with vectors being matrices of a single column.
+type Node struct {
+	tape       *Tape
+	rows, cols int
+	value      xmath.Vector
+	grad       xmath.Vector
+	// backward propagates the gradient of the node to its inputs
+	backward func(n *Node)
+}
+
+// record adds a new node with the given value on the tape.
+func (t *Tape) record(rows, cols int, value xmath.Vector, backward func(n *Node)) *Node {
+	xmath.MustHaveSize(value, rows*cols)
+	n := &Node{
+		tape:     t,
+		rows:     rows,
+		cols:     cols,
+		value:    value,
+		grad:     xmath.Vec(len(value)),
+		backward: backward,
+	}
+	t.nodes = append(t.nodes, n)
+	return n
+}
+
+// Var adds the vector on the tape as an input, sharing its data.
+func (t *Tape) Var(v xmath.Vector) *Node {
+	return t.record(len(v), 1, v, nil)
+}
+
+// Scalar adds the number on the tape as an input.
+func (t *Tape) Scalar(x float64) *Node {
+	return t.record(1, 1, xmath.Vec(1).With(x), nil)
+}
+
+// Mat adds a copy of the matrix on the tape as an input.
+func (t *Tape) Mat(m xmath.Matrix) *Node {
+	var cols int
+	if len(m) > 0 {
+		cols = len(m[0])
+	}
+	value := xmath.Vec(len(m) * cols)
+	for i := range m {
+		xmath.MustHaveSize(m[i], cols)
+		copy(value[i*cols:], m[i])
+	}
+	return t.record(len(m), cols, value, nil)
+}
+
+// Dense adds a copy of the dense matrix on the tape as an input.
+func (t *Tape) Dense(d xmath.Dense) *Node {
+	rows, cols := d.Dims()
+	value := xmath.Vec(rows * cols)
+	for i := 0; i < rows; i++ {
+		copy(value[i*cols:], d.Row(i))
+	}
+	return t.record(rows, cols, value, nil)
+}
+
+// Reset removes all nodes from the tape, so that it can record a new computation.
+func (t *Tape) Reset() {
+	t.nodes = t.nodes[:0]
+}
+
+// Backward propagates the gradient from the given node back to all nodes recorded before it.
+// The seed is the gradient of the output with respect to the node, and defaults to ones if nil
+// e.g. for a scalar output the gradients are its derivatives.
+// The gradients of a previous backward pass are cleared.
+func (t *Tape) Backward(out *Node, seed xmath.Vector) {
+	if out.tape != t {
+		panic("cannot propagate gradient of a node from another tape")
+	}
+	for _, n := range t.nodes {
+		n.grad.Zero()
+	}
+	if seed == nil {
+		for i := range out.grad {
+			out.grad[i] = 1
+		}
+	} else {
+		xmath.MustHaveSize(seed, len(out.grad))
+		copy(out.grad, seed)
+	}
+	for i := len(t.nodes) - 1; i >= 0; i-- {
+		n := t.nodes[i]
+		if n.backward != nil {
+			n.backward(n)
+		}
+	}
+}
+
+// Dims returns the number of rows and columns of the node value.
+func (n *Node) Dims() (rows, cols int) {
+	return n.rows, n.cols
+}
+
+// Value returns the value of the node, with the matrix rows one after the other.
+func (n *Node) Value() xmath.Vector {
+	return n.value
+}
+
+// Matrix returns the value of the node as a matrix.
+func (n *Node) Matrix() xmath.Matrix {
+	return matrix(n.value, n.rows, n.cols)
+}
+
+// Scalar returns the value of a single element node.
+func (n *Node) Scalar() float64 {
+	n.mustHaveDims(1, 1)
+	return n.value[0]
+}
+
+// Grad returns the gradient of the node, with the matrix rows one after the other.
+func (n *Node) Grad() xmath.Vector {
+	return n.grad
+}
+
+// GradMatrix returns the gradient of the node as a matrix.
+func (n *Node) GradMatrix() xmath.Matrix {
+	return matrix(n.grad, n.rows, n.cols)
+}
+
+// String prints the node value in an easily readable form.
+func (n *Node) String() string {
+	return fmt.Sprintf("(%dx%d)%v", n.rows, n.cols, n.value)
+}
+
+// mustHaveDims will check and make sure that the node has the given dimensions.
+func (n *Node) mustHaveDims(rows, cols int) {
+	if n.rows != rows || n.cols != cols {
+		panic(fmt.Sprintf("node must have dimensions '%vx%v' vs '%vx%v'", rows, cols, n.rows, n.cols))
+	}
+}
+
+// matrix splits the data into the rows of a new matrix.
+func matrix(data xmath.Vector, rows, cols int) xmath.Matrix {
+	m := xmath.Mat(rows)
+	for i := range m {
+		m[i] = data[i*cols : (i+1)*cols].Copy()
+	}
+	return m
+}
+
+// sameTape returns the tape of the nodes, making sure they all belong to it.
+func sameTape(nodes ...*Node) *Tape {
+	t := nodes[0].tape
+	for _, n := range nodes[1:] {
+		if n.tape != t {
+			panic("cannot combine nodes from different tapes")
+		}
+	}
+	return t
+}
@@ -0,0 +1,169 @@
+package buffer
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// DefaultCompression is the compression for the quantile digests,
+// which keeps roughly up to 2 x compression centroids, with an accuracy better than 1% in the middle quantiles.
+const DefaultCompression = 100
+
+// centroid is a cluster of values summarised by their mean and count.
+type centroid struct {
+	mean   float64
+	weight float64
+}
+
+// Digest is a merging t-digest, estimating quantiles of a stream of numbers in bounded memory.
+// Its centroids are smaller at the tails of the distribution, so that the extreme quantiles are more accurate.
+// Two digests can be merged, so that separate streams e.g. from different goroutines can be combined.
+type Digest struct {
+	compression float64
+	centroids   []centroid
+	pending     []centroid
+	count       float64
+	min, max    float64
+}
+
+// NewDigest creates a new quantile digest with the given compression.
+func NewDigest(compression float64) *Digest {
+	if compression <= 0 {
+		panic(fmt.Sprintf("digest compression must be positive '%v'", compression))
+	}
+	return &Digest{
+		compression: compression,
+		centroids:   make([]centroid, 0),
+		pending:     make([]centroid, 0),
+		min:         math.MaxFloat64,
+		max:         -math.MaxFloat64,
+	}
+}
+
+// Push adds another element to the digest.
+func (d *Digest) Push(v float64) {
+	d.add(centroid{mean: v, weight: 1}, v, v)
+}
+
+// add buffers the centroid, compressing the digest when the buffer is full.
+func (d *Digest) add(c centroid, min, max float64) {
+	d.pending = append(d.pending, c)
+	d.count += c.weight
+	d.min = math.Min(d.min, min)
+	d.max = math.Max(d.max, max)
+	if float64(len(d.pending)) >= 4*d.compression {
+		d.compress()
+	}
+}
+
+// Count returns the number of elements.
+func (d *Digest) Count() int {
+	return int(d.count)
+}
+
+// Merge adds the elements of the other digest to this one.
+func (d *Digest) Merge(other *Digest) {
+	if other == nil || other.count == 0 {
+		return
+	}
+	for _, c := range other.centroids {
+		d.add(c, other.min, other.max)
+	}
+	for _, c := range other.pending {
+		d.add(c, other.min, other.max)
+	}
+	d.compress()
+}
+
+// scale maps the quantile to the k-scale of the digest, which limits the size of the centroids.
+func (d *Digest) scale(q float64) float64 {
+	return d.compression / (2 * math.Pi) * math.Asin(2*q-1)
+}
+
+// inverse maps the k-scale back to the quantile.
+func (d *Digest) inverse(k float64) float64 {
+	if k >= d.compression/4 {
+		return 1
+	}
+	return (math.Sin(2*math.Pi*k/d.compression) + 1) / 2
+}
+
+// compress merges the pending elements into the centroids.
+func (d *Digest) compress() {
+	if len(d.pending) == 0 {
+		return
+	}
+	d.centroids = d.merged()
+	d.pending = d.pending[:0]
+}
+
+// merged returns the centroids with the pending elements merged into them, without updating the digest,
+// so that it is safe to call from concurrent readers.
+func (d *Digest) merged() []centroid {
+	if len(d.pending) == 0 {
+		return d.centroids
+	}
+	all := make([]centroid, 0, len(d.centroids)+len(d.pending))
+	all = append(all, d.centroids...)
+	all = append(all, d.pending...)
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].mean < all[j].mean
+	})
+	merged := make([]centroid, 0, len(d.centroids)+1)
+	current := all[0]
+	var soFar float64
+	limit := d.count * d.inverse(d.scale(0)+1)
+	for _, c := range all[1:] {
+		if soFar+current.weight+c.weight <= limit {
+			current.weight += c.weight
+			current.mean += (c.mean - current.mean) * c.weight / current.weight
+			continue
+		}
+		merged = append(merged, current)
+		soFar += current.weight
+		limit = d.count * d.inverse(d.scale(soFar/d.count)+1)
+		current = c
+	}
+	return append(merged, current)
+}
+
+// Quantile returns the estimated value below which the given fraction of the elements lie.
+// It returns NaN if the digest is empty.
+// It does not update the digest, so that it can be called concurrently with other readers.
+func (d *Digest) Quantile(q float64) float64 {
+	if q < 0 || q > 1 {
+		panic(fmt.Sprintf("quantile must be within [0,1] '%v'", q))
+	}
+	if d.count == 0 {
+		return math.NaN()
+	}
+	centroids := d.merged()
+	target := q * d.count
+	first := centroids[0]
+	if target < first.weight/2 {
+		return interpolate(d.min, first.mean, target/(first.weight/2))
+	}
+	// walk through the centers of the centroids, where half of their weight lies on either side
+	var before float64
+	for i := 0; i < len(centroids)-1; i++ {
+		c, next := centroids[i], centroids[i+1]
+		center := before + c.weight/2
+		nextCenter := before + c.weight + next.weight/2
+		if target < nextCenter {
+			return interpolate(c.mean, next.mean, (target-center)/(nextCenter-center))
+		}
+		before += c.weight
+	}
+	last := centroids[len(centroids)-1]
+	center := d.count - last.weight/2
+	if target <= center {
+		return last.mean
+	}
+	return interpolate(last.mean, d.max, (target-center)/(last.weight/2))
+}
+
+// interpolate returns the point at the given fraction between from and to.
+func interpolate(from, to, f float64) float64 {
+	return from + (to-from)*f
+}
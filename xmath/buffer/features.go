@@ -0,0 +1,293 @@
+package buffer
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	xmath2 "github.com/drakos74/go-ex-machina/xmath"
+)
+
+// Rolling is an aggregation over the last values of a series.
+type Rolling int
+
+const (
+	// RollingMean is the average of the values in the window.
+	RollingMean Rolling = iota
+	// RollingStDev is the standard deviation of the values in the window.
+	RollingStDev
+	// RollingMin is the minimum of the values in the window.
+	RollingMin
+	// RollingMax is the maximum of the values in the window.
+	RollingMax
+)
+
+func (r Rolling) String() string {
+	switch r {
+	case RollingMean:
+		return "mean"
+	case RollingStDev:
+		return "std"
+	case RollingMin:
+		return "min"
+	case RollingMax:
+		return "max"
+	}
+	return fmt.Sprintf("rolling(%d)", int(r))
+}
+
+// Calendar is a feature derived from the time index of a value.
+// It is encoded as a point on the unit circle e.g. as sine and cosine,
+// so that the end of a cycle is close to its start e.g. 23:00 is close to 00:00.
+type Calendar int
+
+const (
+	// Hour is the hour of the day.
+	Hour Calendar = iota
+	// Weekday is the day of the week.
+	Weekday
+)
+
+func (c Calendar) String() string {
+	switch c {
+	case Hour:
+		return "hour"
+	case Weekday:
+		return "weekday"
+	}
+	return fmt.Sprintf("calendar(%d)", int(c))
+}
+
+// cycle returns the position of the time within the calendar cycle, as a fraction of it.
+func (c Calendar) cycle(t time.Time) float64 {
+	switch c {
+	case Hour:
+		return (float64(t.Hour()) + float64(t.Minute())/60) / 24
+	case Weekday:
+		return float64(t.Weekday()) / 7
+	}
+	panic(fmt.Sprintf("unknown calendar feature %v", c))
+}
+
+// feature is a single element of the feature vectors.
+type feature struct {
+	name string
+	// depth is the number of values it needs, including the current one
+	depth int
+	// value computes the feature out of the last values, with the current one at the end
+	value func(history []float64, t time.Time) float64
+}
+
+// Features builds feature vectors out of a time indexed series, e.g. the buckets of a TimeWindow.
+// The features appear in the vectors in the order they were added to the builder.
+type Features struct {
+	features []feature
+	depth    int
+	history  []float64
+	value    func(bucket *Bucket) float64
+}
+
+// NewFeatures creates a new feature builder with no features.
+// By default buckets contribute the average of their first dimension.
+func NewFeatures() *Features {
+	return &Features{
+		features: make([]feature, 0),
+		depth:    1,
+		history:  make([]float64, 0),
+		value: func(bucket *Bucket) float64 {
+			return bucket.Values().Stats()[0].Avg()
+		},
+	}
+}
+
+// add appends the feature to the builder.
+func (f *Features) add(feat feature) *Features {
+	if feat.depth > f.depth {
+		f.depth = feat.depth
+	}
+	f.features = append(f.features, feat)
+	return f
+}
+
+// WithBucketValue defines the value of the series for each bucket.
+func (f *Features) WithBucketValue(value func(bucket *Bucket) float64) *Features {
+	f.value = value
+	return f
+}
+
+// WithLags adds the values the given number of steps ago, where 0 is the current value.
+func (f *Features) WithLags(lags ...int) *Features {
+	for _, lag := range lags {
+		mustBeAtLeast("lag", lag, 0)
+		lag := lag
+		f.add(feature{
+			name:  fmt.Sprintf("lag_%d", lag),
+			depth: lag + 1,
+			value: func(history []float64, t time.Time) float64 {
+				return history[len(history)-1-lag]
+			},
+		})
+	}
+	return f
+}
+
+// WithRolling adds the aggregations of the last values over a window of the given size, including the current value.
+func (f *Features) WithRolling(window int, aggregations ...Rolling) *Features {
+	mustBeAtLeast("rolling window", window, 1)
+	for _, agg := range aggregations {
+		agg := agg
+		f.add(feature{
+			name:  fmt.Sprintf("rolling_%s_%d", agg, window),
+			depth: window,
+			value: func(history []float64, t time.Time) float64 {
+				stats := NewStats()
+				for _, v := range history[len(history)-window:] {
+					stats.Push(v)
+				}
+				switch agg {
+				case RollingMean:
+					return stats.Avg()
+				case RollingStDev:
+					return stats.StDev()
+				case RollingMin:
+					return stats.Min()
+				case RollingMax:
+					return stats.Max()
+				}
+				panic(fmt.Sprintf("unknown rolling aggregation %v", agg))
+			},
+		})
+	}
+	return f
+}
+
+// WithDiff adds the differences of the current value from the values the given number of steps ago.
+func (f *Features) WithDiff(periods ...int) *Features {
+	for _, p := range periods {
+		mustBeAtLeast("diff period", p, 1)
+		p := p
+		f.add(feature{
+			name:  fmt.Sprintf("diff_%d", p),
+			depth: p + 1,
+			value: func(history []float64, t time.Time) float64 {
+				n := len(history)
+				return history[n-1] - history[n-1-p]
+			},
+		})
+	}
+	return f
+}
+
+// WithPctChange adds the relative changes of the current value from the values the given number of steps ago.
+// The change from a zero value is taken as 0, so that the features remain valid numbers.
+func (f *Features) WithPctChange(periods ...int) *Features {
+	for _, p := range periods {
+		mustBeAtLeast("pct change period", p, 1)
+		p := p
+		f.add(feature{
+			name:  fmt.Sprintf("pct_change_%d", p),
+			depth: p + 1,
+			value: func(history []float64, t time.Time) float64 {
+				n := len(history)
+				if history[n-1-p] == 0 {
+					return 0
+				}
+				return (history[n-1] - history[n-1-p]) / history[n-1-p]
+			},
+		})
+	}
+	return f
+}
+
+// WithCalendar adds the sine and cosine of the given calendar features of the time index.
+func (f *Features) WithCalendar(calendar ...Calendar) *Features {
+	for _, c := range calendar {
+		c := c
+		f.add(feature{
+			name:  fmt.Sprintf("%s_sin", c),
+			depth: 1,
+			value: func(history []float64, t time.Time) float64 {
+				return math.Sin(2 * math.Pi * c.cycle(t))
+			},
+		})
+		f.add(feature{
+			name:  fmt.Sprintf("%s_cos", c),
+			depth: 1,
+			value: func(history []float64, t time.Time) float64 {
+				return math.Cos(2 * math.Pi * c.cycle(t))
+			},
+		})
+	}
+	return f
+}
+
+// mustBeAtLeast will check and make sure the parameter is not less than the given minimum.
+func mustBeAtLeast(name string, v, min int) {
+	if v < min {
+		panic(fmt.Sprintf("%s must be at least '%v' vs '%v'", name, min, v))
+	}
+}
+
+// Names returns the names of the features, in the order they appear in the vectors.
+func (f *Features) Names() []string {
+	names := make([]string, len(f.features))
+	for i, feat := range f.features {
+		names[i] = feat.name
+	}
+	return names
+}
+
+// Size returns the size of the feature vectors.
+func (f *Features) Size() int {
+	return len(f.features)
+}
+
+// Depth returns the number of values needed before the first feature vector can be built.
+func (f *Features) Depth() int {
+	return f.depth
+}
+
+// Push adds the next value of the series at the given time.
+// It returns the feature vector for it, once there are enough values to compute all features.
+func (f *Features) Push(t time.Time, v float64) (xmath2.Vector, bool) {
+	f.history = append(f.history, v)
+	if len(f.history) > f.depth {
+		copy(f.history, f.history[1:])
+		f.history = f.history[:f.depth]
+	}
+	if len(f.history) < f.depth {
+		return nil, false
+	}
+	return f.vector(f.history, t), true
+}
+
+// PushBucket adds the value of the bucket that closed at the given time, as returned by the TimeWindow and HistoryWindow.
+func (f *Features) PushBucket(t time.Time, bucket *Bucket) (xmath2.Vector, bool) {
+	return f.Push(t, f.value(bucket))
+}
+
+// Transform builds the feature vectors for a whole series, e.g. a column of a VectorRing batch,
+// without affecting the state of the builder.
+// The rows correspond to the values from Depth()-1 onwards, as the earlier ones do not have enough history.
+func (f *Features) Transform(times []time.Time, values xmath2.Vector) xmath2.Matrix {
+	if len(times) != len(values) {
+		panic(fmt.Sprintf("times and values must have the same size '%v' vs '%v'", len(times), len(values)))
+	}
+	if len(values) < f.depth {
+		return xmath2.Mat(0)
+	}
+	m := xmath2.Mat(len(values) - f.depth + 1)
+	for i := range m {
+		m[i] = f.vector(values[i:i+f.depth], times[i+f.depth-1])
+	}
+	return m
+}
+
+// vector computes the features out of the last values.
+func (f *Features) vector(history []float64, t time.Time) xmath2.Vector {
+	v := xmath2.Vec(len(f.features))
+	for i, feat := range f.features {
+		v[i] = feat.value(history, t)
+	}
+	return v
+}
@@ -0,0 +1,115 @@
+package buffer
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	xmath2 "github.com/drakos74/go-ex-machina/xmath"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeatures_Push(t *testing.T) {
+
+	features := NewFeatures().
+		WithLags(0, 2).
+		WithRolling(3, RollingMean, RollingStDev, RollingMin, RollingMax).
+		WithDiff(1).
+		WithPctChange(2).
+		WithCalendar(Hour, Weekday)
+
+	assert.Equal(t, []string{
+		"lag_0", "lag_2",
+		"rolling_mean_3", "rolling_std_3", "rolling_min_3", "rolling_max_3",
+		"diff_1", "pct_change_2",
+		"hour_sin", "hour_cos", "weekday_sin", "weekday_cos",
+	}, features.Names())
+	assert.Equal(t, 12, features.Size())
+	assert.Equal(t, 3, features.Depth())
+
+	// a monday at 06:00
+	start := time.Date(2020, 6, 1, 6, 0, 0, 0, time.UTC)
+	values := []float64{1, 2, 4, 8}
+	for i, v := range values {
+		vector, ok := features.Push(start.Add(time.Duration(i)*time.Hour), v)
+		if i < 2 {
+			assert.False(t, ok)
+			assert.Nil(t, vector)
+			continue
+		}
+		assert.True(t, ok)
+		assert.Equal(t, features.Size(), len(vector))
+		window := values[i-2 : i+1]
+		mean := (window[0] + window[1] + window[2]) / 3
+		std := math.Sqrt((math.Pow(window[0]-mean, 2) + math.Pow(window[1]-mean, 2) + math.Pow(window[2]-mean, 2)) / 3)
+		hour := float64(6+i) / 24
+		assert.InDeltaSlice(t, xmath2.Vec(12).With(
+			v, values[i-2],
+			mean, std, window[0], window[2],
+			v-values[i-1], (v-values[i-2])/values[i-2],
+			math.Sin(2*math.Pi*hour), math.Cos(2*math.Pi*hour), math.Sin(2*math.Pi/7), math.Cos(2*math.Pi/7),
+		), vector, 1e-12)
+	}
+
+}
+
+func TestFeatures_Transform(t *testing.T) {
+
+	features := NewFeatures().WithLags(0, 1).WithPctChange(1)
+
+	start := time.Now()
+	times := make([]time.Time, 5)
+	for i := range times {
+		times[i] = start.Add(time.Duration(i) * time.Minute)
+	}
+	values := xmath2.Vec(5).With(0, 1, 2, 3, 4)
+
+	m := features.Transform(times, values)
+	assert.Equal(t, xmath2.Mat(4).With(
+		xmath2.Vec(3).With(1, 0, 0),
+		xmath2.Vec(3).With(2, 1, 1),
+		xmath2.Vec(3).With(3, 2, 0.5),
+		xmath2.Vec(3).With(4, 3, 1.0/3),
+	), m)
+
+	// the streaming features match the batch ones
+	for i := range values {
+		if v, ok := features.Push(times[i], values[i]); ok {
+			assert.Equal(t, m[i-1], v)
+		}
+	}
+
+	assert.Equal(t, 0, len(features.Transform(times[:1], values[:1])))
+	assert.Panics(t, func() {
+		features.WithLags(-1)
+	})
+
+}
+
+func TestFeatures_PushBucket(t *testing.T) {
+
+	features := NewFeatures().
+		WithBucketValue(func(bucket *Bucket) float64 {
+			return bucket.Values().Stats()[0].Max()
+		}).
+		WithLags(0, 1)
+
+	window := NewTimeWindow(10 * time.Second)
+	start := time.Unix(1000, 0)
+
+	vectors := make([]xmath2.Vector, 0)
+	for i := 0; i < 40; i++ {
+		if ts, bucket, ok := window.Push(start.Add(time.Duration(i)*time.Second), float64(i)); ok {
+			if v, ok := features.PushBucket(ts, bucket); ok {
+				vectors = append(vectors, v)
+			}
+		}
+	}
+
+	// buckets close at every 10 seconds, with the max of each as value
+	assert.Equal(t, []xmath2.Vector{
+		xmath2.Vec(2).With(19, 9),
+		xmath2.Vec(2).With(29, 19),
+	}, vectors)
+
+}
@@ -0,0 +1,262 @@
+package buffer
+
+import (
+	"fmt"
+	"time"
+
+	xmath2 "github.com/drakos74/go-ex-machina/xmath"
+)
+
+// Aggregation is a summary of the values within an interval.
+type Aggregation int
+
+const (
+	// First is the first value within the interval e.g. the open.
+	First Aggregation = iota
+	// Last is the last value within the interval e.g. the close.
+	Last
+	// High is the largest value within the interval.
+	High
+	// Low is the smallest value within the interval.
+	Low
+	// Mean is the average of the values within the interval.
+	Mean
+	// Total is the sum of the values within the interval.
+	Total
+	// Count is the number of values within the interval.
+	Count
+)
+
+// OHLC are the open, high, low and close aggregations.
+var OHLC = []Aggregation{First, High, Low, Last}
+
+func (a Aggregation) String() string {
+	switch a {
+	case First:
+		return "first"
+	case Last:
+		return "last"
+	case High:
+		return "high"
+	case Low:
+		return "low"
+	case Mean:
+		return "mean"
+	case Total:
+		return "total"
+	case Count:
+		return "count"
+	}
+	return fmt.Sprintf("aggregation(%d)", int(a))
+}
+
+// value returns the aggregation of the stats.
+func (a Aggregation) value(stats *Stats) float64 {
+	switch a {
+	case First:
+		return stats.First()
+	case Last:
+		return stats.Last()
+	case High:
+		return stats.Max()
+	case Low:
+		return stats.Min()
+	case Mean:
+		return stats.Avg()
+	case Total:
+		return stats.Sum()
+	case Count:
+		return float64(stats.Count())
+	}
+	panic(fmt.Sprintf("unknown aggregation %v", a))
+}
+
+// Fill is the strategy for the intervals without any values.
+// The Count of an empty interval is always 0, regardless of the strategy.
+type Fill int
+
+const (
+	// ForwardFill repeats the values of the last interval.
+	ForwardFill Fill = iota
+	// ZeroFill sets all values to 0.
+	ZeroFill
+	// Interpolate draws a straight line between the intervals on either side of the gap.
+	Interpolate
+)
+
+func (f Fill) String() string {
+	switch f {
+	case ForwardFill:
+		return "forward"
+	case ZeroFill:
+		return "zero"
+	case Interpolate:
+		return "interpolate"
+	}
+	return fmt.Sprintf("fill(%d)", int(f))
+}
+
+// Sample is the aggregated value of an interval.
+type Sample struct {
+	// Time is the start of the interval.
+	Time time.Time
+	// Values are the aggregations of each dimension, one after the other.
+	Values xmath2.Vector
+	// Filled is true if the interval had no values, and they were filled in.
+	Filled bool
+}
+
+// Resampler turns irregular timestamped data into a regular series of fixed intervals,
+// e.g. to feed an rc.Network one Sample at a time.
+// The values of each interval are aggregated per dimension, and the empty intervals are filled in.
+// Values are expected to arrive in time order.
+type Resampler struct {
+	window       *TimeWindow
+	aggregations []Aggregation
+	fill         Fill
+	last         *Sample
+}
+
+// NewResampler creates a new resampler for the given interval, which must be a whole number of seconds.
+// By default it keeps the mean of each interval, and fills in empty intervals with the last values.
+func NewResampler(interval time.Duration) *Resampler {
+	if interval < time.Second || interval%time.Second != 0 {
+		panic(fmt.Sprintf("resample interval must be a whole number of seconds '%v'", interval))
+	}
+	return &Resampler{
+		window:       NewTimeWindow(interval),
+		aggregations: []Aggregation{Mean},
+		fill:         ForwardFill,
+	}
+}
+
+// WithAggregations defines the aggregations for each dimension, in the order they appear in the samples.
+func (r *Resampler) WithAggregations(aggregations ...Aggregation) *Resampler {
+	if len(aggregations) == 0 {
+		panic("resampler needs at least one aggregation")
+	}
+	r.aggregations = aggregations
+	return r
+}
+
+// WithFill defines the strategy for the intervals without any values.
+func (r *Resampler) WithFill(fill Fill) *Resampler {
+	r.fill = fill
+	return r
+}
+
+// Push adds a value at the given time.
+// It returns the samples of the intervals that closed with the push,
+// which are more than one if there were empty intervals in between.
+// As an interval only closes when a later one starts, the empty intervals are returned together with the one after them.
+func (r *Resampler) Push(t time.Time, v ...float64) []Sample {
+	start, bucket, ok := r.window.Push(t, v...)
+	if !ok {
+		return nil
+	}
+	sample := Sample{
+		Time:   start,
+		Values: r.aggregate(bucket),
+	}
+	if r.emitted(sample) {
+		return nil
+	}
+	samples := r.gap(sample)
+	samples = append(samples, sample)
+	r.last = &sample
+	return samples
+}
+
+// Current returns the sample of the interval that is still open, if it has any values.
+// It does not close the interval, so that later values within it are still taken into account.
+func (r *Resampler) Current() (Sample, bool) {
+	current := r.window.window.current
+	if current.Size() == 0 {
+		return Sample{}, false
+	}
+	return Sample{
+		Time:   time.Unix(current.index*r.window.duration, 0),
+		Values: r.aggregate(&current),
+	}, true
+}
+
+// Resample pushes all the values, and returns the regular series up to and including the last interval.
+// The values are rows corresponding to the times.
+// The last interval is returned while still open, so later values within it are not part of any series.
+func (r *Resampler) Resample(times []time.Time, values xmath2.Matrix) ([]time.Time, xmath2.Matrix) {
+	if len(times) != len(values) {
+		panic(fmt.Sprintf("times and values must have the same size '%v' vs '%v'", len(times), len(values)))
+	}
+	samples := make([]Sample, 0)
+	for i, t := range times {
+		samples = append(samples, r.Push(t, values[i]...)...)
+	}
+	if current, ok := r.Current(); ok && !r.emitted(current) {
+		samples = append(samples, r.gap(current)...)
+		samples = append(samples, current)
+		r.last = &current
+	}
+	series := make([]time.Time, len(samples))
+	m := xmath2.Mat(len(samples))
+	for i, s := range samples {
+		series[i] = s.Time
+		m[i] = s.Values
+	}
+	return series, m
+}
+
+// aggregate applies the aggregations on each dimension of the bucket.
+func (r *Resampler) aggregate(bucket *Bucket) xmath2.Vector {
+	stats := bucket.Values().Stats()
+	v := xmath2.Vec(len(stats) * len(r.aggregations))
+	for i, s := range stats {
+		for j, a := range r.aggregations {
+			v[i*len(r.aggregations)+j] = a.value(s)
+		}
+	}
+	return v
+}
+
+// emitted checks if the sample interval has already been returned e.g. by an earlier Resample.
+func (r *Resampler) emitted(sample Sample) bool {
+	return r.last != nil && !sample.Time.After(r.last.Time)
+}
+
+// gap fills in the empty intervals between the last sample and the next one.
+func (r *Resampler) gap(next Sample) []Sample {
+	if r.last == nil {
+		return nil
+	}
+	interval := time.Duration(r.window.duration) * time.Second
+	steps := int(next.Time.Sub(r.last.Time) / interval)
+	if steps < 2 {
+		return nil
+	}
+	if len(next.Values) != len(r.last.Values) {
+		panic(fmt.Sprintf("samples must have the same size '%v' vs '%v'", len(r.last.Values), len(next.Values)))
+	}
+	samples := make([]Sample, steps-1)
+	for k := range samples {
+		v := xmath2.Vec(len(next.Values))
+		for i := range v {
+			switch r.fill {
+			case ForwardFill:
+				v[i] = r.last.Values[i]
+			case ZeroFill:
+			case Interpolate:
+				v[i] = interpolate(r.last.Values[i], next.Values[i], float64(k+1)/float64(steps))
+			default:
+				panic(fmt.Sprintf("unknown fill strategy %v", r.fill))
+			}
+			if r.aggregations[i%len(r.aggregations)] == Count {
+				v[i] = 0
+			}
+		}
+		samples[k] = Sample{
+			Time:   r.last.Time.Add(time.Duration(k+1) * interval),
+			Values: v,
+			Filled: true,
+		}
+	}
+	return samples
+}
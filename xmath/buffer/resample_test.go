@@ -0,0 +1,177 @@
+package buffer
+
+import (
+	"testing"
+	"time"
+
+	xmath2 "github.com/drakos74/go-ex-machina/xmath"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResampler_Aggregations(t *testing.T) {
+
+	start := time.Unix(6000, 0)
+
+	r := NewResampler(time.Minute).WithAggregations(append(OHLC, Count, Total, Mean)...)
+
+	assert.Nil(t, r.Push(start.Add(5*time.Second), 3, 10))
+	assert.Nil(t, r.Push(start.Add(20*time.Second), 5, 20))
+	assert.Nil(t, r.Push(start.Add(40*time.Second), 1, 30))
+	assert.Nil(t, r.Push(start.Add(50*time.Second), 2, 40))
+
+	current, ok := r.Current()
+	assert.True(t, ok)
+	assert.Equal(t, start, current.Time)
+
+	samples := r.Push(start.Add(65*time.Second), 7, 50)
+	assert.Equal(t, []Sample{
+		{
+			Time: start,
+			Values: xmath2.Vec(14).With(
+				3, 5, 1, 2, 4, 11, 2.75,
+				10, 40, 10, 40, 4, 100, 25,
+			),
+		},
+	}, samples)
+	assert.Equal(t, current, samples[0])
+
+	current, ok = r.Current()
+	assert.True(t, ok)
+	assert.Equal(t, start.Add(time.Minute), current.Time)
+	assert.Equal(t, xmath2.Vec(14).With(
+		7, 7, 7, 7, 1, 7, 7,
+		50, 50, 50, 50, 1, 50, 50,
+	), current.Values)
+
+}
+
+func TestResampler_Fill(t *testing.T) {
+
+	start := time.Unix(6000, 0)
+
+	type test struct {
+		fill   Fill
+		values xmath2.Vector
+	}
+
+	tests := map[string]test{
+		"forward": {
+			fill:   ForwardFill,
+			values: xmath2.Vec(5).With(2, 2, 2, 2, 10),
+		},
+		"zero": {
+			fill:   ZeroFill,
+			values: xmath2.Vec(5).With(2, 0, 0, 0, 10),
+		},
+		"interpolate": {
+			fill:   Interpolate,
+			values: xmath2.Vec(5).With(2, 4, 6, 8, 10),
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := NewResampler(10*time.Second).
+				WithAggregations(Last, Count).
+				WithFill(tt.fill)
+			times, series := r.Resample(
+				[]time.Time{start, start.Add(5 * time.Second), start.Add(42 * time.Second)},
+				xmath2.Mat(3).With(
+					xmath2.Vec(1).With(1),
+					xmath2.Vec(1).With(2),
+					xmath2.Vec(1).With(10),
+				),
+			)
+			assert.Equal(t, 5, len(times))
+			for i, ti := range times {
+				assert.Equal(t, start.Add(time.Duration(i)*10*time.Second), ti)
+				assert.Equal(t, tt.values[i], series[i][0])
+			}
+			// the empty intervals have no elements, regardless of the fill
+			assert.Equal(t, xmath2.Vec(5).With(2, 0, 0, 0, 1), series.T()[1])
+		})
+	}
+
+}
+
+func TestResampler_Stream(t *testing.T) {
+
+	start := time.Unix(6000, 0)
+
+	r := NewResampler(time.Second).WithFill(Interpolate)
+
+	var samples []Sample
+	for _, i := range []int{0, 1, 4, 5, 9} {
+		samples = append(samples, r.Push(start.Add(time.Duration(i)*time.Second), float64(i))...)
+	}
+
+	// the gap before the last interval is filled once that interval closes
+	assert.Equal(t, 6, len(samples))
+	samples = append(samples, r.Push(start.Add(10*time.Second), 10)...)
+	assert.Equal(t, 10, len(samples))
+	for i, s := range samples {
+		assert.Equal(t, start.Add(time.Duration(i)*time.Second), s.Time)
+		assert.InDelta(t, float64(i), s.Values[0], 1e-9)
+		assert.Equal(t, i > 5 && i < 9 || i == 2 || i == 3, s.Filled)
+	}
+
+}
+
+func TestResampler_InvalidInterval(t *testing.T) {
+	assert.Panics(t, func() {
+		NewResampler(500 * time.Millisecond)
+	})
+	assert.Panics(t, func() {
+		NewResampler(1500 * time.Millisecond)
+	})
+}
+
+func TestResampler_ResampleTwice(t *testing.T) {
+
+	start := time.Unix(6000, 0)
+	at := func(seconds ...int) []time.Time {
+		times := make([]time.Time, len(seconds))
+		for i, s := range seconds {
+			times[i] = start.Add(time.Duration(s) * time.Second)
+		}
+		return times
+	}
+
+	r := NewResampler(10 * time.Second)
+
+	times, values := r.Resample(at(0, 5, 12), xmath2.Mat(3).With(
+		xmath2.Vec(1).With(0),
+		xmath2.Vec(1).With(5),
+		xmath2.Vec(1).With(12),
+	))
+	assert.Equal(t, at(0, 10), times)
+	assert.Equal(t, xmath2.Mat(2).With(
+		xmath2.Vec(1).With(2.5),
+		xmath2.Vec(1).With(12),
+	), values)
+
+	// the open interval at 10 was already returned, so the series continues after it
+	times, values = r.Resample(at(15, 45), xmath2.Mat(2).With(
+		xmath2.Vec(1).With(15),
+		xmath2.Vec(1).With(45),
+	))
+	assert.Equal(t, at(20, 30, 40), times)
+	assert.Equal(t, xmath2.Mat(3).With(
+		xmath2.Vec(1).With(12),
+		xmath2.Vec(1).With(12),
+		xmath2.Vec(1).With(45),
+	), values)
+
+	// without new intervals there is nothing to return
+	times, values = r.Resample(at(47), xmath2.Mat(1).With(xmath2.Vec(1).With(47)))
+	assert.Equal(t, 0, len(times))
+	assert.Equal(t, 0, len(values))
+
+	// the stream continues after the last returned interval
+	samples := r.Push(start.Add(50*time.Second), 50)
+	assert.Equal(t, 0, len(samples))
+	samples = r.Push(start.Add(60*time.Second), 60)
+	assert.Equal(t, 1, len(samples))
+	assert.Equal(t, start.Add(50*time.Second), samples[0].Time)
+
+}
@@ -0,0 +1,693 @@
+package buffer
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+
+	xmath2 "github.com/drakos74/go-ex-machina/xmath"
+)
+
+// The state types capture everything needed to resume pushing to the buffers exactly where they left off,
+// e.g. after a restart of a streaming service.
+// Each buffer can be marshalled to json, or to a compact binary form.
+
+type ewmaState struct {
+	Alpha float64 `json:"alpha"`
+	Decay float64 `json:"decay"`
+	M1    float64 `json:"m1"`
+	M2    float64 `json:"m2"`
+}
+
+func (e *ewma) state() *ewmaState {
+	if e == nil {
+		return nil
+	}
+	return &ewmaState{
+		Alpha: e.alpha,
+		Decay: e.decay,
+		M1:    e.m1,
+		M2:    e.m2,
+	}
+}
+
+func (s *ewmaState) restore() *ewma {
+	if s == nil {
+		return nil
+	}
+	e := newEWMA(s.Alpha)
+	e.decay = s.Decay
+	e.m1 = s.M1
+	e.m2 = s.M2
+	return e
+}
+
+type centroidState struct {
+	Mean   float64 `json:"mean"`
+	Weight float64 `json:"weight"`
+}
+
+func centroidStates(centroids []centroid) []centroidState {
+	states := make([]centroidState, len(centroids))
+	for i, c := range centroids {
+		states[i] = centroidState{
+			Mean:   c.mean,
+			Weight: c.weight,
+		}
+	}
+	return states
+}
+
+func centroidsOf(states []centroidState) []centroid {
+	centroids := make([]centroid, len(states))
+	for i, s := range states {
+		centroids[i] = centroid{
+			mean:   s.Mean,
+			weight: s.Weight,
+		}
+	}
+	return centroids
+}
+
+type digestState struct {
+	Compression float64         `json:"compression"`
+	Centroids   []centroidState `json:"centroids"`
+	Pending     []centroidState `json:"pending"`
+	Count       float64         `json:"count"`
+	Min         float64         `json:"min"`
+	Max         float64         `json:"max"`
+}
+
+func (d *Digest) state() *digestState {
+	if d == nil {
+		return nil
+	}
+	return &digestState{
+		Compression: d.compression,
+		Centroids:   centroidStates(d.centroids),
+		Pending:     centroidStates(d.pending),
+		Count:       d.count,
+		Min:         d.min,
+		Max:         d.max,
+	}
+}
+
+func (s *digestState) restore() *Digest {
+	if s == nil {
+		return nil
+	}
+	d := NewDigest(s.Compression)
+	d.centroids = centroidsOf(s.Centroids)
+	d.pending = centroidsOf(s.Pending)
+	d.count = s.Count
+	d.min = s.Min
+	d.max = s.Max
+	return d
+}
+
+// MarshalJSON serializes the full state of the digest.
+func (d Digest) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.state())
+}
+
+// UnmarshalJSON restores the digest state.
+func (d *Digest) UnmarshalJSON(b []byte) error {
+	var s digestState
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	*d = *s.restore()
+	return nil
+}
+
+// MarshalBinary serializes the full state of the digest in binary form.
+func (d Digest) MarshalBinary() ([]byte, error) {
+	return marshalBinary(d.state())
+}
+
+// UnmarshalBinary restores the digest state from its binary form.
+func (d *Digest) UnmarshalBinary(b []byte) error {
+	var s digestState
+	if err := unmarshalBinary(b, &s); err != nil {
+		return err
+	}
+	*d = *s.restore()
+	return nil
+}
+
+type statsState struct {
+	Count  int          `json:"count"`
+	Sum    float64      `json:"sum"`
+	First  float64      `json:"first"`
+	Last   float64      `json:"last"`
+	Min    float64      `json:"min"`
+	Max    float64      `json:"max"`
+	Mean   float64      `json:"mean"`
+	M2     float64      `json:"m2"`
+	M3     float64      `json:"m3"`
+	M4     float64      `json:"m4"`
+	EWMA   *ewmaState   `json:"ewma,omitempty"`
+	Digest *digestState `json:"digest,omitempty"`
+}
+
+func (s Stats) state() statsState {
+	return statsState{
+		Count:  s.count,
+		Sum:    s.sum,
+		First:  s.first,
+		Last:   s.last,
+		Min:    s.min,
+		Max:    s.max,
+		Mean:   s.mean,
+		M2:     s.m2,
+		M3:     s.m3,
+		M4:     s.m4,
+		EWMA:   s.ewma.state(),
+		Digest: s.digest.state(),
+	}
+}
+
+func (s statsState) restore() *Stats {
+	return &Stats{
+		count:  s.Count,
+		sum:    s.Sum,
+		first:  s.First,
+		last:   s.Last,
+		min:    s.Min,
+		max:    s.Max,
+		mean:   s.Mean,
+		m2:     s.M2,
+		m3:     s.M3,
+		m4:     s.M4,
+		ewma:   s.EWMA.restore(),
+		digest: s.Digest.restore(),
+	}
+}
+
+// MarshalJSON serializes the full state of the stats, including the optional ewma and quantiles.
+func (s Stats) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.state())
+}
+
+// UnmarshalJSON restores the stats state, so that pushing can resume where it left off.
+func (s *Stats) UnmarshalJSON(b []byte) error {
+	var state statsState
+	if err := json.Unmarshal(b, &state); err != nil {
+		return err
+	}
+	*s = *state.restore()
+	return nil
+}
+
+// MarshalBinary serializes the full state of the stats in binary form.
+func (s Stats) MarshalBinary() ([]byte, error) {
+	return marshalBinary(s.state())
+}
+
+// UnmarshalBinary restores the stats state from its binary form.
+func (s *Stats) UnmarshalBinary(b []byte) error {
+	var state statsState
+	if err := unmarshalBinary(b, &state); err != nil {
+		return err
+	}
+	*s = *state.restore()
+	return nil
+}
+
+type collectorState struct {
+	Stats     []statsState  `json:"stats"`
+	Comoments xmath2.Matrix `json:"comoments,omitempty"`
+}
+
+func (sc StatsCollector) state() collectorState {
+	stats := make([]statsState, len(sc.stats))
+	for i, s := range sc.stats {
+		stats[i] = s.state()
+	}
+	return collectorState{
+		Stats:     stats,
+		Comoments: sc.comoments,
+	}
+}
+
+func (s collectorState) restore() (*StatsCollector, error) {
+	dim := len(s.Stats)
+	sc := NewStatsCollector(dim)
+	for i := range s.Stats {
+		sc.stats[i] = s.Stats[i].restore()
+	}
+	// the comoments are only there if the covariance is enabled
+	if s.Comoments == nil {
+		return sc, nil
+	}
+	if len(s.Comoments) != dim {
+		return nil, fmt.Errorf("could not restore comoments of size %d for %d dimensions", len(s.Comoments), dim)
+	}
+	sc.WithCovariance()
+	for i := range s.Comoments {
+		if len(s.Comoments[i]) != dim {
+			return nil, fmt.Errorf("could not restore comoments of size %d for %d dimensions", len(s.Comoments[i]), dim)
+		}
+		copy(sc.comoments[i], s.Comoments[i])
+	}
+	return sc, nil
+}
+
+// MarshalJSON serializes the full state of the collector, including the co-variance across the dimensions if enabled.
+func (sc StatsCollector) MarshalJSON() ([]byte, error) {
+	return json.Marshal(sc.state())
+}
+
+// UnmarshalJSON restores the collector state, so that pushing can resume where it left off.
+func (sc *StatsCollector) UnmarshalJSON(b []byte) error {
+	var state collectorState
+	if err := json.Unmarshal(b, &state); err != nil {
+		return err
+	}
+	return sc.restore(state)
+}
+
+// MarshalBinary serializes the full state of the collector in binary form.
+func (sc StatsCollector) MarshalBinary() ([]byte, error) {
+	return marshalBinary(sc.state())
+}
+
+// UnmarshalBinary restores the collector state from its binary form.
+func (sc *StatsCollector) UnmarshalBinary(b []byte) error {
+	var state collectorState
+	if err := unmarshalBinary(b, &state); err != nil {
+		return err
+	}
+	return sc.restore(state)
+}
+
+func (sc *StatsCollector) restore(state collectorState) error {
+	restored, err := state.restore()
+	if err != nil {
+		return err
+	}
+	*sc = *restored
+	return nil
+}
+
+type bucketState struct {
+	Index int64          `json:"index"`
+	Stats collectorState `json:"stats"`
+}
+
+func (b Bucket) state() *bucketState {
+	return &bucketState{
+		Index: b.index,
+		Stats: b.stats.state(),
+	}
+}
+
+func (s *bucketState) restore() (*Bucket, error) {
+	stats, err := s.Stats.restore()
+	if err != nil {
+		return nil, fmt.Errorf("could not restore bucket %d: %w", s.Index, err)
+	}
+	return &Bucket{
+		stats: stats,
+		index: s.Index,
+	}, nil
+}
+
+// MarshalJSON serializes the full state of the bucket.
+func (b Bucket) MarshalJSON() ([]byte, error) {
+	return json.Marshal(b.state())
+}
+
+// UnmarshalJSON restores the bucket state.
+func (b *Bucket) UnmarshalJSON(data []byte) error {
+	var state bucketState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+	return b.restore(&state)
+}
+
+// MarshalBinary serializes the full state of the bucket in binary form.
+func (b Bucket) MarshalBinary() ([]byte, error) {
+	return marshalBinary(b.state())
+}
+
+// UnmarshalBinary restores the bucket state from its binary form.
+func (b *Bucket) UnmarshalBinary(data []byte) error {
+	var state bucketState
+	if err := unmarshalBinary(data, &state); err != nil {
+		return err
+	}
+	return b.restore(&state)
+}
+
+func (b *Bucket) restore(state *bucketState) error {
+	restored, err := state.restore()
+	if err != nil {
+		return err
+	}
+	*b = *restored
+	return nil
+}
+
+type windowState struct {
+	Size      int64        `json:"size"`
+	LastIndex int64        `json:"last_index"`
+	Last      *bucketState `json:"last,omitempty"`
+	Current   bucketState  `json:"current"`
+}
+
+func (w *Window) state() *windowState {
+	state := &windowState{
+		Size:      w.size,
+		LastIndex: w.lastIndex,
+		Current:   *w.current.state(),
+	}
+	if w.last != nil {
+		state.Last = w.last.state()
+	}
+	return state
+}
+
+func (s *windowState) restore() (*Window, error) {
+	current, err := s.Current.restore()
+	if err != nil {
+		return nil, fmt.Errorf("could not restore current bucket: %w", err)
+	}
+	w := &Window{
+		size:      s.Size,
+		lastIndex: s.LastIndex,
+		current:   *current,
+	}
+	if s.Last != nil {
+		last, err := s.Last.restore()
+		if err != nil {
+			return nil, fmt.Errorf("could not restore last bucket: %w", err)
+		}
+		w.last = last
+	}
+	return w, nil
+}
+
+// MarshalJSON serializes the full state of the window, including any closed bucket that has not been consumed.
+func (w *Window) MarshalJSON() ([]byte, error) {
+	return json.Marshal(w.state())
+}
+
+// UnmarshalJSON restores the window state, so that pushing can resume where it left off.
+func (w *Window) UnmarshalJSON(b []byte) error {
+	var state windowState
+	if err := json.Unmarshal(b, &state); err != nil {
+		return err
+	}
+	return w.restore(&state)
+}
+
+// MarshalBinary serializes the full state of the window in binary form.
+func (w *Window) MarshalBinary() ([]byte, error) {
+	return marshalBinary(w.state())
+}
+
+// UnmarshalBinary restores the window state from its binary form.
+func (w *Window) UnmarshalBinary(b []byte) error {
+	var state windowState
+	if err := unmarshalBinary(b, &state); err != nil {
+		return err
+	}
+	return w.restore(&state)
+}
+
+func (w *Window) restore(state *windowState) error {
+	restored, err := state.restore()
+	if err != nil {
+		return err
+	}
+	*w = *restored
+	return nil
+}
+
+type timeWindowState struct {
+	Index    int64        `json:"index"`
+	Duration int64        `json:"duration"`
+	Window   *windowState `json:"window"`
+}
+
+func (tw *TimeWindow) state() *timeWindowState {
+	return &timeWindowState{
+		Index:    tw.index,
+		Duration: tw.duration,
+		Window:   tw.window.state(),
+	}
+}
+
+func (s *timeWindowState) restore() (*TimeWindow, error) {
+	if s.Duration <= 0 {
+		return nil, fmt.Errorf("could not restore time window of duration %d", s.Duration)
+	}
+	if s.Window == nil {
+		return nil, fmt.Errorf("could not restore time window without window")
+	}
+	window, err := s.Window.restore()
+	if err != nil {
+		return nil, err
+	}
+	return &TimeWindow{
+		index:    s.Index,
+		duration: s.Duration,
+		window:   window,
+	}, nil
+}
+
+// MarshalJSON serializes the full state of the time window.
+func (tw *TimeWindow) MarshalJSON() ([]byte, error) {
+	return json.Marshal(tw.state())
+}
+
+// UnmarshalJSON restores the time window state, so that pushing can resume where it left off.
+func (tw *TimeWindow) UnmarshalJSON(b []byte) error {
+	var state timeWindowState
+	if err := json.Unmarshal(b, &state); err != nil {
+		return err
+	}
+	return tw.restore(&state)
+}
+
+// MarshalBinary serializes the full state of the time window in binary form.
+func (tw *TimeWindow) MarshalBinary() ([]byte, error) {
+	return marshalBinary(tw.state())
+}
+
+// UnmarshalBinary restores the time window state from its binary form.
+func (tw *TimeWindow) UnmarshalBinary(b []byte) error {
+	var state timeWindowState
+	if err := unmarshalBinary(b, &state); err != nil {
+		return err
+	}
+	return tw.restore(&state)
+}
+
+func (tw *TimeWindow) restore(state *timeWindowState) error {
+	restored, err := state.restore()
+	if err != nil {
+		return err
+	}
+	*tw = *restored
+	return nil
+}
+
+// bucketRingState keeps only the buckets pushed so far,
+// as the ring is filled from the start until it wraps around.
+type bucketRingState struct {
+	Size    int            `json:"size"`
+	Index   int            `json:"index"`
+	Count   int            `json:"count"`
+	Buckets []*bucketState `json:"buckets"`
+}
+
+func (r *BucketRing) state() *bucketRingState {
+	l := len(r.values)
+	if r.count < l {
+		l = r.count
+	}
+	buckets := make([]*bucketState, l)
+	for i := range buckets {
+		buckets[i] = r.values[i].state()
+	}
+	return &bucketRingState{
+		Size:    len(r.values),
+		Index:   r.index,
+		Count:   r.count,
+		Buckets: buckets,
+	}
+}
+
+func (s *bucketRingState) restore() (*BucketRing, error) {
+	if s.Size <= 0 || len(s.Buckets) > s.Size {
+		return nil, fmt.Errorf("could not restore %d buckets in ring of size %d", len(s.Buckets), s.Size)
+	}
+	r := NewBucketRing(s.Size)
+	r.index = s.Index
+	r.count = s.Count
+	for i, b := range s.Buckets {
+		bucket, err := b.restore()
+		if err != nil {
+			return nil, err
+		}
+		r.values[i] = bucket
+	}
+	return r, nil
+}
+
+type historyWindowState struct {
+	Window  *timeWindowState `json:"window"`
+	Buckets *bucketRingState `json:"buckets"`
+}
+
+func (h *HistoryWindow) state() *historyWindowState {
+	return &historyWindowState{
+		Window:  h.window.state(),
+		Buckets: h.buckets.state(),
+	}
+}
+
+func (s *historyWindowState) restore() (*HistoryWindow, error) {
+	if s.Window == nil || s.Buckets == nil {
+		return nil, fmt.Errorf("could not restore history window without window and buckets")
+	}
+	window, err := s.Window.restore()
+	if err != nil {
+		return nil, err
+	}
+	buckets, err := s.Buckets.restore()
+	if err != nil {
+		return nil, fmt.Errorf("could not restore history: %w", err)
+	}
+	return &HistoryWindow{
+		window:  window,
+		buckets: buckets,
+	}, nil
+}
+
+// MarshalJSON serializes the full state of the history window, including the buckets in its history.
+func (h *HistoryWindow) MarshalJSON() ([]byte, error) {
+	return json.Marshal(h.state())
+}
+
+// UnmarshalJSON restores the history window state, so that pushing can resume where it left off.
+func (h *HistoryWindow) UnmarshalJSON(b []byte) error {
+	var state historyWindowState
+	if err := json.Unmarshal(b, &state); err != nil {
+		return err
+	}
+	return h.restore(&state)
+}
+
+// MarshalBinary serializes the full state of the history window in binary form.
+func (h *HistoryWindow) MarshalBinary() ([]byte, error) {
+	return marshalBinary(h.state())
+}
+
+// UnmarshalBinary restores the history window state from its binary form.
+func (h *HistoryWindow) UnmarshalBinary(b []byte) error {
+	var state historyWindowState
+	if err := unmarshalBinary(b, &state); err != nil {
+		return err
+	}
+	return h.restore(&state)
+}
+
+func (h *HistoryWindow) restore(state *historyWindowState) error {
+	restored, err := state.restore()
+	if err != nil {
+		return err
+	}
+	*h = *restored
+	return nil
+}
+
+// vectorRingState keeps only the vectors pushed so far,
+// as the ring is filled from the start until it wraps around.
+type vectorRingState struct {
+	Size    int           `json:"size"`
+	Index   int           `json:"index"`
+	Vectors xmath2.Matrix `json:"vectors"`
+}
+
+func (w *VectorRing) state() *vectorRingState {
+	l := len(w.mem)
+	if w.idx < l {
+		l = w.idx
+	}
+	return &vectorRingState{
+		Size:    len(w.mem),
+		Index:   w.idx,
+		Vectors: w.mem[:l],
+	}
+}
+
+func (s *vectorRingState) restore() (*VectorRing, error) {
+	if s.Size <= 0 || len(s.Vectors) > s.Size {
+		return nil, fmt.Errorf("could not restore %d vectors in ring of size %d", len(s.Vectors), s.Size)
+	}
+	w := NewVectorRing(s.Size)
+	w.idx = s.Index
+	for i, v := range s.Vectors {
+		w.mem[i] = xmath2.Vec(len(v)).With(v...)
+	}
+	return w, nil
+}
+
+// MarshalJSON serializes the full state of the ring, including the vectors of the current batch.
+func (w *VectorRing) MarshalJSON() ([]byte, error) {
+	return json.Marshal(w.state())
+}
+
+// UnmarshalJSON restores the ring state, so that pushing can resume where it left off.
+func (w *VectorRing) UnmarshalJSON(b []byte) error {
+	var state vectorRingState
+	if err := json.Unmarshal(b, &state); err != nil {
+		return err
+	}
+	return w.restore(&state)
+}
+
+// MarshalBinary serializes the full state of the ring in binary form.
+func (w *VectorRing) MarshalBinary() ([]byte, error) {
+	return marshalBinary(w.state())
+}
+
+// UnmarshalBinary restores the ring state from its binary form.
+func (w *VectorRing) UnmarshalBinary(b []byte) error {
+	var state vectorRingState
+	if err := unmarshalBinary(b, &state); err != nil {
+		return err
+	}
+	return w.restore(&state)
+}
+
+func (w *VectorRing) restore(state *vectorRingState) error {
+	restored, err := state.restore()
+	if err != nil {
+		return err
+	}
+	*w = *restored
+	return nil
+}
+
+// marshalBinary encodes the state with gob, which keeps the floats exact and omits the field names of repeated values.
+func marshalBinary(state interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(state); err != nil {
+		return nil, fmt.Errorf("could not encode state: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// unmarshalBinary decodes the state encoded with marshalBinary.
+func unmarshalBinary(b []byte, state interface{}) error {
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(state); err != nil {
+		return fmt.Errorf("could not decode state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,318 @@
+package buffer
+
+import (
+	"encoding"
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	xmath2 "github.com/drakos74/go-ex-machina/xmath"
+	"github.com/stretchr/testify/assert"
+)
+
+type codec struct {
+	marshal   func(v interface{}) ([]byte, error)
+	unmarshal func(b []byte, v interface{}) error
+}
+
+var codecs = map[string]codec{
+	"json": {
+		marshal:   json.Marshal,
+		unmarshal: json.Unmarshal,
+	},
+	"binary": {
+		marshal: func(v interface{}) ([]byte, error) {
+			return v.(encoding.BinaryMarshaler).MarshalBinary()
+		},
+		unmarshal: func(b []byte, v interface{}) error {
+			return v.(encoding.BinaryUnmarshaler).UnmarshalBinary(b)
+		},
+	},
+}
+
+// roundTrip marshals the source and unmarshals it into the target.
+func roundTrip(t *testing.T, c codec, source, target interface{}) {
+	b, err := c.marshal(source)
+	assert.NoError(t, err)
+	err = c.unmarshal(b, target)
+	assert.NoError(t, err)
+}
+
+// signal is a deterministic series with some variation.
+func signal(i int) float64 {
+	return math.Sin(float64(i)/5) + float64(i%7)/10
+}
+
+func TestStats_State(t *testing.T) {
+
+	type test struct {
+		stats func() *Stats
+	}
+
+	tests := map[string]test{
+		"plain": {
+			stats: NewStats,
+		},
+		"ewma": {
+			stats: func() *Stats {
+				return NewStats().WithEWMA(0.1)
+			},
+		},
+		"quantiles": {
+			stats: func() *Stats {
+				return NewStats().WithEWMA(0.1).WithQuantiles(10)
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		for codecName, c := range codecs {
+			t.Run(name+"-"+codecName, func(t *testing.T) {
+				stats := tt.stats()
+				for i := 0; i < 100; i++ {
+					stats.Push(signal(i))
+				}
+				restored := NewStats()
+				roundTrip(t, c, stats, restored)
+				assert.Equal(t, stats, restored)
+				for i := 100; i < 200; i++ {
+					stats.Push(signal(i))
+					restored.Push(signal(i))
+				}
+				assert.Equal(t, stats, restored)
+				if stats.digest != nil {
+					assert.Equal(t, stats.Median(), restored.Median())
+				}
+			})
+		}
+	}
+
+}
+
+func TestStatsCollector_State(t *testing.T) {
+
+	collectors := map[string]func() *StatsCollector{
+		"scalar": func() *StatsCollector {
+			return NewStatsCollector(3).WithEWMA(0.2)
+		},
+		"covariance": func() *StatsCollector {
+			return NewStatsCollector(3).WithEWMA(0.2).WithCovariance()
+		},
+	}
+
+	for name, c := range codecs {
+		for config, newCollector := range collectors {
+			t.Run(name+"-"+config, func(t *testing.T) {
+				collector := newCollector()
+				for i := 0; i < 50; i++ {
+					collector.Push(signal(i), signal(2*i), float64(i))
+				}
+				restored := NewStatsCollector(0)
+				roundTrip(t, c, collector, restored)
+				for i := 50; i < 100; i++ {
+					collector.Push(signal(i), signal(2*i), float64(i))
+					restored.Push(signal(i), signal(2*i), float64(i))
+				}
+				assert.Equal(t, collector, restored)
+				assert.Equal(t, collector.Covariance(), restored.Covariance())
+			})
+		}
+	}
+
+}
+
+func TestStatsCollector_InvalidState(t *testing.T) {
+
+	for name, c := range codecs {
+		t.Run(name, func(t *testing.T) {
+			collector := NewStatsCollector(2).WithCovariance()
+			collector.Push(1, 2)
+			collector.comoments = collector.comoments[:1]
+			b, err := c.marshal(collector)
+			assert.NoError(t, err)
+			err = c.unmarshal(b, NewStatsCollector(0))
+			assert.Error(t, err)
+		})
+	}
+
+}
+
+func TestWindow_State(t *testing.T) {
+
+	for name, c := range codecs {
+		t.Run(name, func(t *testing.T) {
+			window := NewWindow(5)
+			restored := &Window{}
+			var buckets, restoredBuckets []Bucket
+			for i := 1; i < 100; i++ {
+				if _, ok := window.Push(int64(i), signal(i), float64(i)); ok {
+					buckets = append(buckets, window.Get())
+				}
+				if i == 42 {
+					roundTrip(t, c, window, restored)
+				}
+				if i > 42 {
+					if _, ok := restored.Push(int64(i), signal(i), float64(i)); ok {
+						restoredBuckets = append(restoredBuckets, restored.Get())
+					}
+				}
+			}
+			assert.Equal(t, window.Current(), restored.Current())
+			assert.Equal(t, window.Next(), restored.Next())
+			// the buckets closed after the restore are the same
+			assert.Equal(t, 11, len(restoredBuckets))
+			closed := buckets[len(buckets)-len(restoredBuckets):]
+			for i := range restoredBuckets {
+				assert.Equal(t, closed[i].Index(), restoredBuckets[i].Index())
+				assert.Equal(t, closed[i].Values().Stats(), restoredBuckets[i].Values().Stats())
+				assert.Equal(t, closed[i].Values().Covariance(), restoredBuckets[i].Values().Covariance())
+			}
+		})
+	}
+
+}
+
+func TestWindow_StateUnconsumed(t *testing.T) {
+
+	for name, c := range codecs {
+		t.Run(name, func(t *testing.T) {
+			window := NewWindow(5)
+			for i := 1; i < 7; i++ {
+				window.Push(int64(i), float64(i))
+			}
+			restored := &Window{}
+			roundTrip(t, c, window, restored)
+			// the closed bucket can still be consumed after the restore
+			bucket := restored.Get()
+			assert.Equal(t, window.Get().Values().Stats(), bucket.Values().Stats())
+			assert.Equal(t, 4, bucket.Size())
+		})
+	}
+
+}
+
+func TestBucket_State(t *testing.T) {
+
+	for name, c := range codecs {
+		t.Run(name, func(t *testing.T) {
+			bucket := NewBucket(7, 2)
+			bucket.Push(7, 1, 2)
+			bucket.Push(7, 3, 5)
+			restored := Bucket{}
+			roundTrip(t, c, bucket, &restored)
+			assert.Equal(t, bucket.Index(), restored.Index())
+			assert.True(t, restored.Push(7, 5, 8))
+			assert.False(t, restored.Push(8, 5, 8))
+			assert.Equal(t, 3, restored.Size())
+			assert.Equal(t, 3.0, restored.Values().Stats()[0].Avg())
+			assert.Equal(t, 5.0, restored.Values().Stats()[1].Avg())
+		})
+	}
+
+}
+
+func TestHistoryWindow_State(t *testing.T) {
+
+	avg := func(bucket *Bucket) interface{} {
+		return bucket.Values().Stats()[0].Avg()
+	}
+
+	type test struct {
+		at int
+	}
+
+	tests := map[string]test{
+		"filling": {
+			at: 10,
+		},
+		"full": {
+			at: 50,
+		},
+	}
+
+	for name, tt := range tests {
+		for codecName, c := range codecs {
+			t.Run(name+"-"+codecName, func(t *testing.T) {
+				window := NewHistoryWindow(time.Minute, 5)
+				restored := &HistoryWindow{}
+				start := time.Unix(6000, 0)
+				for i := 0; i < 100; i++ {
+					ts := start.Add(time.Duration(i) * 20 * time.Second)
+					ti, bucket, ok := window.Push(ts, signal(i))
+					if i == tt.at {
+						roundTrip(t, c, window, restored)
+						assert.Equal(t, window.Get(avg), restored.Get(avg))
+					}
+					if i > tt.at {
+						rti, restoredBucket, rok := restored.Push(ts, signal(i))
+						assert.Equal(t, ok, rok)
+						assert.Equal(t, ti, rti)
+						if ok {
+							assert.Equal(t, bucket.Values().Stats(), restoredBucket.Values().Stats())
+						}
+					}
+				}
+				assert.Equal(t, window.Get(avg), restored.Get(avg))
+				assert.Equal(t, window.window.Next(1), restored.window.Next(1))
+			})
+		}
+	}
+
+}
+
+func TestTimeWindow_InvalidState(t *testing.T) {
+
+	for name, c := range codecs {
+		t.Run(name, func(t *testing.T) {
+			window := NewTimeWindow(time.Minute)
+			window.duration = 0
+			b, err := c.marshal(window)
+			assert.NoError(t, err)
+			err = c.unmarshal(b, &TimeWindow{})
+			assert.Error(t, err)
+		})
+	}
+
+}
+
+func TestVectorRing_State(t *testing.T) {
+
+	type test struct {
+		at int
+	}
+
+	tests := map[string]test{
+		"empty": {
+			at: 0,
+		},
+		"filling": {
+			at: 2,
+		},
+		"full": {
+			at: 11,
+		},
+	}
+
+	for name, tt := range tests {
+		for codecName, c := range codecs {
+			t.Run(name+"-"+codecName, func(t *testing.T) {
+				ring := NewSplitVectorRing(3)
+				for i := 0; i < tt.at; i++ {
+					ring.Push(xmath2.Vec(2).With(float64(i), signal(i)))
+				}
+				restored := &VectorRing{}
+				roundTrip(t, c, ring, restored)
+				assert.Equal(t, ring.Size(), restored.Size())
+				assert.Equal(t, ring.batch(), restored.batch())
+				for i := tt.at; i < 20; i++ {
+					batch, ok := ring.Push(xmath2.Vec(2).With(float64(i), signal(i)))
+					restoredBatch, rok := restored.Push(xmath2.Vec(2).With(float64(i), signal(i)))
+					assert.Equal(t, ok, rok)
+					assert.Equal(t, batch, restoredBatch)
+				}
+			})
+		}
+	}
+
+}
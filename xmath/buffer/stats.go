@@ -3,33 +3,61 @@ package buffer
 import (
 	"fmt"
 	"math"
+
+	xmath2 "github.com/drakos74/go-ex-machina/xmath"
 )
 
 // Stats is a set of statistical properties of a set of numbers.
+// Two Stats can be merged, so that separate streams e.g. from different goroutines can be combined.
 type Stats struct {
-	count          int
-	sum            float64
-	first, last    float64
-	min, max       float64
-	mean, dSquared float64
+	count       int
+	sum         float64
+	first, last float64
+	min, max    float64
+	// mean and the sums of the powers of differences from the mean
+	mean, m2, m3, m4 float64
+	// ewma is the exponentially weighted average of the set, if enabled
+	ewma *ewma
+	// digest estimates the quantiles of the set, if enabled
+	digest *Digest
 }
 
 // NewStats creates a new Stats.
 func NewStats() *Stats {
 	return &Stats{
 		min: math.MaxFloat64,
+		max: -math.MaxFloat64,
 	}
 }
 
+// WithEWMA enables the exponentially weighted mean and variance with the given smoothing factor.
+// Higher values of alpha discount older elements faster.
+func (s *Stats) WithEWMA(alpha float64) *Stats {
+	s.ewma = newEWMA(alpha)
+	return s
+}
+
+// WithQuantiles enables the estimation of quantiles with a digest of the given compression.
+func (s *Stats) WithQuantiles(compression float64) *Stats {
+	s.digest = NewDigest(compression)
+	return s
+}
+
 // Push adds another element to the set.
 func (s *Stats) Push(v float64) {
+	n1 := float64(s.count)
 	s.count++
+	n := float64(s.count)
 	s.sum += v
-	diff := (v - s.mean) / float64(s.count)
-	mean := s.mean + diff
-	squaredDiff := (v - mean) * (v - s.mean)
-	s.dSquared += squaredDiff
-	s.mean = mean
+
+	delta := v - s.mean
+	deltaN := delta / n
+	deltaN2 := deltaN * deltaN
+	term := delta * deltaN * n1
+	s.mean += deltaN
+	s.m4 += term*deltaN2*(n*n-3*n+3) + 6*deltaN2*s.m2 - 4*deltaN*s.m3
+	s.m3 += term*deltaN*(n-2) - 3*deltaN*s.m2
+	s.m2 += term
 
 	if s.count == 1 {
 		s.first = v
@@ -44,6 +72,56 @@ func (s *Stats) Push(v float64) {
 	}
 
 	s.last = v
+
+	if s.ewma != nil {
+		s.ewma.push(v)
+	}
+	if s.digest != nil {
+		s.digest.Push(v)
+	}
+}
+
+// Merge adds the elements of the other set to this one, as if they were pushed after the current ones.
+// Both sets must track the same properties.
+func (s *Stats) Merge(other *Stats) {
+	if (s.ewma == nil) != (other.ewma == nil) || (s.ewma != nil && s.ewma.alpha != other.ewma.alpha) {
+		panic("cannot merge stats with different ewma configuration")
+	}
+	if (s.digest == nil) != (other.digest == nil) {
+		panic("cannot merge stats with different quantile configuration")
+	}
+	if other.count == 0 {
+		return
+	}
+	if s.count == 0 {
+		s.first = other.first
+	}
+
+	na, nb := float64(s.count), float64(other.count)
+	n := na + nb
+	delta := other.mean - s.mean
+	delta2 := delta * delta
+	m2 := s.m2 + other.m2 + delta2*na*nb/n
+	m3 := s.m3 + other.m3 + delta2*delta*na*nb*(na-nb)/(n*n) +
+		3*delta*(na*other.m2-nb*s.m2)/n
+	m4 := s.m4 + other.m4 + delta2*delta2*na*nb*(na*na-na*nb+nb*nb)/(n*n*n) +
+		6*delta2*(na*na*other.m2+nb*nb*s.m2)/(n*n) +
+		4*delta*(na*other.m3-nb*s.m3)/n
+	s.mean += delta * nb / n
+	s.m2, s.m3, s.m4 = m2, m3, m4
+
+	s.count += other.count
+	s.sum += other.sum
+	s.min = math.Min(s.min, other.min)
+	s.max = math.Max(s.max, other.max)
+	s.last = other.last
+
+	if s.ewma != nil {
+		s.ewma.merge(other.ewma)
+	}
+	if s.digest != nil {
+		s.digest.Merge(other.digest)
+	}
 }
 
 // Avg returns the average value of the set.
@@ -61,6 +139,26 @@ func (s Stats) Count() int {
 	return s.count
 }
 
+// Min returns the minimum value of the set.
+func (s Stats) Min() float64 {
+	return s.min
+}
+
+// Max returns the maximum value of the set.
+func (s Stats) Max() float64 {
+	return s.max
+}
+
+// First returns the first value of the set.
+func (s Stats) First() float64 {
+	return s.first
+}
+
+// Last returns the last value of the set.
+func (s Stats) Last() float64 {
+	return s.last
+}
+
 // Diff returns the difference of max and min.
 func (s Stats) Diff() float64 {
 	return s.last - s.first
@@ -68,7 +166,7 @@ func (s Stats) Diff() float64 {
 
 // Variance is the mathematical variance of the set.
 func (s Stats) Variance() float64 {
-	return s.dSquared / float64(s.count)
+	return s.m2 / float64(s.count)
 }
 
 // StDev is the standard deviation of the set.
@@ -78,7 +176,7 @@ func (s Stats) StDev() float64 {
 
 // SampleVariance is the sample variance of the set.
 func (s Stats) SampleVariance() float64 {
-	return s.dSquared / float64(s.count-1)
+	return s.m2 / float64(s.count-1)
 }
 
 // SampleStDev is the sample standard deviation of the set.
@@ -86,11 +184,102 @@ func (s Stats) SampleStDev() float64 {
 	return math.Sqrt(s.SampleVariance())
 }
 
+// Skewness is the skewness of the set e.g. the asymmetry of its distribution around the mean.
+func (s Stats) Skewness() float64 {
+	return math.Sqrt(float64(s.count)) * s.m3 / math.Pow(s.m2, 1.5)
+}
+
+// Kurtosis is the excess kurtosis of the set e.g. how heavy the tails of its distribution are compared to the normal one.
+func (s Stats) Kurtosis() float64 {
+	return float64(s.count)*s.m4/(s.m2*s.m2) - 3
+}
+
+// EWMA returns the exponentially weighted mean of the set, or NaN if it is not enabled.
+func (s Stats) EWMA() float64 {
+	if s.ewma == nil {
+		return math.NaN()
+	}
+	return s.ewma.mean()
+}
+
+// EWVariance returns the exponentially weighted variance of the set, or NaN if it is not enabled.
+func (s Stats) EWVariance() float64 {
+	if s.ewma == nil {
+		return math.NaN()
+	}
+	return s.ewma.variance()
+}
+
+// Quantile returns the estimated value below which the given fraction of the elements lie,
+// or NaN if the quantiles are not enabled.
+func (s Stats) Quantile(q float64) float64 {
+	if s.digest == nil {
+		return math.NaN()
+	}
+	return s.digest.Quantile(q)
+}
+
+// Median returns the estimated median of the set, or NaN if the quantiles are not enabled.
+func (s Stats) Median() float64 {
+	return s.Quantile(0.5)
+}
+
+// ewma keeps the exponentially weighted moments of a stream.
+// The moments start from zero and are corrected by the total weight, so that the early values are not biased
+// and two streams can be merged exactly.
+type ewma struct {
+	alpha float64
+	// decay is the weight left for the moments before all the elements e.g. (1-alpha)^count
+	decay  float64
+	m1, m2 float64
+}
+
+func newEWMA(alpha float64) *ewma {
+	if alpha <= 0 || alpha > 1 {
+		panic(fmt.Sprintf("ewma alpha must be within (0,1] '%v'", alpha))
+	}
+	return &ewma{
+		alpha: alpha,
+		decay: 1,
+	}
+}
+
+func (e *ewma) push(v float64) {
+	e.m1 = (1-e.alpha)*e.m1 + e.alpha*v
+	e.m2 = (1-e.alpha)*e.m2 + e.alpha*v*v
+	e.decay *= 1 - e.alpha
+}
+
+// merge applies the other moments, as if its elements came after the current ones.
+func (e *ewma) merge(other *ewma) {
+	e.m1 = other.decay*e.m1 + other.m1
+	e.m2 = other.decay*e.m2 + other.m2
+	e.decay *= other.decay
+}
+
+// weight is the total weight of the elements.
+func (e *ewma) weight() float64 {
+	return 1 - e.decay
+}
+
+func (e *ewma) mean() float64 {
+	return e.m1 / e.weight()
+}
+
+func (e *ewma) variance() float64 {
+	m := e.mean()
+	return math.Max(0, e.m2/e.weight()-m*m)
+}
+
 // StatsCollector is a collection of Stats variables.
 // This enabled multi-dimensional tracking.
+// It can also track the co-variance across the dimensions, if enabled.
 type StatsCollector struct {
 	dim   int
 	stats []*Stats
+	// comoments are the sums of the products of differences from the mean across dimensions, if enabled
+	comoments xmath2.Matrix
+	delta     xmath2.Vector
 }
 
 // NewStatsCollector creates a new Stats collector.
@@ -105,14 +294,121 @@ func NewStatsCollector(dim int) *StatsCollector {
 	}
 }
 
+// WithCovariance enables the co-variance across the dimensions.
+// It should be enabled before any elements are pushed.
+// Note that its cost grows with the square of the dimensions, both in memory and for each push.
+func (sc *StatsCollector) WithCovariance() *StatsCollector {
+	sc.comoments = xmath2.Mat(sc.dim).Of(sc.dim)
+	sc.delta = xmath2.Vec(sc.dim)
+	return sc
+}
+
+// WithEWMA enables the exponentially weighted mean and variance for all dimensions.
+func (sc *StatsCollector) WithEWMA(alpha float64) *StatsCollector {
+	for _, s := range sc.stats {
+		s.WithEWMA(alpha)
+	}
+	return sc
+}
+
+// WithQuantiles enables the estimation of quantiles for all dimensions.
+func (sc *StatsCollector) WithQuantiles(compression float64) *StatsCollector {
+	for _, s := range sc.stats {
+		s.WithQuantiles(compression)
+	}
+	return sc
+}
+
 // Push pushes each value to the corresponding dimension.
 func (sc *StatsCollector) Push(v ...float64) {
 	if len(v) != sc.dim {
 		panic(fmt.Sprintf("inconsistent dimensions %d vs %d", len(v), sc.dim))
 	}
+	if sc.comoments == nil {
+		for i := 0; i < len(sc.stats); i++ {
+			sc.stats[i].Push(v[i])
+		}
+		return
+	}
 	for i := 0; i < len(sc.stats); i++ {
+		sc.delta[i] = v[i] - sc.stats[i].mean
 		sc.stats[i].Push(v[i])
 	}
+	if sc.dim == 0 {
+		return
+	}
+	n := float64(sc.stats[0].count)
+	f := (n - 1) / n
+	for i := range sc.comoments {
+		for j := range sc.comoments[i] {
+			sc.comoments[i][j] += f * sc.delta[i] * sc.delta[j]
+		}
+	}
+}
+
+// Merge adds the elements of the other collector to this one, as if they were pushed after the current ones.
+// Both collectors must track the same properties.
+func (sc *StatsCollector) Merge(other *StatsCollector) {
+	if other.dim != sc.dim {
+		panic(fmt.Sprintf("inconsistent dimensions %d vs %d", other.dim, sc.dim))
+	}
+	if (sc.comoments == nil) != (other.comoments == nil) {
+		panic("cannot merge collectors with different covariance configuration")
+	}
+	if sc.dim == 0 {
+		return
+	}
+	na, nb := float64(sc.stats[0].count), float64(other.stats[0].count)
+	if nb == 0 {
+		return
+	}
+	if sc.comoments == nil {
+		for i := range sc.stats {
+			sc.stats[i].Merge(other.stats[i])
+		}
+		return
+	}
+	for i := range sc.delta {
+		sc.delta[i] = other.stats[i].mean - sc.stats[i].mean
+	}
+	f := na * nb / (na + nb)
+	for i := range sc.comoments {
+		for j := range sc.comoments[i] {
+			sc.comoments[i][j] += other.comoments[i][j] + f*sc.delta[i]*sc.delta[j]
+		}
+	}
+	for i := range sc.stats {
+		sc.stats[i].Merge(other.stats[i])
+	}
+}
+
+// Covariance returns the covariance matrix across the dimensions, or nil if the covariance is not enabled.
+func (sc StatsCollector) Covariance() xmath2.Matrix {
+	if sc.comoments == nil {
+		return nil
+	}
+	if sc.dim == 0 {
+		return xmath2.Mat(0)
+	}
+	return sc.comoments.Mult(1 / float64(sc.stats[0].count))
+}
+
+// Correlation returns the pearson correlation matrix across the dimensions, or nil if the covariance is not enabled.
+// The correlation with a constant dimension is 0.
+func (sc StatsCollector) Correlation() xmath2.Matrix {
+	if sc.comoments == nil {
+		return nil
+	}
+	corr := xmath2.Mat(sc.dim).Of(sc.dim)
+	for i := range corr {
+		for j := range corr[i] {
+			d := math.Sqrt(sc.comoments[i][i] * sc.comoments[j][j])
+			if d > 0 {
+				corr[i][j] = sc.comoments[i][j] / d
+			}
+		}
+	}
+	return corr
 }
 
 func (sc StatsCollector) Stats() []*Stats {
@@ -215,6 +511,10 @@ func (w *Window) Push(index int64, value ...float64) (int64, bool) {
 		w.lastIndex = index
 	}
 
+	if w.current.Size() == 0 {
+		// the dimensions of the bucket are defined by its first value
+		w.current = NewBucket(w.lastIndex, len(value))
+	}
 	w.current.Push(w.lastIndex, value...)
 
 	return lastIndex, ready
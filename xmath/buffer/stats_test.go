@@ -3,8 +3,13 @@ package buffer
 import (
 	"fmt"
 	"math"
+	"math/rand"
+	"sort"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestSet_Push(t *testing.T) {
@@ -56,6 +61,53 @@ func TestTimeWindow(t *testing.T) {
 
 }
 
+func TestWindow_Dimensions(t *testing.T) {
+
+	type test struct {
+		size int64
+		dim  int
+	}
+
+	tests := map[string]test{
+		"single": {size: 10, dim: 1},
+		"same":   {size: 3, dim: 3},
+		"more":   {size: 2, dim: 5},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			window := NewWindow(tt.size)
+			var buckets []Bucket
+			// start after the first index, so that the first bucket is not re-created with the first value
+			for i := int64(1); i <= 3*tt.size; i++ {
+				v := make([]float64, tt.dim)
+				for j := range v {
+					v[j] = float64(i) * float64(j+1)
+				}
+				if _, ok := window.Push(i, v...); ok {
+					buckets = append(buckets, window.Get())
+				}
+			}
+			// the dimensions of the buckets follow the values, regardless of the window size
+			assert.Equal(t, 3, len(buckets))
+			for k, bucket := range buckets {
+				from := int64(k) * tt.size
+				if k == 0 {
+					from = 1
+				}
+				to := int64(k+1)*tt.size - 1
+				assert.Equal(t, int(to-from+1), bucket.Size())
+				stats := bucket.Values().Stats()
+				assert.Equal(t, tt.dim, len(stats))
+				for j, s := range stats {
+					assert.Equal(t, float64(j+1)*float64(from+to)/2, s.Avg())
+				}
+			}
+		})
+	}
+
+}
+
 func TestHistoryWindow_Increasing(t *testing.T) {
 
 	type test struct {
@@ -135,3 +187,319 @@ func TestHistoryWindow_Increasing(t *testing.T) {
 	}
 
 }
+
+// moments computes the mean, variance, skewness and excess kurtosis of the values directly.
+func moments(values []float64) (mean, variance, skewness, kurtosis float64) {
+	n := float64(len(values))
+	for _, v := range values {
+		mean += v
+	}
+	mean /= n
+	var m2, m3, m4 float64
+	for _, v := range values {
+		d := v - mean
+		m2 += d * d
+		m3 += d * d * d
+		m4 += d * d * d * d
+	}
+	return mean, m2 / n, math.Sqrt(n) * m3 / math.Pow(m2, 1.5), n*m4/(m2*m2) - 3
+}
+
+func randValues(n int, seed int64, gen func(rng *rand.Rand) float64) []float64 {
+	rng := rand.New(rand.NewSource(seed))
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = gen(rng)
+	}
+	return values
+}
+
+func TestStats_Moments(t *testing.T) {
+
+	type test struct {
+		gen func(rng *rand.Rand) float64
+	}
+
+	tests := map[string]test{
+		"uniform": {gen: func(rng *rand.Rand) float64 {
+			return rng.Float64()
+		}},
+		"normal": {gen: func(rng *rand.Rand) float64 {
+			return 10 + 2*rng.NormFloat64()
+		}},
+		"exponential": {gen: func(rng *rand.Rand) float64 {
+			return rng.ExpFloat64()
+		}},
+		"negative": {gen: func(rng *rand.Rand) float64 {
+			return -1 - rng.Float64()
+		}},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			values := randValues(1000, 1, tt.gen)
+			stats := NewStats()
+			for _, v := range values {
+				stats.Push(v)
+			}
+			mean, variance, skewness, kurtosis := moments(values)
+			assert.InDelta(t, mean, stats.Avg(), 1e-9)
+			assert.InDelta(t, variance, stats.Variance(), 1e-9)
+			assert.InDelta(t, skewness, stats.Skewness(), 1e-9)
+			assert.InDelta(t, kurtosis, stats.Kurtosis(), 1e-9)
+
+			sorted := append([]float64{}, values...)
+			sort.Float64s(sorted)
+			assert.Equal(t, sorted[0], stats.Min())
+			assert.Equal(t, sorted[len(sorted)-1], stats.Max())
+
+			// the optional properties are not tracked
+			assert.True(t, math.IsNaN(stats.EWMA()))
+			assert.True(t, math.IsNaN(stats.Median()))
+		})
+	}
+
+}
+
+func TestStats_EWMA(t *testing.T) {
+
+	alpha := 0.1
+	values := randValues(100, 1, func(rng *rand.Rand) float64 {
+		return rng.NormFloat64()
+	})
+
+	stats := NewStats().WithEWMA(alpha)
+	// the classic recursion, started from the first value
+	mean := values[0]
+	for i, v := range values {
+		stats.Push(v)
+		if i > 0 {
+			mean = (1-alpha)*mean + alpha*v
+		}
+	}
+	// the bias correction makes up for the different start, with a weight of (1-alpha)^n
+	assert.InDelta(t, mean, stats.EWMA(), 1e-3)
+	assert.True(t, stats.EWVariance() > 0)
+
+	// a constant stream has its value as mean and no variance
+	constant := NewStats().WithEWMA(alpha)
+	for i := 0; i < 10; i++ {
+		constant.Push(5)
+	}
+	assert.InDelta(t, 5, constant.EWMA(), 1e-12)
+	assert.InDelta(t, 0, constant.EWVariance(), 1e-12)
+
+	assert.Panics(t, func() {
+		NewStats().WithEWMA(0)
+	})
+
+}
+
+func TestStats_Quantile(t *testing.T) {
+
+	type test struct {
+		gen func(rng *rand.Rand) float64
+	}
+
+	tests := map[string]test{
+		"uniform": {gen: func(rng *rand.Rand) float64 {
+			return rng.Float64()
+		}},
+		"normal": {gen: func(rng *rand.Rand) float64 {
+			return rng.NormFloat64()
+		}},
+		"exponential": {gen: func(rng *rand.Rand) float64 {
+			return rng.ExpFloat64()
+		}},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			values := randValues(10000, 1, tt.gen)
+			stats := NewStats().WithQuantiles(DefaultCompression)
+			for _, v := range values {
+				stats.Push(v)
+			}
+			sorted := append([]float64{}, values...)
+			sort.Float64s(sorted)
+			for _, q := range []float64{0.01, 0.1, 0.25, 0.5, 0.75, 0.9, 0.99} {
+				// compare the rank of the estimate with the requested one
+				estimate := stats.Quantile(q)
+				rank := float64(sort.SearchFloat64s(sorted, estimate)) / float64(len(sorted))
+				assert.InDelta(t, q, rank, 0.01, fmt.Sprintf("quantile %v", q))
+			}
+			assert.Equal(t, sorted[0], stats.Quantile(0))
+			assert.Equal(t, sorted[len(sorted)-1], stats.Quantile(1))
+			assert.True(t, len(stats.digest.centroids) <= 2*DefaultCompression)
+		})
+	}
+
+}
+
+func TestStats_Merge(t *testing.T) {
+
+	values := randValues(10000, 1, func(rng *rand.Rand) float64 {
+		return rng.ExpFloat64()
+	})
+
+	newStats := func() *Stats {
+		return NewStats().WithEWMA(0.01).WithQuantiles(DefaultCompression)
+	}
+
+	expected := newStats()
+	for _, v := range values {
+		expected.Push(v)
+	}
+
+	// push each part of the stream from a different goroutine
+	parts := 4
+	stats := make([]*Stats, parts)
+	wg := sync.WaitGroup{}
+	for p := 0; p < parts; p++ {
+		stats[p] = newStats()
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			size := len(values) / parts
+			for _, v := range values[p*size : (p+1)*size] {
+				stats[p].Push(v)
+			}
+		}(p)
+	}
+	wg.Wait()
+
+	merged := newStats()
+	for _, s := range stats {
+		merged.Merge(s)
+	}
+
+	assert.Equal(t, expected.Count(), merged.Count())
+	assert.InDelta(t, expected.Sum(), merged.Sum(), 1e-9)
+	assert.Equal(t, expected.Min(), merged.Min())
+	assert.Equal(t, expected.Max(), merged.Max())
+	assert.Equal(t, expected.Diff(), merged.Diff())
+	assert.InDelta(t, expected.Avg(), merged.Avg(), 1e-9)
+	assert.InDelta(t, expected.Variance(), merged.Variance(), 1e-9)
+	assert.InDelta(t, expected.Skewness(), merged.Skewness(), 1e-9)
+	assert.InDelta(t, expected.Kurtosis(), merged.Kurtosis(), 1e-9)
+	// the ewma is merged exactly, as the parts follow each other
+	assert.InDelta(t, expected.EWMA(), merged.EWMA(), 1e-9)
+	assert.InDelta(t, expected.EWVariance(), merged.EWVariance(), 1e-9)
+	for _, q := range []float64{0.1, 0.5, 0.9} {
+		assert.InDelta(t, expected.Quantile(q), merged.Quantile(q), 0.02*expected.Quantile(q))
+	}
+
+	assert.Panics(t, func() {
+		NewStats().Merge(newStats())
+	})
+
+}
+
+func TestStatsCollector_Covariance(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(1))
+	n := 1000
+	data := make([][]float64, n)
+	for i := range data {
+		x := rng.NormFloat64()
+		// y follows x, z is the opposite and w is independent
+		data[i] = []float64{x, 2*x + 0.1*rng.NormFloat64(), -x, rng.NormFloat64()}
+	}
+
+	expected := func(i, j int) float64 {
+		var mi, mj float64
+		for _, v := range data {
+			mi += v[i]
+			mj += v[j]
+		}
+		mi /= float64(n)
+		mj /= float64(n)
+		var c float64
+		for _, v := range data {
+			c += (v[i] - mi) * (v[j] - mj)
+		}
+		return c / float64(n)
+	}
+
+	assertCollector := func(t *testing.T, sc *StatsCollector) {
+		cov := sc.Covariance()
+		corr := sc.Correlation()
+		for i := 0; i < 4; i++ {
+			for j := 0; j < 4; j++ {
+				assert.InDelta(t, expected(i, j), cov[i][j], 1e-9)
+				assert.InDelta(t, expected(i, j)/math.Sqrt(expected(i, i)*expected(j, j)), corr[i][j], 1e-9)
+			}
+		}
+		assert.InDelta(t, 1, corr[0][1], 0.01)
+		assert.InDelta(t, -1, corr[0][2], 1e-9)
+		assert.InDelta(t, 0, corr[0][3], 0.1)
+	}
+
+	t.Run("push", func(t *testing.T) {
+		sc := NewStatsCollector(4).WithCovariance()
+		for _, v := range data {
+			sc.Push(v...)
+		}
+		assertCollector(t, sc)
+	})
+
+	t.Run("merge", func(t *testing.T) {
+		first, second := NewStatsCollector(4).WithCovariance(), NewStatsCollector(4).WithCovariance()
+		for i, v := range data {
+			if i < n/3 {
+				first.Push(v...)
+			} else {
+				second.Push(v...)
+			}
+		}
+		first.Merge(second)
+		assertCollector(t, first)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		first, second := NewStatsCollector(4), NewStatsCollector(4)
+		for i, v := range data {
+			if i < n/3 {
+				first.Push(v...)
+			} else {
+				second.Push(v...)
+			}
+		}
+		first.Merge(second)
+		assert.Nil(t, first.Covariance())
+		assert.Nil(t, first.Correlation())
+		// the scalar statistics are the same as with the covariance
+		sc := NewStatsCollector(4).WithCovariance()
+		for _, v := range data {
+			sc.Push(v...)
+		}
+		for i := range sc.Stats() {
+			assert.Equal(t, n, first.Stats()[i].Count())
+			assert.InDelta(t, sc.Stats()[i].Avg(), first.Stats()[i].Avg(), 1e-9)
+			assert.InDelta(t, sc.Stats()[i].Variance(), first.Stats()[i].Variance(), 1e-9)
+		}
+		assert.Panics(t, func() {
+			first.Merge(sc)
+		})
+	})
+
+}
+
+func BenchmarkStatsCollector_Push(b *testing.B) {
+	v := make([]float64, 784)
+	for i := range v {
+		v[i] = float64(i)
+	}
+	b.Run("scalar", func(b *testing.B) {
+		sc := NewStatsCollector(len(v))
+		for i := 0; i < b.N; i++ {
+			sc.Push(v...)
+		}
+	})
+	b.Run("covariance", func(b *testing.B) {
+		sc := NewStatsCollector(len(v)).WithCovariance()
+		for i := 0; i < b.N; i++ {
+			sc.Push(v...)
+		}
+	})
+}
@@ -0,0 +1,206 @@
+package buffer
+
+import (
+	"sync"
+	"time"
+
+	xmath2 "github.com/drakos74/go-ex-machina/xmath"
+)
+
+// The Sync types wrap the buffers, so that they can be pushed to and read from multiple goroutines.
+// Reads return snapshots, which are not affected by later pushes.
+
+// SyncRing is a Ring that is safe for concurrent use.
+type SyncRing struct {
+	mutex sync.RWMutex
+	ring  *Ring
+}
+
+// NewSyncRing creates a new concurrent ring with the given buffer size.
+func NewSyncRing(size int) *SyncRing {
+	return &SyncRing{
+		ring: NewRing(size),
+	}
+}
+
+// Push adds an element to the ring.
+func (r *SyncRing) Push(v float64) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.ring.Push(v)
+}
+
+// Size returns the number of non-nil elements within the ring.
+func (r *SyncRing) Size() int {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.ring.Size()
+}
+
+// Get returns a snapshot of the ring elements in order.
+func (r *SyncRing) Get() []float64 {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.ring.Get()
+}
+
+// Aggregate applies the process on the ring elements.
+func (r *SyncRing) Aggregate(process Func) float64 {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.ring.Aggregate(process)
+}
+
+// SyncVectorRing is a VectorRing that is safe for concurrent use.
+type SyncVectorRing struct {
+	mutex sync.RWMutex
+	ring  *VectorRing
+}
+
+// NewSyncVectorRing creates a new concurrent vector ring of the given size.
+func NewSyncVectorRing(n int) *SyncVectorRing {
+	return &SyncVectorRing{
+		ring: NewVectorRing(n),
+	}
+}
+
+// NewSyncSplitVectorRing creates a new concurrent vector ring with an extra element, as the NewSplitVectorRing.
+func NewSyncSplitVectorRing(n int) *SyncVectorRing {
+	return &SyncVectorRing{
+		ring: NewSplitVectorRing(n),
+	}
+}
+
+// Push adds an element to the ring, and returns a snapshot of the batch once it is complete.
+func (w *SyncVectorRing) Push(v xmath2.Vector) (xmath2.Matrix, bool) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	batch, ok := w.ring.Push(v.Copy())
+	if !ok {
+		return nil, false
+	}
+	return batch.Copy(), true
+}
+
+// Batch returns a snapshot of the current batch, and if it is complete.
+// The rows of an incomplete batch that have not been pushed yet are nil, at the start of the batch.
+func (w *SyncVectorRing) Batch() (xmath2.Matrix, bool) {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+	batch := w.ring.batch()
+	for i := range batch {
+		if batch[i] != nil {
+			batch[i] = batch[i].Copy()
+		}
+	}
+	return batch, w.ring.isReady()
+}
+
+// Size returns the size of the batches.
+func (w *SyncVectorRing) Size() int {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+	return w.ring.Size()
+}
+
+// SyncBucketRing is a BucketRing that is safe for concurrent use.
+type SyncBucketRing struct {
+	mutex sync.RWMutex
+	ring  *BucketRing
+}
+
+// NewSyncBucketRing creates a new concurrent bucket ring with the given buffer size.
+func NewSyncBucketRing(size int) *SyncBucketRing {
+	return &SyncBucketRing{
+		ring: NewBucketRing(size),
+	}
+}
+
+// Push adds a bucket to the ring.
+func (r *SyncBucketRing) Push(v *Bucket) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.ring.Push(v)
+}
+
+// Size returns the number of non-nil elements within the ring.
+func (r *SyncBucketRing) Size() int {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.ring.Size()
+}
+
+// Get returns the transformed buckets in order.
+func (r *SyncBucketRing) Get(transform Transform) []interface{} {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.ring.Get(transform)
+}
+
+// SyncWindow is a Window that is safe for concurrent use.
+// As the closed buckets are consumed within the push, there is no separate Get.
+type SyncWindow struct {
+	mutex  sync.Mutex
+	window *Window
+}
+
+// NewSyncWindow creates a new concurrent window of the given window size.
+func NewSyncWindow(size int64) *SyncWindow {
+	return &SyncWindow{
+		window: NewWindow(size),
+	}
+}
+
+// Push adds an element to the window at the given index.
+// It returns the bucket that closed with the push, if any.
+func (w *SyncWindow) Push(index int64, value ...float64) (*Bucket, bool) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if _, ok := w.window.Push(index, value...); ok {
+		bucket := w.window.Get()
+		return &bucket, true
+	}
+	return nil, false
+}
+
+// Current returns the current index the window accumulates data on.
+func (w *SyncWindow) Current() int64 {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.window.Current()
+}
+
+// Next is the next index at which a new bucket will be created.
+func (w *SyncWindow) Next() int64 {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.window.Next()
+}
+
+// SyncHistoryWindow is a HistoryWindow that is safe for concurrent use.
+type SyncHistoryWindow struct {
+	mutex  sync.RWMutex
+	window *HistoryWindow
+}
+
+// NewSyncHistoryWindow creates a new concurrent history window.
+func NewSyncHistoryWindow(duration time.Duration, size int) *SyncHistoryWindow {
+	return &SyncHistoryWindow{
+		window: NewHistoryWindow(duration, size),
+	}
+}
+
+// Push adds an element to the given time index.
+// It will return true, if there was a new bucket completed at the last operation.
+func (h *SyncHistoryWindow) Push(t time.Time, v ...float64) (time.Time, *Bucket, bool) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.window.Push(t, v...)
+}
+
+// Get returns the transformed buckets of the history.
+func (h *SyncHistoryWindow) Get(transform Transform) []interface{} {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	return h.window.Get(transform)
+}
@@ -0,0 +1,193 @@
+package buffer
+
+import (
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	xmath2 "github.com/drakos74/go-ex-machina/xmath"
+	"github.com/stretchr/testify/assert"
+)
+
+// concurrently runs the producer and the consumer in parallel goroutines, the given number of times each.
+func concurrently(n int, producer func(i int), consumer func(i int)) {
+	wg := sync.WaitGroup{}
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < n; i++ {
+			producer(i)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < n; i++ {
+			consumer(i)
+		}
+	}()
+	wg.Wait()
+}
+
+func TestSyncRing(t *testing.T) {
+
+	ring := NewSyncRing(10)
+
+	concurrently(1000, func(i int) {
+		ring.Push(float64(i))
+	}, func(i int) {
+		values := ring.Get()
+		assert.Equal(t, 10, len(values))
+		ring.Aggregate(Sum)
+		ring.Size()
+	})
+
+	assert.Equal(t, []float64{990, 991, 992, 993, 994, 995, 996, 997, 998, 999}, ring.Get())
+
+}
+
+func TestSyncVectorRing(t *testing.T) {
+
+	ring := NewSyncVectorRing(3)
+
+	concurrently(1000, func(i int) {
+		v := xmath2.Vec(2).With(float64(i), float64(i))
+		if batch, ok := ring.Push(v); ok {
+			// the batch keeps the last values in order
+			assert.Equal(t, float64(i), batch[2][0])
+			assert.Equal(t, float64(i-2), batch[0][0])
+		}
+		// changing the vector after the push does not affect the ring
+		v[0] = -1
+	}, func(i int) {
+		batch, ok := ring.Batch()
+		assert.Equal(t, 3, len(batch))
+		if ok {
+			for _, row := range batch {
+				assert.True(t, row[0] >= 0)
+				// changing the snapshot does not affect the ring
+				row[1] = -1
+			}
+		}
+	})
+
+	batch, ok := ring.Batch()
+	assert.True(t, ok)
+	assert.Equal(t, xmath2.Mat(3).With(
+		xmath2.Vec(2).With(997, 997),
+		xmath2.Vec(2).With(998, 998),
+		xmath2.Vec(2).With(999, 999),
+	), batch)
+
+}
+
+func TestSyncBucketRing(t *testing.T) {
+
+	ring := NewSyncBucketRing(5)
+
+	concurrently(1000, func(i int) {
+		bucket := NewBucket(int64(i), 1)
+		bucket.Push(int64(i), float64(i))
+		ring.Push(&bucket)
+	}, func(i int) {
+		values := ring.Get(func(bucket *Bucket) interface{} {
+			return bucket.Index()
+		})
+		assert.True(t, len(values) <= 5)
+		ring.Size()
+	})
+
+	assert.Equal(t, 5, ring.Size())
+
+}
+
+func TestSyncWindow(t *testing.T) {
+
+	window := NewSyncWindow(10)
+	buckets := make(chan *Bucket, 100)
+
+	concurrently(1000, func(i int) {
+		if bucket, ok := window.Push(int64(i+1), float64(i)); ok {
+			buckets <- bucket
+		}
+	}, func(i int) {
+		window.Current()
+		window.Next()
+	})
+	close(buckets)
+
+	// the first bucket starts at 0, and the last one at 1000 is still open
+	count := 0
+	for bucket := range buckets {
+		if count == 0 {
+			assert.Equal(t, 9, bucket.Size())
+		} else {
+			assert.Equal(t, 10, bucket.Size())
+		}
+		count++
+	}
+	assert.Equal(t, 100, count)
+
+}
+
+func TestSyncHistoryWindow(t *testing.T) {
+
+	window := NewSyncHistoryWindow(10*time.Second, 5)
+	start := time.Unix(1000, 0)
+
+	concurrently(1000, func(i int) {
+		window.Push(start.Add(time.Duration(i)*time.Second), float64(i))
+	}, func(i int) {
+		values := window.Get(func(bucket *Bucket) interface{} {
+			return bucket.Values().Stats()[0].Avg()
+		})
+		assert.True(t, len(values) <= 5)
+	})
+
+	assert.Equal(t, []interface{}{944.5, 954.5, 964.5, 974.5, 984.5}, window.Get(func(bucket *Bucket) interface{} {
+		return bucket.Values().Stats()[0].Avg()
+	}))
+
+}
+
+func TestSyncBucketRing_Quantiles(t *testing.T) {
+
+	ring := NewSyncBucketRing(5)
+	push := func(i int) {
+		bucket := NewBucket(int64(i), 1)
+		bucket.stats.WithQuantiles(100)
+		// keep some elements pending in the digest, so that the quantiles need to merge them in
+		for j := 0; j < 101; j++ {
+			bucket.Push(int64(i), float64(i*1000+j))
+		}
+		ring.Push(&bucket)
+	}
+	for i := 0; i < 5; i++ {
+		push(i)
+	}
+
+	median := func(bucket *Bucket) interface{} {
+		return bucket.Values().Stats()[0].Median()
+	}
+
+	// several readers compute the quantiles of the same buckets, while the producer pushes new ones
+	wg := sync.WaitGroup{}
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				for _, m := range ring.Get(median) {
+					assert.False(t, math.IsNaN(m.(float64)))
+				}
+			}
+		}()
+	}
+	for i := 5; i < 100; i++ {
+		push(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, []interface{}{95050.0, 96050.0, 97050.0, 98050.0, 99050.0}, ring.Get(median))
+
+}
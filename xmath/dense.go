@@ -0,0 +1,295 @@
+package xmath
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Dense is a matrix backed by a single contiguous slice in row-major order.
+// Compared to Matrix it needs one allocation instead of one per row,
+// and its transpose is a view on the same data instead of a copy.
+type Dense struct {
+	data       []float64
+	rows, cols int
+	stride     int
+	transposed bool
+}
+
+// NewDense creates a new zero Dense matrix with the given number of rows and columns.
+func NewDense(rows, cols int) Dense {
+	return Dense{
+		data:   make([]float64, rows*cols),
+		rows:   rows,
+		cols:   cols,
+		stride: cols,
+	}
+}
+
+// DenseOf creates a new Dense matrix with the values of the given Matrix.
+func DenseOf(m Matrix) Dense {
+	var cols int
+	if len(m) > 0 {
+		cols = len(m[0])
+	}
+	d := NewDense(len(m), cols)
+	for i := range m {
+		MustHaveSize(m[i], cols)
+		copy(d.data[i*d.stride:], m[i])
+	}
+	return d
+}
+
+// Dims returns the number of rows and columns of the matrix.
+func (d Dense) Dims() (rows, cols int) {
+	if d.transposed {
+		return d.cols, d.rows
+	}
+	return d.rows, d.cols
+}
+
+// index returns the position of the element at row i and column j in the underlying data.
+func (d Dense) index(i, j int) int {
+	if d.transposed {
+		i, j = j, i
+	}
+	return i*d.stride + j
+}
+
+// At returns the element at row i and column j.
+func (d Dense) At(i, j int) float64 {
+	return d.data[d.index(i, j)]
+}
+
+// Set sets the element at row i and column j.
+func (d Dense) Set(i, j int, v float64) {
+	d.data[d.index(i, j)] = v
+}
+
+// Row returns the row at the given index.
+// For non-transposed matrices the row shares the underlying data, so mutations will be reflected on the matrix.
+func (d Dense) Row(i int) Vector {
+	if !d.transposed {
+		return d.data[i*d.stride : i*d.stride+d.cols]
+	}
+	rows, cols := d.Dims()
+	if i >= rows {
+		panic(fmt.Sprintf("row index %d out of range for %d rows", i, rows))
+	}
+	v := Vec(cols)
+	for j := range v {
+		v[j] = d.At(i, j)
+	}
+	return v
+}
+
+// T returns the transpose of the matrix as a view on the same data.
+func (d Dense) T() Dense {
+	t := d
+	t.transposed = !d.transposed
+	return t
+}
+
+// Matrix converts the Dense matrix to the Matrix form.
+func (d Dense) Matrix() Matrix {
+	rows, _ := d.Dims()
+	m := Mat(rows)
+	for i := range m {
+		m[i] = d.Row(i).Copy()
+	}
+	return m
+}
+
+// Copy copies the matrix into a new one with the same values and a contiguous row-major layout.
+func (d Dense) Copy() Dense {
+	rows, cols := d.Dims()
+	c := NewDense(rows, cols)
+	if !d.transposed && d.stride == d.cols {
+		copy(c.data, d.data)
+		return c
+	}
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			c.data[i*c.stride+j] = d.At(i, j)
+		}
+	}
+	return c
+}
+
+// Generate generates the rows of the matrix using the generator func.
+func (d Dense) Generate(gen VectorGenerator) Dense {
+	rows, cols := d.Dims()
+	for i := 0; i < rows; i++ {
+		v := gen(cols, i)
+		MustHaveSize(v, cols)
+		for j := range v {
+			d.Set(i, j, v[j])
+		}
+	}
+	return d
+}
+
+// Prod returns the product of the matrix with the given vector.
+func (d Dense) Prod(v Vector) Vector {
+	rows, _ := d.Dims()
+	return d.ProdInto(Vec(rows), v)
+}
+
+// ProdInto writes the product of the matrix with the given vector into dst.
+func (d Dense) ProdInto(dst, v Vector) Vector {
+	rows, cols := d.Dims()
+	MustHaveSize(v, cols)
+	MustHaveSize(dst, rows)
+	d.prod(dst, v, workers(rows, rows*cols, parallelThreshold))
+	return dst
+}
+
+// prod writes the product of the matrix with the given vector into dst, split across the given number of workers.
+func (d Dense) prod(dst, v Vector, workers int) {
+	rows, _ := d.Dims()
+	if workers > 1 {
+		parallel(rows, workers, func(from, to int) {
+			d.prodInto(dst, v, from, to)
+		})
+	} else {
+		d.prodInto(dst, v, 0, rows)
+	}
+}
+
+// prodInto writes the elements [from,to) of the product of the matrix with the given vector into dst.
+func (d Dense) prodInto(dst, v Vector, from, to int) {
+	_, cols := d.Dims()
+	if !d.transposed {
+		for i := from; i < to; i++ {
+			dst[i] = dot(d.data[i*d.stride:i*d.stride+cols], v)
+		}
+		return
+	}
+	// for the transpose we walk through the underlying rows,
+	// accumulating each one scaled by the corresponding vector element.
+	// Each goroutine owns a range of the output, so that there is no contention.
+	dst[from:to].Zero()
+	for j := 0; j < cols; j++ {
+		axpy(v[j], d.data[j*d.stride+from:j*d.stride+to], dst[from:to])
+	}
+}
+
+// Add returns the element-wise addition of the two matrices.
+func (d Dense) Add(e Dense) Dense {
+	return d.Dop(func(x, y float64) float64 {
+		return x + y
+	}, e)
+}
+
+// Mult multiplies each element of the matrix with the given factor.
+func (d Dense) Mult(s float64) Dense {
+	return d.Op(Scale(s))
+}
+
+// Op applies to each of the elements a specific function.
+func (d Dense) Op(transform Op) Dense {
+	c := d.Copy()
+	for i := range c.data {
+		c.data[i] = transform(c.data[i])
+	}
+	return c
+}
+
+// Dop applies to each pair of corresponding elements a specific function.
+func (d Dense) Dop(transform Dop, e Dense) Dense {
+	MustHaveSameDims(d, e)
+	c := d.Copy()
+	rows, cols := c.Dims()
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			c.data[i*c.stride+j] = transform(c.data[i*c.stride+j], e.At(i, j))
+		}
+	}
+	return c
+}
+
+// AddInPlace adds e to the matrix, mutating the underlying data.
+func (d Dense) AddInPlace(e Dense) Dense {
+	MustHaveSameDims(d, e)
+	rows, cols := d.Dims()
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			d.data[d.index(i, j)] += e.At(i, j)
+		}
+	}
+	return d
+}
+
+// ScaleInPlace multiplies each element of the matrix with s, mutating the underlying data.
+func (d Dense) ScaleInPlace(s float64) Dense {
+	return d.OpInPlace(Scale(s))
+}
+
+// OpInPlace applies the transform to each of the elements, mutating the underlying data.
+func (d Dense) OpInPlace(transform Op) Dense {
+	rows, cols := d.Dims()
+	if d.transposed {
+		rows, cols = cols, rows
+	}
+	for i := 0; i < rows; i++ {
+		row := d.data[i*d.stride : i*d.stride+cols]
+		for j := range row {
+			row[j] = transform(row[j])
+		}
+	}
+	return d
+}
+
+// AddOuterInPlace adds the outer product of v and w scaled by s to the matrix, mutating the underlying data.
+// It is equivalent to d.AddInPlace(Outer(v, w).Mult(s)) without the intermediate allocations.
+func (d Dense) AddOuterInPlace(s float64, v, w Vector) Dense {
+	rows, cols := d.Dims()
+	MustHaveSize(v, rows)
+	MustHaveSize(w, cols)
+	if d.transposed {
+		return d.T().AddOuterInPlace(s, w, v).T()
+	}
+	for i := range v {
+		row := d.data[i*d.stride : i*d.stride+cols]
+		sv := s * v[i]
+		for j, x := range w {
+			row[j] += sv * x
+		}
+	}
+	return d
+}
+
+// Outer returns the outer product of the given vectors as a Dense matrix.
+func Outer(v, w Vector) Dense {
+	d := NewDense(len(v), len(w))
+	for i := range v {
+		row := d.data[i*d.stride : i*d.stride+len(w)]
+		for j := range w {
+			row[j] = v[i] * w[j]
+		}
+	}
+	return d
+}
+
+// String prints the matrix in an easily readable form.
+func (d Dense) String() string {
+	rows, cols := d.Dims()
+	builder := strings.Builder{}
+	builder.WriteString(fmt.Sprintf("(%d,%d)", rows, cols))
+	builder.WriteString("\n")
+	for i := 0; i < rows; i++ {
+		builder.WriteString("\t")
+		builder.WriteString(fmt.Sprintf("[%d]", i))
+		builder.WriteString(fmt.Sprintf("%v", d.Row(i)))
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
+
+// MustHaveSameDims verifies that the given matrices have the same dimensions.
+func MustHaveSameDims(d, e Dense) {
+	dr, dc := d.Dims()
+	er, ec := e.Dims()
+	if dr != er || dc != ec {
+		panic(fmt.Sprintf("matrices must have the same dimensions '%dx%d' vs '%dx%d'", dr, dc, er, ec))
+	}
+}
@@ -0,0 +1,101 @@
+package xmath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testMatrix() Matrix {
+	return Mat(3).With(
+		Vec(2).With(11, 12),
+		Vec(2).With(21, 22),
+		Vec(2).With(31, 32),
+	)
+}
+
+func TestDense_Conversion(t *testing.T) {
+
+	m := testMatrix()
+	d := DenseOf(m)
+
+	rows, cols := d.Dims()
+	assert.Equal(t, 3, rows)
+	assert.Equal(t, 2, cols)
+	assert.Equal(t, m, d.Matrix())
+
+	for i := range m {
+		assert.Equal(t, m[i], d.Row(i))
+		for j := range m[i] {
+			assert.Equal(t, m[i][j], d.At(i, j))
+		}
+	}
+
+	// rows share the same data
+	d.Row(1)[1] = 0
+	assert.Equal(t, 0.0, d.At(1, 1))
+
+}
+
+func TestDense_T(t *testing.T) {
+
+	m := testMatrix()
+	d := DenseOf(m)
+	dT := d.T()
+
+	rows, cols := dT.Dims()
+	assert.Equal(t, 2, rows)
+	assert.Equal(t, 3, cols)
+	assert.Equal(t, m.T(), dT.Matrix())
+	assert.Equal(t, d, dT.T())
+
+	// copying a transposed view creates a contiguous matrix
+	assert.Equal(t, DenseOf(m.T()).Matrix(), dT.Copy().Matrix())
+
+	// the transpose is a view on the same data
+	dT.Set(0, 2, 0)
+	assert.Equal(t, 0.0, d.At(2, 0))
+
+}
+
+func TestDense_Prod(t *testing.T) {
+
+	m := testMatrix()
+	d := DenseOf(m)
+
+	v := Vec(2).With(1, 2)
+	assert.Equal(t, m.Prod(v), d.Prod(v))
+
+	w := Vec(3).With(1, 2, 3)
+	assert.Equal(t, m.T().Prod(w), d.T().Prod(w))
+
+	assert.Panics(t, func() {
+		d.Prod(w)
+	})
+
+}
+
+func TestDense_Ops(t *testing.T) {
+
+	m := testMatrix()
+	d := DenseOf(m)
+
+	assert.Equal(t, m.Add(m), d.Add(d).Matrix())
+	assert.Equal(t, m.Mult(2), d.Mult(2).Matrix())
+	assert.Equal(t, m.Op(Sqrt), d.Op(Sqrt).Matrix())
+	assert.Equal(t, m.T().Add(m.T()), d.T().Add(d.T()).Matrix())
+	// the initial matrix should be left untouched
+	assert.Equal(t, m, d.Matrix())
+
+	v := Vec(2).With(1, 2)
+	w := Vec(3).With(1, 2, 3)
+	assert.Equal(t, v.Prod(w), Outer(v, w).Matrix())
+
+	assert.Panics(t, func() {
+		d.Add(d.T())
+	})
+
+	g := NewDense(2, 3).Generate(Const(1))
+	assert.Equal(t, Mat(2).Of(3).Generate(3, Const(1)), g.Matrix())
+
+}
@@ -0,0 +1,165 @@
+package xmath
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// maxSweeps is the maximum number of sweeps for the jacobi iterations, which typically converge in less than 10.
+const maxSweeps = 100
+
+// Eigen returns the eigenvalues and eigenvectors of the symmetric matrix, using the jacobi eigenvalue algorithm.
+// The eigenvalues are sorted in decreasing order, and the eigenvectors are returned as the rows of a matrix,
+// with the unit eigenvector of each eigenvalue at the same index.
+func (m Matrix) Eigen() (values Vector, vectors Matrix, err error) {
+	n, err := square(m)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not decompose matrix: %w", err)
+	}
+	if !m.symmetric() {
+		return nil, nil, ErrNotSymmetric
+	}
+	a := m.Copy()
+	// v accumulates the rotations, holding the eigenvectors in its rows
+	v := Identity(n)
+	eps := tolerance * maxAbs(m)
+	for sweep := 0; sweep < maxSweeps; sweep++ {
+		var off float64
+		for p := 0; p < n; p++ {
+			for q := p + 1; q < n; q++ {
+				off += a[p][q] * a[p][q]
+			}
+		}
+		if math.Sqrt(off) <= eps {
+			break
+		}
+		for p := 0; p < n; p++ {
+			for q := p + 1; q < n; q++ {
+				if a[p][q] == 0 {
+					continue
+				}
+				// find the rotation that zeroes a[p][q]
+				theta := (a[q][q] - a[p][p]) / (2 * a[p][q])
+				t := 1 / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				if theta < 0 {
+					t = -t
+				}
+				c := 1 / math.Sqrt(t*t+1)
+				s := t * c
+				for k := 0; k < n; k++ {
+					akp, akq := a[k][p], a[k][q]
+					a[k][p] = c*akp - s*akq
+					a[k][q] = s*akp + c*akq
+				}
+				for k := 0; k < n; k++ {
+					apk, aqk := a[p][k], a[q][k]
+					a[p][k] = c*apk - s*aqk
+					a[q][k] = s*apk + c*aqk
+				}
+				rotate(v[p], v[q], c, s)
+			}
+		}
+	}
+	values = Vec(n)
+	vectors = Mat(n)
+	for i, j := range descending(a.diagonal()) {
+		values[i] = a[j][j]
+		vectors[i] = v[j]
+	}
+	return values, vectors, nil
+}
+
+// rotate applies the givens rotation to the pair of vectors.
+func rotate(x, y Vector, c, s float64) {
+	for k := range x {
+		xk, yk := x[k], y[k]
+		x[k] = c*xk - s*yk
+		y[k] = s*xk + c*yk
+	}
+}
+
+// descending returns the indices of the values in decreasing order of the values.
+func descending(values Vector) []int {
+	idx := make([]int, len(values))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool {
+		return values[idx[i]] > values[idx[j]]
+	})
+	return idx
+}
+
+// SVD returns the thin singular value decomposition of the matrix e.g. A = U*diag(s)*V^T,
+// using the one-sided jacobi algorithm.
+// For an m x n matrix and k = min(m,n), U is m x k, s has size k and V is n x k.
+// The singular values are sorted in decreasing order.
+func (m Matrix) SVD() (u Matrix, s Vector, v Matrix, err error) {
+	rows, cols, err := dims(m)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("could not decompose matrix: %w", err)
+	}
+	if rows < cols {
+		// decompose the transpose and swap the factors
+		v, s, u, err = m.T().SVD()
+		return u, s, v, err
+	}
+	// work on the columns of the matrix, as the rows of its transpose
+	a := m.T()
+	vt := Identity(cols)
+	eps := tolerance * tolerance
+	for sweep := 0; sweep < maxSweeps; sweep++ {
+		rotated := false
+		for p := 0; p < cols; p++ {
+			for q := p + 1; q < cols; q++ {
+				alpha := a[p].Dot(a[p])
+				beta := a[q].Dot(a[q])
+				gamma := a[p].Dot(a[q])
+				if gamma == 0 || gamma*gamma <= eps*alpha*beta {
+					continue
+				}
+				rotated = true
+				zeta := (beta - alpha) / (2 * gamma)
+				t := 1 / (math.Abs(zeta) + math.Sqrt(1+zeta*zeta))
+				if zeta < 0 {
+					t = -t
+				}
+				c := 1 / math.Sqrt(1+t*t)
+				rotate(a[p], a[q], c, c*t)
+				rotate(vt[p], vt[q], c, c*t)
+			}
+		}
+		if !rotated {
+			break
+		}
+	}
+	norms := Vec(cols)
+	for j := range norms {
+		norms[j] = a[j].Norm()
+	}
+	u = Mat(rows).Of(cols)
+	s = Vec(cols)
+	v = Mat(cols).Of(cols)
+	for i, j := range descending(norms) {
+		s[i] = norms[j]
+		for r := 0; r < rows; r++ {
+			if s[i] > 0 {
+				u[r][i] = a[j][r] / s[i]
+			}
+		}
+		for r := 0; r < cols; r++ {
+			v[r][i] = vt[j][r]
+		}
+	}
+	return u, s, v, nil
+}
+
+// diagonal returns the diagonal elements of the square matrix.
+func (m Matrix) diagonal() Vector {
+	d := Vec(len(m))
+	for i := range m {
+		d[i] = m[i][i]
+	}
+	return d
+}
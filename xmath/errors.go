@@ -0,0 +1,96 @@
+package xmath
+
+import (
+	"fmt"
+	"math"
+)
+
+// The Must* checks panic on invalid input, which is fine for programming errors within a computation.
+// At the boundaries, where the input comes from outside e.g. a malformed record,
+// the Expect* checks return typed errors instead, that carry the location of the failure.
+
+// ShapeError is returned when the dimensions of the arguments of an operation do not match.
+type ShapeError struct {
+	// Location describes where the mismatch occurred e.g. the operation or argument name.
+	Location string
+	Expected []int
+	Actual   []int
+}
+
+// Error describes the shape mismatch.
+func (e *ShapeError) Error() string {
+	return fmt.Sprintf("%s: expected shape %v but got %v", e.Location, e.Expected, e.Actual)
+}
+
+// NaNError is returned when an element is not a valid number e.g. NaN or Inf.
+type NaNError struct {
+	// Location describes where the invalid number was found e.g. the operation or argument name.
+	Location string
+	// Index is the index of the element within its vector or matrix.
+	Index []int
+	Value float64
+}
+
+// Error describes the invalid number and its position.
+func (e *NaNError) Error() string {
+	return fmt.Sprintf("%s: invalid number %v at %v", e.Location, e.Value, e.Index)
+}
+
+// ExpectSize returns a ShapeError if the vector does not have the given size.
+func ExpectSize(location string, v Vector, n int) error {
+	if len(v) != n {
+		return &ShapeError{
+			Location: location,
+			Expected: []int{n},
+			Actual:   []int{len(v)},
+		}
+	}
+	return nil
+}
+
+// ExpectSameSize returns a ShapeError if the given vectors are not of the same size.
+func ExpectSameSize(location string, v, w Vector) error {
+	return ExpectSize(location, w, len(v))
+}
+
+// ExpectDim returns a ShapeError if the matrix does not have the given primary dimension.
+func ExpectDim(location string, m Matrix, n int) error {
+	if len(m) != n {
+		return &ShapeError{
+			Location: location,
+			Expected: []int{n},
+			Actual:   []int{len(m)},
+		}
+	}
+	return nil
+}
+
+// ExpectValid returns a NaNError for the first element of the vector that is not a valid number.
+func ExpectValid(location string, v Vector) error {
+	for i, x := range v {
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			return &NaNError{
+				Location: location,
+				Index:    []int{i},
+				Value:    x,
+			}
+		}
+	}
+	return nil
+}
+
+// ExpectValidMatrix returns a NaNError for the first element of the matrix that is not a valid number.
+func ExpectValidMatrix(location string, m Matrix) error {
+	for i := range m {
+		for j, x := range m[i] {
+			if math.IsNaN(x) || math.IsInf(x, 0) {
+				return &NaNError{
+					Location: location,
+					Index:    []int{i, j},
+					Value:    x,
+				}
+			}
+		}
+	}
+	return nil
+}
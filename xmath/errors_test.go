@@ -0,0 +1,76 @@
+package xmath
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpect(t *testing.T) {
+
+	type test struct {
+		err   error
+		shape *ShapeError
+		nan   *NaNError
+	}
+
+	tests := map[string]test{
+		"size": {
+			err: ExpectSize("input", Vec(2), 2),
+		},
+		"size-mismatch": {
+			err:   ExpectSize("input", Vec(2), 3),
+			shape: &ShapeError{Location: "input", Expected: []int{3}, Actual: []int{2}},
+		},
+		"same-size-mismatch": {
+			err:   ExpectSameSize("diff", Vec(2), Vec(1)),
+			shape: &ShapeError{Location: "diff", Expected: []int{2}, Actual: []int{1}},
+		},
+		"dim-mismatch": {
+			err:   ExpectDim("batch", Mat(2), 4),
+			shape: &ShapeError{Location: "batch", Expected: []int{4}, Actual: []int{2}},
+		},
+		"valid": {
+			err: ExpectValid("input", Vec(2).With(1, 2)),
+		},
+		"nan": {
+			err: ExpectValid("input", Vec(3).With(1, 2, math.NaN())),
+			nan: &NaNError{Location: "input", Index: []int{2}, Value: math.NaN()},
+		},
+		"inf-in-matrix": {
+			err: ExpectValidMatrix("batch", Mat(2).With(Vec(1), Vec(2).With(0, math.Inf(-1)))),
+			nan: &NaNError{Location: "batch", Index: []int{1, 1}, Value: math.Inf(-1)},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// wrapping should preserve the error type
+			err := fmt.Errorf("wrapped: %w", tt.err)
+			var shapeErr *ShapeError
+			var nanErr *NaNError
+			switch {
+			case tt.shape != nil:
+				assert.True(t, errors.As(err, &shapeErr))
+				assert.Equal(t, tt.shape, shapeErr)
+			case tt.nan != nil:
+				assert.True(t, errors.As(err, &nanErr))
+				assert.Equal(t, tt.nan.Location, nanErr.Location)
+				assert.Equal(t, tt.nan.Index, nanErr.Index)
+				assert.Equal(t, tt.nan.Value == tt.nan.Value, nanErr.Value == nanErr.Value)
+			default:
+				assert.NoError(t, tt.err)
+			}
+		})
+	}
+
+	assert.Equal(t, "input: expected shape [3] but got [2]", ExpectSize("input", Vec(2), 3).Error())
+
+	_, err := Mat(2).Of(3).LU()
+	var shapeErr *ShapeError
+	assert.True(t, errors.As(err, &shapeErr))
+
+}
@@ -0,0 +1,396 @@
+package forecast
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/drakos74/go-ex-machina/xmath"
+)
+
+// ARIMA is an autoregressive integrated moving average model.
+// The series is differenced d times, and the differences follow
+// w[t] = c + phi[0]*w[t-1] + ... + phi[p-1]*w[t-p] + e[t] + theta[0]*e[t-1] + ... + theta[q-1]*e[t-q]
+// where e are the one-step-ahead errors.
+type ARIMA struct {
+	p, d, q int
+	c       float64
+	phi     xmath.Vector
+	theta   xmath.Vector
+	sigma2  float64
+	fitted  bool
+	// t is the number of values pushed
+	t int
+	// levels are the last values of the series differenced 0 up to d-1 times
+	levels xmath.Vector
+	// w and e are the last differences and errors needed for the next prediction
+	w, e xmath.Vector
+	// warm is the number of differences before the errors count towards the variance
+	warm int
+	// n is the number of errors after the warmup, and sse the sum of their squares
+	n   int
+	sse float64
+}
+
+// NewAR creates an autoregressive model of order p.
+func NewAR(p int) *ARIMA {
+	return NewARIMA(p, 0, 0)
+}
+
+// NewMA creates a moving average model of order q.
+func NewMA(q int) *ARIMA {
+	return NewARIMA(0, 0, q)
+}
+
+// NewARIMA creates an ARIMA model with p autoregressive terms, d differences and q moving average terms.
+func NewARIMA(p, d, q int) *ARIMA {
+	if p < 0 || d < 0 || q < 0 {
+		panic(fmt.Sprintf("arima orders must not be negative '(%v,%v,%v)'", p, d, q))
+	}
+	m := &ARIMA{
+		p: p,
+		d: d,
+		q: q,
+	}
+	m.reset()
+	return m
+}
+
+// reset clears the state of the model, keeping its coefficients.
+func (m *ARIMA) reset() {
+	m.t = 0
+	m.levels = xmath.Vec(m.d)
+	m.w = xmath.Vec(0)
+	// the errors before the first prediction are taken as 0
+	m.e = xmath.Vec(m.q)
+	m.n = 0
+	m.sse = 0
+}
+
+// Order returns the number of autoregressive terms, differences and moving average terms.
+func (m *ARIMA) Order() (p, d, q int) {
+	return m.p, m.d, m.q
+}
+
+// Constant returns the constant term of the differenced series.
+func (m *ARIMA) Constant() float64 {
+	return m.c
+}
+
+// AR returns the autoregressive coefficients.
+func (m *ARIMA) AR() xmath.Vector {
+	return m.phi
+}
+
+// MA returns the moving average coefficients.
+func (m *ARIMA) MA() xmath.Vector {
+	return m.theta
+}
+
+// Variance returns the variance of the one-step-ahead errors of the fit.
+func (m *ARIMA) Variance() float64 {
+	return m.sigma2
+}
+
+// longOrder returns the order of the long autoregression used to estimate the errors of a moving average model.
+func (m *ARIMA) longOrder(n int) int {
+	long := m.p + m.q + 10
+	if long > n/4 {
+		long = n / 4
+	}
+	if long < m.p+m.q {
+		long = m.p + m.q
+	}
+	return long
+}
+
+// warmup returns the number of differences the least squares fit starts after, for a differenced series of size n.
+func (m *ARIMA) warmup(n int) int {
+	if m.q > 0 {
+		return m.longOrder(n) + m.q
+	}
+	return m.p
+}
+
+// Fit resets the model and fits it on the series with the Hannan-Rissanen method.
+// The errors of a moving average model are estimated with a long autoregression fitted with the Yule-Walker equations,
+// and the coefficients are then found with least squares.
+// It returns the sum of squared one-step-ahead errors, after the model has warmed up.
+func (m *ARIMA) Fit(data xmath.Vector) (float64, error) {
+	return m.fit(data, m.p)
+}
+
+// fit fits the model on the series, and counts the one-step-ahead errors after the given number of differences.
+func (m *ARIMA) fit(data xmath.Vector, warm int) (float64, error) {
+	if err := xmath.ExpectValid("series", data); err != nil {
+		return 0, err
+	}
+	w := Diff(data, m.d)
+	k := 1 + m.p + m.q
+
+	start := m.warmup(len(w))
+	e := xmath.Vec(len(w))
+	if m.q > 0 {
+		long := m.longOrder(len(w))
+		phi, _, err := YuleWalker(w, long)
+		if err != nil {
+			return 0, fmt.Errorf("could not estimate errors of MA(%d) model: %w", m.q, err)
+		}
+		c := mean(w) * (1 - sum(phi))
+		for t := long; t < len(w); t++ {
+			e[t] = w[t] - c - phi.Dot(reverse(w[t-long:t]))
+		}
+	}
+
+	rows := len(w) - start
+	if rows < k {
+		return 0, fmt.Errorf("could not fit ARIMA(%d,%d,%d) model on %d values", m.p, m.d, m.q, len(data))
+	}
+	a := xmath.Mat(rows)
+	b := xmath.Vec(rows)
+	for i := range a {
+		t := start + i
+		row := xmath.Vec(k)
+		row[0] = 1
+		copy(row[1:], reverse(w[t-m.p:t]))
+		copy(row[1+m.p:], reverse(e[t-m.q:t]))
+		a[i] = row
+		b[i] = w[t]
+	}
+	x, err := xmath.LeastSquares(a, b)
+	if err != nil {
+		return 0, fmt.Errorf("could not fit ARIMA(%d,%d,%d) model: %w", m.p, m.d, m.q, err)
+	}
+	m.c = x[0]
+	m.phi = x[1 : 1+m.p].Copy()
+	m.theta = x[1+m.p:].Copy()
+	m.fitted = true
+	m.warm = warm
+
+	m.reset()
+	for _, v := range data {
+		m.Push(v)
+	}
+	if m.n > 0 {
+		m.sigma2 = m.sse / float64(m.n)
+	}
+	return m.sse, nil
+}
+
+// Push updates the model with the next value of the series.
+// The first d values are needed for the differences, and the next p ones for the autoregression,
+// before the model can start predicting.
+func (m *ARIMA) Push(v float64) {
+	w := v
+	for k := 0; k < m.d; k++ {
+		prev := m.levels[k]
+		m.levels[k] = w
+		if m.t <= k {
+			m.t++
+			return
+		}
+		w -= prev
+	}
+	m.t++
+
+	var e float64
+	if m.fitted && len(m.w) >= m.p {
+		e = w - m.predict(m.w, m.e)
+		if m.t-m.d > m.warm {
+			m.sse += e * e
+			m.n++
+		}
+	}
+	m.w = last(append(m.w, w), m.p)
+	m.e = last(append(m.e, e), m.q)
+}
+
+// predict returns the next difference out of the last differences and errors.
+func (m *ARIMA) predict(w, e xmath.Vector) float64 {
+	y := m.c
+	for i, phi := range m.phi {
+		y += phi * w[len(w)-1-i]
+	}
+	for j, theta := range m.theta {
+		y += theta * e[len(e)-1-j]
+	}
+	return y
+}
+
+// ready returns true if the model is fitted and has enough values to predict.
+func (m *ARIMA) ready() bool {
+	return m.fitted && m.t > m.d && len(m.w) >= m.p
+}
+
+// Forecast returns the predictions for the given number of steps after the last value.
+// The future errors are taken as 0, so that the moving average terms fade out after q steps.
+// The predictions are NaN until the model is fitted and has enough values.
+func (m *ARIMA) Forecast(steps int) xmath.Vector {
+	if !m.ready() {
+		return nan(steps)
+	}
+	w := m.w.Copy()
+	e := m.e.Copy()
+	levels := m.levels.Copy()
+	f := xmath.Vec(steps)
+	for h := range f {
+		y := m.predict(w, e)
+		w = last(append(w, y), m.p)
+		e = last(append(e, 0), m.q)
+		// integrate the difference back to the series
+		for k := m.d - 1; k >= 0; k-- {
+			levels[k] += y
+			y = levels[k]
+		}
+		f[h] = y
+	}
+	return f
+}
+
+// psi returns the weights of the past errors in the prediction error of the series for each step ahead.
+func (m *ARIMA) psi(steps int) xmath.Vector {
+	// the autoregressive polynomial of the series e.g. (1 - phi(B)) * (1 - B)^d
+	poly := append(xmath.Vector{1}, m.phi.Mult(-1)...)
+	for k := 0; k < m.d; k++ {
+		next := xmath.Vec(len(poly) + 1)
+		for i, a := range poly {
+			next[i] += a
+			next[i+1] -= a
+		}
+		poly = next
+	}
+	psi := xmath.Vec(steps)
+	if steps == 0 {
+		return psi
+	}
+	psi[0] = 1
+	for j := 1; j < steps; j++ {
+		if j <= m.q {
+			psi[j] = m.theta[j-1]
+		}
+		for i := 1; i < len(poly) && i <= j; i++ {
+			psi[j] -= poly[i] * psi[j-i]
+		}
+	}
+	return psi
+}
+
+// Interval returns the predictions for the given number of steps after the last value,
+// together with the bounds of the interval that contains the values with the given confidence e.g. 0.95,
+// assuming normally distributed errors.
+func (m *ARIMA) Interval(steps int, confidence float64) (forecast, lower, upper xmath.Vector) {
+	if confidence <= 0 || confidence >= 1 {
+		panic(fmt.Sprintf("confidence must be within (0,1) '%v'", confidence))
+	}
+	forecast = m.Forecast(steps)
+	lower = xmath.Vec(steps)
+	upper = xmath.Vec(steps)
+	z := math.Sqrt2 * math.Erfinv(confidence)
+	var variance float64
+	for h, psi := range m.psi(steps) {
+		variance += m.sigma2 * psi * psi
+		width := z * math.Sqrt(variance)
+		lower[h] = forecast[h] - width
+		upper[h] = forecast[h] + width
+	}
+	return forecast, lower, upper
+}
+
+// logLikelihood returns the gaussian log likelihood of the errors of the fit.
+// The variance is kept above 0, so that a perfect fit has a finite likelihood and the models are still compared on their size.
+func (m *ARIMA) logLikelihood() float64 {
+	n := float64(m.n)
+	sigma2 := math.Max(m.sigma2, math.SmallestNonzeroFloat64)
+	return -n / 2 * (math.Log(2*math.Pi*sigma2) + 1)
+}
+
+// params returns the number of estimated parameters e.g. the coefficients, the constant and the variance.
+func (m *ARIMA) params() float64 {
+	return float64(m.p + m.q + 2)
+}
+
+// AIC returns the Akaike information criterion of the fit.
+func (m *ARIMA) AIC() float64 {
+	return -2*m.logLikelihood() + 2*m.params()
+}
+
+// BIC returns the Bayesian information criterion of the fit, which penalises the model size more than the AIC.
+func (m *ARIMA) BIC() float64 {
+	return -2*m.logLikelihood() + m.params()*math.Log(float64(m.n))
+}
+
+// Criterion scores a fitted model, where lower is better e.g. (*ARIMA).AIC or (*ARIMA).BIC.
+type Criterion func(m *ARIMA) float64
+
+// Select fits the ARIMA models with up to maxP autoregressive and maxQ moving average terms on the series,
+// and returns the one with the best criterion.
+// As the differencing changes the series the criteria are computed on, d is fixed.
+// The criteria of all models are computed on the same errors, after the longest warmup of the models,
+// so that they are comparable regardless of the order.
+func Select(data xmath.Vector, maxP, d, maxQ int, criterion Criterion) (*ARIMA, error) {
+	warm := commonWarmup(len(Diff(data, d)), maxP, d, maxQ)
+
+	var best *ARIMA
+	score := math.Inf(1)
+	var err error
+	for p := 0; p <= maxP; p++ {
+		for q := 0; q <= maxQ; q++ {
+			m := NewARIMA(p, d, q)
+			if _, fitErr := m.fit(data, warm); fitErr != nil {
+				err = fitErr
+				continue
+			}
+			if m.n == 0 {
+				err = fmt.Errorf("could not evaluate ARIMA(%d,%d,%d) model on %d values", p, d, q, len(data))
+				continue
+			}
+			if s := criterion(m); best == nil || s < score {
+				best = m
+				score = s
+			}
+		}
+	}
+	if best == nil {
+		return nil, fmt.Errorf("could not fit any ARIMA model: %w", err)
+	}
+	return best, nil
+}
+
+// commonWarmup returns the longest warmup of the models with up to maxP autoregressive and maxQ moving average terms,
+// for a differenced series of size n.
+func commonWarmup(n, maxP, d, maxQ int) int {
+	var warm int
+	for p := 0; p <= maxP; p++ {
+		for q := 0; q <= maxQ; q++ {
+			if w := NewARIMA(p, d, q).warmup(n); w > warm {
+				warm = w
+			}
+		}
+	}
+	return warm
+}
+
+// reverse returns a copy of the values in reverse order e.g. the most recent first.
+func reverse(v xmath.Vector) xmath.Vector {
+	r := xmath.Vec(len(v))
+	for i, x := range v {
+		r[len(v)-1-i] = x
+	}
+	return r
+}
+
+// last returns the last n values.
+func last(v xmath.Vector, n int) xmath.Vector {
+	if len(v) <= n {
+		return v
+	}
+	return v[len(v)-n:]
+}
+
+// sum returns the sum of the values.
+func sum(v xmath.Vector) float64 {
+	var s float64
+	for _, x := range v {
+		s += x
+	}
+	return s
+}
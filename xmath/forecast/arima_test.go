@@ -0,0 +1,222 @@
+package forecast
+
+import (
+	"math"
+	"testing"
+
+	"github.com/drakos74/go-ex-machina/xmath"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestARIMA_Fit(t *testing.T) {
+
+	type test struct {
+		model *ARIMA
+		data  xmath.Vector
+		c     float64
+		phi   []float64
+		theta []float64
+	}
+
+	tests := map[string]test{
+		"ar": {
+			model: NewAR(2),
+			data:  simulate(3000, 1, []float64{0.6, -0.3}, nil, 3),
+			c:     1,
+			phi:   []float64{0.6, -0.3},
+			theta: []float64{},
+		},
+		"ma": {
+			model: NewMA(1),
+			data:  simulate(3000, 2, nil, []float64{0.5}, 4),
+			c:     2,
+			phi:   []float64{},
+			theta: []float64{0.5},
+		},
+		"arma": {
+			model: NewARIMA(1, 0, 1),
+			data:  simulate(3000, 0, []float64{0.5}, []float64{0.4}, 5),
+			phi:   []float64{0.5},
+			theta: []float64{0.4},
+		},
+		"arima": {
+			model: NewARIMA(1, 1, 0),
+			data:  integrate(simulate(3000, 0.5, []float64{0.4}, nil, 6)),
+			c:     0.5,
+			phi:   []float64{0.4},
+			theta: []float64{},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			sse, err := tt.model.Fit(tt.data)
+			assert.NoError(t, err)
+			assert.True(t, sse > 0)
+			assert.InDelta(t, tt.c, tt.model.Constant(), 0.15)
+			assert.Equal(t, len(tt.phi), len(tt.model.AR()))
+			for i, phi := range tt.phi {
+				assert.InDelta(t, phi, tt.model.AR()[i], 0.1)
+			}
+			assert.Equal(t, len(tt.theta), len(tt.model.MA()))
+			for j, theta := range tt.theta {
+				assert.InDelta(t, theta, tt.model.MA()[j], 0.1)
+			}
+			// the noise has unit variance
+			assert.InDelta(t, 1, tt.model.Variance(), 0.1)
+		})
+	}
+
+}
+
+// integrate returns the cumulative sums of the series.
+func integrate(data xmath.Vector) xmath.Vector {
+	y := xmath.Vec(len(data))
+	var s float64
+	for t, w := range data {
+		s += w
+		y[t] = s
+	}
+	return y
+}
+
+func TestARIMA_Forecast(t *testing.T) {
+
+	type test struct {
+		model  *ARIMA
+		series func(t int) float64
+	}
+
+	tests := map[string]test{
+		"drift": {
+			model: NewARIMA(0, 1, 0),
+			series: func(t int) float64 {
+				return 3*float64(t) + 1
+			},
+		},
+		"quadratic": {
+			model: NewARIMA(0, 2, 0),
+			series: func(t int) float64 {
+				return float64(t * t)
+			},
+		},
+		"decay": {
+			model: NewAR(1),
+			series: func(t int) float64 {
+				return 2 + math.Pow(0.5, float64(t))
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			for _, f := range tt.model.Forecast(2) {
+				assert.True(t, math.IsNaN(f))
+			}
+			_, err := tt.model.Fit(generate(30, tt.series))
+			assert.NoError(t, err)
+			forecast := tt.model.Forecast(5)
+			for h, f := range forecast {
+				assert.InDelta(t, tt.series(30+h), f, 1e-6)
+			}
+			// pushing the next value moves the forecast along
+			tt.model.Push(tt.series(30))
+			assert.InDelta(t, tt.series(31), tt.model.Forecast(1)[0], 1e-6)
+		})
+	}
+
+}
+
+func TestARIMA_Interval(t *testing.T) {
+
+	// a random walk has a forecast error variance growing linearly with the steps
+	model := NewARIMA(0, 1, 0)
+	_, err := model.Fit(integrate(simulate(1000, 0, nil, nil, 7)))
+	assert.NoError(t, err)
+
+	forecast, lower, upper := model.Interval(9, 0.95)
+	width := 1.959964 * math.Sqrt(model.Variance())
+	for h := range forecast {
+		assert.InDelta(t, forecast[h]-lower[h], upper[h]-forecast[h], 1e-9)
+		assert.InDelta(t, width*math.Sqrt(float64(h+1)), upper[h]-forecast[h], 1e-5)
+	}
+
+	// an AR(1) forecast error variance converges to the variance of the process
+	model = NewAR(1)
+	_, err = model.Fit(simulate(3000, 0, []float64{0.5}, nil, 8))
+	assert.NoError(t, err)
+	forecast, _, upper = model.Interval(50, 0.95)
+	phi := model.AR()[0]
+	assert.InDelta(t, 1.959964*math.Sqrt(model.Variance()/(1-phi*phi)), upper[49]-forecast[49], 1e-5)
+
+}
+
+func TestSelect(t *testing.T) {
+
+	data := simulate(2000, 0, []float64{0.6, -0.3}, nil, 9)
+
+	model, err := Select(data, 3, 0, 2, (*ARIMA).BIC)
+	assert.NoError(t, err)
+	p, d, q := model.Order()
+	assert.Equal(t, 2, p)
+	assert.Equal(t, 0, d)
+	assert.Equal(t, 0, q)
+
+	// the aic penalises less the bigger models, but still needs the autoregressive terms
+	model, err = Select(data, 3, 0, 2, (*ARIMA).AIC)
+	assert.NoError(t, err)
+	p, _, _ = model.Order()
+	assert.True(t, p >= 2)
+	ar := NewAR(2)
+	_, err = ar.fit(data, commonWarmup(len(data), 3, 0, 2))
+	assert.NoError(t, err)
+	assert.True(t, model.AIC() <= ar.AIC())
+
+	_, err = Select(data[:1], 2, 1, 2, (*ARIMA).AIC)
+	assert.Error(t, err)
+
+}
+
+func TestSelect_CommonSample(t *testing.T) {
+
+	data := simulate(500, 0, []float64{0.6, -0.3}, []float64{0.4}, 3)
+
+	// the moving average models need the longest warmup for the errors of their long autoregression
+	warm := commonWarmup(len(data), 3, 0, 2)
+	assert.Equal(t, NewARIMA(3, 0, 2).warmup(len(data)), warm)
+
+	// all models are evaluated on the same errors, regardless of their order
+	for p := 0; p <= 3; p++ {
+		for q := 0; q <= 2; q++ {
+			m := NewARIMA(p, 0, q)
+			_, err := m.fit(data, warm)
+			assert.NoError(t, err)
+			assert.Equal(t, len(data)-warm, m.n)
+		}
+	}
+
+	// a perfect fit does not get an infinite score
+	m := mustFit(t, NewAR(1), data)
+	m.sigma2 = 0
+	assert.False(t, math.IsInf(m.AIC(), 0))
+	assert.False(t, math.IsInf(m.BIC(), 0))
+	assert.True(t, m.AIC() < mustFit(t, NewAR(1), data).AIC())
+
+}
+
+// mustFit fits the model on the data, failing the test if there is an error.
+func mustFit(t *testing.T, m *ARIMA, data xmath.Vector) *ARIMA {
+	_, err := m.Fit(data)
+	assert.NoError(t, err)
+	return m
+}
+
+func TestARIMA_FitErrors(t *testing.T) {
+
+	_, err := NewARIMA(2, 1, 1).Fit(xmath.Vec(5).With(1, 2, 3, 4, 5))
+	assert.Error(t, err)
+
+	_, err = NewAR(1).Fit(xmath.Vec(5).With(1, 2, math.Inf(1), 4, 5))
+	assert.Error(t, err)
+
+}
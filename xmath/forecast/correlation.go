@@ -0,0 +1,108 @@
+package forecast
+
+import (
+	"fmt"
+
+	"github.com/drakos74/go-ex-machina/xmath"
+)
+
+// Diff returns the series differenced the given number of times e.g. y[t] - y[t-1] for d = 1.
+// Each differencing shortens the series by one value.
+func Diff(data xmath.Vector, d int) xmath.Vector {
+	if d < 0 {
+		panic(fmt.Sprintf("differencing order must be at least '%v' vs '%v'", 0, d))
+	}
+	w := data.Copy()
+	for k := 0; k < d && len(w) > 0; k++ {
+		for t := 0; t < len(w)-1; t++ {
+			w[t] = w[t+1] - w[t]
+		}
+		w = w[:len(w)-1]
+	}
+	return w
+}
+
+// autocovariance returns the autocovariances of the series for the lags from 0 up to the given one.
+func autocovariance(data xmath.Vector, lags int) xmath.Vector {
+	if lags >= len(data) {
+		panic(fmt.Sprintf("lags must be less than the series size '%v' vs '%v'", len(data), lags))
+	}
+	m := mean(data)
+	c := xmath.Vec(lags + 1)
+	for k := range c {
+		for t := k; t < len(data); t++ {
+			c[k] += (data[t] - m) * (data[t-k] - m)
+		}
+		c[k] /= float64(len(data))
+	}
+	return c
+}
+
+// ACF returns the autocorrelations of the series for the lags from 0 up to the given one.
+// The autocorrelation of a constant series is 0 for all lags but the first.
+func ACF(data xmath.Vector, lags int) xmath.Vector {
+	c := autocovariance(data, lags)
+	acf := xmath.Vec(lags + 1)
+	acf[0] = 1
+	if c[0] == 0 {
+		return acf
+	}
+	for k := 1; k <= lags; k++ {
+		acf[k] = c[k] / c[0]
+	}
+	return acf
+}
+
+// PACF returns the partial autocorrelations of the series for the lags from 0 up to the given one,
+// with the Durbin-Levinson recursion.
+// The partial autocorrelation at lag k is the last coefficient of the AR(k) model fitted on the series.
+func PACF(data xmath.Vector, lags int) xmath.Vector {
+	acf := ACF(data, lags)
+	pacf := xmath.Vec(lags + 1)
+	pacf[0] = 1
+	phi := xmath.Vec(0)
+	v := 1.0
+	for k := 1; k <= lags; k++ {
+		num := acf[k]
+		for j, p := range phi {
+			num -= p * acf[k-1-j]
+		}
+		if v <= 0 {
+			break
+		}
+		a := num / v
+		next := xmath.Vec(k)
+		for j := range phi {
+			next[j] = phi[j] - a*phi[k-2-j]
+		}
+		next[k-1] = a
+		phi = next
+		v *= 1 - a*a
+		pacf[k] = a
+	}
+	return pacf
+}
+
+// YuleWalker estimates the coefficients of an AR(p) model out of the autocovariances of the series,
+// together with the variance of its innovations.
+func YuleWalker(data xmath.Vector, p int) (xmath.Vector, float64, error) {
+	if p < 1 || p >= len(data) {
+		return nil, 0, fmt.Errorf("could not estimate AR(%d) model on %d values", p, len(data))
+	}
+	c := autocovariance(data, p)
+	r := xmath.Mat(p).Of(p)
+	for i := range r {
+		for j := range r[i] {
+			k := i - j
+			if k < 0 {
+				k = -k
+			}
+			r[i][j] = c[k]
+		}
+	}
+	phi, err := xmath.Solve(r, c[1:])
+	if err != nil {
+		return nil, 0, fmt.Errorf("could not solve yule-walker equations: %w", err)
+	}
+	return phi, c[0] - phi.Dot(c[1:]), nil
+}
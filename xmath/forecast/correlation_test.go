@@ -0,0 +1,134 @@
+package forecast
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/drakos74/go-ex-machina/xmath"
+	"github.com/stretchr/testify/assert"
+)
+
+// simulate generates an ARMA series with the given constant and coefficients, driven by seeded gaussian noise.
+func simulate(n int, c float64, phi, theta []float64, seed int64) xmath.Vector {
+	rng := rand.New(rand.NewSource(seed))
+	burn := 100
+	y := xmath.Vec(n + burn)
+	e := xmath.Vec(n + burn)
+	for t := range y {
+		e[t] = rng.NormFloat64()
+		y[t] = c + e[t]
+		for i, p := range phi {
+			if t > i {
+				y[t] += p * y[t-1-i]
+			}
+		}
+		for j, q := range theta {
+			if t > j {
+				y[t] += q * e[t-1-j]
+			}
+		}
+	}
+	return y[burn:]
+}
+
+func TestDiff(t *testing.T) {
+
+	type test struct {
+		d    int
+		diff xmath.Vector
+	}
+
+	tests := map[string]test{
+		"none": {
+			d:    0,
+			diff: xmath.Vec(5).With(1, 4, 9, 16, 25),
+		},
+		"first": {
+			d:    1,
+			diff: xmath.Vec(4).With(3, 5, 7, 9),
+		},
+		"second": {
+			d:    2,
+			diff: xmath.Vec(3).With(2, 2, 2),
+		},
+		"all": {
+			d:    6,
+			diff: xmath.Vec(0),
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			data := xmath.Vec(5).With(1, 4, 9, 16, 25)
+			assert.Equal(t, tt.diff, Diff(data, tt.d))
+			// the series is not affected
+			assert.Equal(t, xmath.Vec(5).With(1, 4, 9, 16, 25), data)
+		})
+	}
+
+}
+
+func TestACF(t *testing.T) {
+
+	type test struct {
+		data xmath.Vector
+		acf  xmath.Vector
+	}
+
+	tests := map[string]test{
+		"alternating": {
+			data: xmath.Vec(6).With(1, -1, 1, -1, 1, -1),
+			acf:  xmath.Vec(3).With(1, -5.0/6, 4.0/6),
+		},
+		"constant": {
+			data: xmath.Vec(4).With(2, 2, 2, 2),
+			acf:  xmath.Vec(3).With(1, 0, 0),
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			acf := ACF(tt.data, 2)
+			for k := range acf {
+				assert.InDelta(t, tt.acf[k], acf[k], 1e-9)
+			}
+		})
+	}
+
+}
+
+func TestPACF(t *testing.T) {
+
+	data := simulate(5000, 0, []float64{0.7}, nil, 1)
+
+	acf := ACF(data, 5)
+	pacf := PACF(data, 5)
+
+	assert.Equal(t, 1.0, pacf[0])
+	// the first partial autocorrelation is the first autocorrelation
+	assert.InDelta(t, acf[1], pacf[1], 1e-9)
+	assert.InDelta(t, 0.7, pacf[1], 0.05)
+	// an AR(1) process has no partial autocorrelation after the first lag
+	for k := 2; k <= 5; k++ {
+		assert.InDelta(t, 0, pacf[k], 0.05)
+	}
+
+}
+
+func TestYuleWalker(t *testing.T) {
+
+	data := simulate(5000, 0, []float64{0.6, -0.3}, nil, 2)
+
+	phi, sigma2, err := YuleWalker(data, 2)
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.6, phi[0], 0.05)
+	assert.InDelta(t, -0.3, phi[1], 0.05)
+	assert.InDelta(t, 1, sigma2, 0.1)
+
+	// the last coefficient is the partial autocorrelation
+	assert.InDelta(t, PACF(data, 2)[2], phi[1], 1e-9)
+
+	_, _, err = YuleWalker(data[:2], 2)
+	assert.Error(t, err)
+
+}
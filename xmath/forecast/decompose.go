@@ -0,0 +1,174 @@
+package forecast
+
+import (
+	"fmt"
+
+	"github.com/drakos74/go-ex-machina/xmath"
+)
+
+// Decomposition splits a series into its trend, seasonal and residual components,
+// so that e.g. the de-seasonalised residuals can be fed to a recurrent network.
+// For an additive season the series is the sum of the components, and for a multiplicative one their product.
+type Decomposition struct {
+	Period   int
+	Season   Season
+	Trend    xmath.Vector
+	Seasonal xmath.Vector
+	Residual xmath.Vector
+}
+
+// Decompose splits the regular series into trend, seasonal and residual components, with the classical method.
+// The trend is the centered moving average over a period, extended linearly for the half period at either end,
+// and the seasonal component is the average deviation from the trend at each phase of the period.
+// If the period is 0, it is detected out of the autocorrelation of the series.
+func Decompose(data xmath.Vector, period int, season Season) (*Decomposition, error) {
+	if season == NoSeason {
+		return nil, fmt.Errorf("could not decompose series without an additive or multiplicative season")
+	}
+	if err := xmath.ExpectValid("series", data); err != nil {
+		return nil, err
+	}
+	if period == 0 {
+		p, err := DetectPeriod(data, len(data)/2)
+		if err != nil {
+			return nil, fmt.Errorf("could not detect period: %w", err)
+		}
+		period = p
+	}
+	// the trend needs more than a full period of moving averages
+	if period < 2 || len(data) <= 2*period {
+		return nil, fmt.Errorf("could not decompose %d values with period %d", len(data), period)
+	}
+	if season == Multiplicative {
+		for i, v := range data {
+			if v <= 0 {
+				return nil, fmt.Errorf("could not decompose multiplicative season with non-positive value %v at %d", v, i)
+			}
+		}
+	}
+
+	trend, from, to := movingAverage(data, period)
+	// extend the trend with the average slope over the first and last period
+	head := (trend[from+period] - trend[from]) / float64(period)
+	for t := 0; t < from; t++ {
+		trend[t] = trend[from] - head*float64(from-t)
+	}
+	tail := (trend[to-1] - trend[to-1-period]) / float64(period)
+	for t := to; t < len(data); t++ {
+		trend[t] = trend[to-1] + tail*float64(t-to+1)
+	}
+
+	// average the deviation from the trend at each phase, where the moving average is defined
+	index := xmath.Vec(period)
+	counts := xmath.Vec(period)
+	for t := from; t < to; t++ {
+		if season == Multiplicative {
+			index[t%period] += data[t] / trend[t]
+		} else {
+			index[t%period] += data[t] - trend[t]
+		}
+		counts[t%period]++
+	}
+	var total float64
+	for i := range index {
+		index[i] /= counts[i]
+		total += index[i]
+	}
+	// normalise the season, so that it does not shift the level of the series
+	for i := range index {
+		if season == Multiplicative {
+			index[i] /= total / float64(period)
+		} else {
+			index[i] -= total / float64(period)
+		}
+	}
+
+	seasonal := xmath.Vec(len(data))
+	residual := xmath.Vec(len(data))
+	for t, v := range data {
+		seasonal[t] = index[t%period]
+		if season == Multiplicative {
+			residual[t] = v / (trend[t] * seasonal[t])
+		} else {
+			residual[t] = v - trend[t] - seasonal[t]
+		}
+	}
+
+	return &Decomposition{
+		Period:   period,
+		Season:   season,
+		Trend:    trend,
+		Seasonal: seasonal,
+		Residual: residual,
+	}, nil
+}
+
+// movingAverage returns the centered moving average of the series over the period,
+// together with the range of indices it is defined on.
+// For an even period the window spans period+1 values, with half the weight on the ones at either end.
+func movingAverage(data xmath.Vector, period int) (ma xmath.Vector, from, to int) {
+	half := period / 2
+	ma = xmath.Vec(len(data))
+	from, to = half, len(data)-half
+	for t := from; t < to; t++ {
+		var s float64
+		if period%2 == 0 {
+			s = (data[t-half] + data[t+half]) / 2
+			for i := t - half + 1; i < t+half; i++ {
+				s += data[i]
+			}
+		} else {
+			for i := t - half; i <= t+half; i++ {
+				s += data[i]
+			}
+		}
+		ma[t] = s / float64(period)
+	}
+	return ma, from, to
+}
+
+// DetectPeriod returns the lag with the highest autocorrelation peak up to the given lag.
+// The linear trend of the series is removed first, so that it does not hide the seasonal peaks.
+func DetectPeriod(data xmath.Vector, maxLag int) (int, error) {
+	w := detrend(data)
+	if maxLag >= len(w) {
+		maxLag = len(w) - 1
+	}
+	if maxLag < 3 {
+		return 0, fmt.Errorf("could not detect period on %d values", len(data))
+	}
+	acf := ACF(w, maxLag)
+	period := 0
+	for k := 2; k < maxLag; k++ {
+		if acf[k] > 0 && acf[k] > acf[k-1] && acf[k] >= acf[k+1] {
+			if period == 0 || acf[k] > acf[period] {
+				period = k
+			}
+		}
+	}
+	if period == 0 {
+		return 0, fmt.Errorf("could not detect period in autocorrelation up to lag %d", maxLag)
+	}
+	return period, nil
+}
+
+// detrend returns the deviations of the series from its least squares line.
+func detrend(data xmath.Vector) xmath.Vector {
+	n := float64(len(data))
+	tm := (n - 1) / 2
+	ym := mean(data)
+	var cov, v float64
+	for t, y := range data {
+		cov += (float64(t) - tm) * (y - ym)
+		v += (float64(t) - tm) * (float64(t) - tm)
+	}
+	var slope float64
+	if v > 0 {
+		slope = cov / v
+	}
+	w := xmath.Vec(len(data))
+	for t, y := range data {
+		w[t] = y - ym - slope*(float64(t)-tm)
+	}
+	return w
+}
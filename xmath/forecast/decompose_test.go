@@ -0,0 +1,158 @@
+package forecast
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/drakos74/go-ex-machina/xmath"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecompose(t *testing.T) {
+
+	weekly := []float64{-3, -1, 0, 1, 2, 4, -3}
+
+	type test struct {
+		season   Season
+		period   int
+		trend    func(t int) float64
+		seasonal func(t int) float64
+		series   func(t int) float64
+		delta    float64
+	}
+
+	tests := map[string]test{
+		"additive-even": {
+			season: Additive,
+			period: 4,
+			trend: func(t int) float64 {
+				return 10 + 0.5*float64(t)
+			},
+			seasonal: func(t int) float64 {
+				return pattern[t%4]
+			},
+			series: func(t int) float64 {
+				return 10 + 0.5*float64(t) + pattern[t%4]
+			},
+			delta: 1e-9,
+		},
+		"additive-odd": {
+			season: Additive,
+			period: 7,
+			trend: func(t int) float64 {
+				return 5 - 0.2*float64(t)
+			},
+			seasonal: func(t int) float64 {
+				return weekly[t%7]
+			},
+			series: func(t int) float64 {
+				return 5 - 0.2*float64(t) + weekly[t%7]
+			},
+			delta: 1e-9,
+		},
+		"multiplicative": {
+			season: Multiplicative,
+			period: 4,
+			trend: func(t int) float64 {
+				return 100 + float64(t)
+			},
+			seasonal: func(t int) float64 {
+				return 1 + 0.1*pattern[t%4]
+			},
+			series: func(t int) float64 {
+				return (100 + float64(t)) * (1 + 0.1*pattern[t%4])
+			},
+			delta: 0.02,
+		},
+		"detect": {
+			season: Additive,
+			trend: func(t int) float64 {
+				return 0.3 * float64(t)
+			},
+			seasonal: func(t int) float64 {
+				return weekly[t%7]
+			},
+			series: func(t int) float64 {
+				return 0.3*float64(t) + weekly[t%7]
+			},
+			delta: 1e-9,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			data := generate(50, tt.series)
+			d, err := Decompose(data, tt.period, tt.season)
+			assert.NoError(t, err)
+			if tt.period == 0 {
+				assert.Equal(t, 7, d.Period)
+			}
+			for i := range data {
+				assert.InDelta(t, tt.trend(i), d.Trend[i], tt.delta*math.Abs(tt.trend(i))+1e-9)
+				assert.InDelta(t, tt.seasonal(i), d.Seasonal[i], tt.delta+1e-9)
+				// the components add up to the series
+				if tt.season == Multiplicative {
+					assert.InDelta(t, data[i], d.Trend[i]*d.Seasonal[i]*d.Residual[i], 1e-9)
+				} else {
+					assert.InDelta(t, data[i], d.Trend[i]+d.Seasonal[i]+d.Residual[i], 1e-9)
+				}
+			}
+		})
+	}
+
+}
+
+func TestDecompose_Errors(t *testing.T) {
+
+	type test struct {
+		data   xmath.Vector
+		period int
+		season Season
+	}
+
+	tests := map[string]test{
+		"no-season": {
+			data:   generate(20, func(t int) float64 { return 1 }),
+			period: 4,
+			season: NoSeason,
+		},
+		"too-short": {
+			data:   generate(8, func(t int) float64 { return 1 }),
+			period: 4,
+			season: Additive,
+		},
+		"multiplicative-negative": {
+			data:   generate(20, func(t int) float64 { return float64(t) - 5 }),
+			period: 4,
+			season: Multiplicative,
+		},
+		"no-period": {
+			data:   generate(20, func(t int) float64 { return float64(t) }),
+			season: Additive,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Decompose(tt.data, tt.period, tt.season)
+			assert.Error(t, err)
+		})
+	}
+
+}
+
+func TestDetectPeriod(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(1))
+
+	for _, period := range []int{3, 5, 12, 24} {
+		data := generate(500, func(t int) float64 {
+			return 0.05*float64(t) + math.Sin(2*math.Pi*float64(t)/float64(period)) + 0.2*rng.NormFloat64()
+		})
+		p, err := DetectPeriod(data, 50)
+		assert.NoError(t, err)
+		assert.Equal(t, period, p)
+	}
+
+}
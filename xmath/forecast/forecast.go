@@ -0,0 +1,73 @@
+package forecast
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/drakos74/go-ex-machina/xmath"
+	"github.com/drakos74/go-ex-machina/xmath/series"
+)
+
+// Model is a statistical forecasting model for a univariate series,
+// e.g. as a baseline to benchmark the recurrent networks against.
+type Model interface {
+	// Fit resets the model and fits it on the series.
+	// It returns the sum of squared one-step-ahead errors, after the model has warmed up.
+	Fit(data xmath.Vector) (float64, error)
+	// Push updates the model with the next value of the series.
+	Push(v float64)
+	// Forecast returns the predictions for the given number of steps after the last value.
+	Forecast(steps int) xmath.Vector
+}
+
+// Tunable is a model with parameters within (0,1], that can be fitted on a grid of their values.
+type Tunable interface {
+	Model
+	// Params returns the parameters of the model, so that they can be evolved in place.
+	Params() []*float64
+}
+
+// Grid fits the model for every combination of the parameters on a grid of the given number of values within (0,1],
+// and keeps the parameters with the smallest error.
+// It returns the error of the best fit, with the model fitted on the whole series.
+func Grid(model Tunable, data xmath.Vector, limit int) (float64, error) {
+	if limit < 1 {
+		panic(fmt.Sprintf("grid must have at least '%v' values per parameter vs '%v'", 1, limit))
+	}
+	params := model.Params()
+	sequences := make([]*series.Sequence, len(params))
+	step := 1 / float64(limit)
+	for i, p := range params {
+		sequences[i] = series.RangeSequence(p, step, 1+step, limit, 6)
+	}
+	evolution := series.NewEvolution(sequences...)
+
+	best := math.Inf(1)
+	values := make([]float64, len(params))
+	for evolution.Next() {
+		sse, err := model.Fit(data)
+		if err != nil {
+			return 0, fmt.Errorf("could not fit model: %w", err)
+		}
+		if sse < best {
+			best = sse
+			for i, p := range params {
+				values[i] = *p
+			}
+		}
+	}
+
+	for i, p := range params {
+		*p = values[i]
+	}
+	return model.Fit(data)
+}
+
+// nan returns a vector of the given size, with all elements NaN.
+func nan(n int) xmath.Vector {
+	v := xmath.Vec(n)
+	for i := range v {
+		v[i] = math.NaN()
+	}
+	return v
+}
@@ -0,0 +1,245 @@
+package forecast
+
+import (
+	"fmt"
+
+	"github.com/drakos74/go-ex-machina/xmath"
+)
+
+// Season is the way the seasonal component combines with the level of the series.
+type Season int
+
+const (
+	// NoSeason means the series has no seasonal component.
+	NoSeason Season = iota
+	// Additive seasons add a constant amount to the level e.g. +10 every weekend.
+	Additive
+	// Multiplicative seasons scale the level e.g. +10% every weekend.
+	Multiplicative
+)
+
+func (s Season) String() string {
+	switch s {
+	case NoSeason:
+		return "none"
+	case Additive:
+		return "additive"
+	case Multiplicative:
+		return "multiplicative"
+	}
+	return fmt.Sprintf("season(%d)", int(s))
+}
+
+// Smoothing is an exponential smoothing model, with an optional trend and season.
+// Alpha smooths the level, Beta the trend and Gamma the season, where higher values follow recent values more closely.
+type Smoothing struct {
+	Alpha, Beta, Gamma float64
+	trend              bool
+	season             Season
+	period             int
+	// level, slope and seasonal are the current components of the series
+	level, slope float64
+	seasonal     xmath.Vector
+	// t is the number of values pushed
+	t int
+	// warmup keeps the first values, until there are enough of them to initialise the components
+	warmup xmath.Vector
+	sse    float64
+}
+
+// NewSimple creates a simple exponential smoothing model, for series without trend or season.
+func NewSimple(alpha float64) *Smoothing {
+	return newSmoothing(alpha, 0, 0, false, NoSeason, 0)
+}
+
+// NewHolt creates a double exponential smoothing model, for series with a trend.
+func NewHolt(alpha, beta float64) *Smoothing {
+	return newSmoothing(alpha, beta, 0, true, NoSeason, 0)
+}
+
+// NewHoltWinters creates a triple exponential smoothing model, for series with a trend and a season of the given period.
+func NewHoltWinters(alpha, beta, gamma float64, period int, season Season) *Smoothing {
+	if period < 2 {
+		panic(fmt.Sprintf("season period must be at least '%v' vs '%v'", 2, period))
+	}
+	if season == NoSeason {
+		panic("holt-winters model needs an additive or multiplicative season")
+	}
+	return newSmoothing(alpha, beta, gamma, true, season, period)
+}
+
+func newSmoothing(alpha, beta, gamma float64, trend bool, season Season, period int) *Smoothing {
+	s := &Smoothing{
+		Alpha:  alpha,
+		Beta:   beta,
+		Gamma:  gamma,
+		trend:  trend,
+		season: season,
+		period: period,
+	}
+	s.reset()
+	return s
+}
+
+// reset clears the state of the model, keeping its parameters.
+func (s *Smoothing) reset() {
+	s.level, s.slope = 0, 0
+	s.seasonal = xmath.Vec(s.period)
+	s.t = 0
+	s.warmup = xmath.Vec(0)
+	s.sse = 0
+}
+
+// Params returns the smoothing parameters of the model.
+func (s *Smoothing) Params() []*float64 {
+	params := []*float64{&s.Alpha}
+	if s.trend {
+		params = append(params, &s.Beta)
+	}
+	if s.season != NoSeason {
+		params = append(params, &s.Gamma)
+	}
+	return params
+}
+
+// needs returns the number of values needed to initialise the components.
+func (s *Smoothing) needs() int {
+	switch {
+	case s.season != NoSeason:
+		return 2 * s.period
+	case s.trend:
+		return 2
+	}
+	return 1
+}
+
+// Fit resets the model and fits it on the series.
+// It returns the sum of squared one-step-ahead errors, after the model has warmed up.
+func (s *Smoothing) Fit(data xmath.Vector) (float64, error) {
+	if len(data) < s.needs() {
+		return 0, fmt.Errorf("could not fit smoothing model on %d values, it needs at least %d", len(data), s.needs())
+	}
+	if err := xmath.ExpectValid("series", data); err != nil {
+		return 0, err
+	}
+	if s.season == Multiplicative {
+		for i, v := range data {
+			if v <= 0 {
+				return 0, fmt.Errorf("could not fit multiplicative season on non-positive value %v at %d", v, i)
+			}
+		}
+	}
+	s.reset()
+	for _, v := range data {
+		s.Push(v)
+	}
+	return s.sse, nil
+}
+
+// Push updates the model with the next value of the series.
+// The first values are kept until there are enough of them to initialise the model,
+// e.g. two full seasons for the Holt-Winters model.
+func (s *Smoothing) Push(v float64) {
+	if len(s.warmup) < s.needs() {
+		s.warmup = append(s.warmup, v)
+		if len(s.warmup) == s.needs() {
+			s.initialise()
+		}
+		return
+	}
+	s.update(v)
+}
+
+// initialise sets the components out of the warmup values, and applies the values after the first season on them.
+func (s *Smoothing) initialise() {
+	switch {
+	case s.season != NoSeason:
+		m := s.period
+		first := mean(s.warmup[:m])
+		s.slope = (mean(s.warmup[m:]) - first) / float64(m)
+		// the average of the first season lies at its middle, so the trend is taken out of the season around it
+		center := float64(m-1) / 2
+		s.level = first + s.slope*center
+		for i := 0; i < m; i++ {
+			trend := first + s.slope*(float64(i)-center)
+			if s.season == Multiplicative {
+				s.seasonal[i] = s.warmup[i] / trend
+			} else {
+				s.seasonal[i] = s.warmup[i] - trend
+			}
+		}
+		s.t = m
+		for _, v := range s.warmup[m:] {
+			s.update(v)
+		}
+	case s.trend:
+		s.level = s.warmup[0]
+		s.slope = s.warmup[1] - s.warmup[0]
+		s.t = 1
+		s.update(s.warmup[1])
+	default:
+		s.level = s.warmup[0]
+		s.t = 1
+	}
+}
+
+// update applies the next value on the components.
+func (s *Smoothing) update(v float64) {
+	e := v - s.predict(1)
+	s.sse += e * e
+
+	level := s.level
+	switch s.season {
+	case Additive:
+		i := s.t % s.period
+		season := s.seasonal[i]
+		s.level = s.Alpha*(v-season) + (1-s.Alpha)*(level+s.slope)
+		s.seasonal[i] = s.Gamma*(v-s.level) + (1-s.Gamma)*season
+	case Multiplicative:
+		i := s.t % s.period
+		season := s.seasonal[i]
+		s.level = s.Alpha*(v/season) + (1-s.Alpha)*(level+s.slope)
+		s.seasonal[i] = s.Gamma*(v/s.level) + (1-s.Gamma)*season
+	default:
+		s.level = s.Alpha*v + (1-s.Alpha)*(level+s.slope)
+	}
+	if s.trend {
+		s.slope = s.Beta*(s.level-level) + (1-s.Beta)*s.slope
+	}
+	s.t++
+}
+
+// predict returns the prediction for the given number of steps ahead.
+func (s *Smoothing) predict(h int) float64 {
+	y := s.level + float64(h)*s.slope
+	if s.season == NoSeason {
+		return y
+	}
+	season := s.seasonal[(s.t+h-1)%s.period]
+	if s.season == Multiplicative {
+		return y * season
+	}
+	return y + season
+}
+
+// Forecast returns the predictions for the given number of steps after the last value.
+// The predictions are NaN until the model has enough values to be initialised.
+func (s *Smoothing) Forecast(steps int) xmath.Vector {
+	if len(s.warmup) < s.needs() {
+		return nan(steps)
+	}
+	f := xmath.Vec(steps)
+	for h := range f {
+		f[h] = s.predict(h + 1)
+	}
+	return f
+}
+
+// mean returns the average of the values.
+func mean(v xmath.Vector) float64 {
+	var sum float64
+	for _, x := range v {
+		sum += x
+	}
+	return sum / float64(len(v))
+}
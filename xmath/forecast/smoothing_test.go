@@ -0,0 +1,166 @@
+package forecast
+
+import (
+	"math"
+	"testing"
+
+	"github.com/drakos74/go-ex-machina/xmath"
+	"github.com/stretchr/testify/assert"
+)
+
+// generate creates a series of the given size out of the function.
+func generate(n int, f func(t int) float64) xmath.Vector {
+	v := xmath.Vec(n)
+	for t := range v {
+		v[t] = f(t)
+	}
+	return v
+}
+
+var pattern = []float64{-3, 1, 4, -2}
+
+func TestSmoothing_Forecast(t *testing.T) {
+
+	type test struct {
+		model  *Smoothing
+		series func(t int) float64
+		delta  float64
+	}
+
+	tests := map[string]test{
+		"simple-constant": {
+			model: NewSimple(0.3),
+			series: func(t int) float64 {
+				return 5
+			},
+		},
+		"holt-linear": {
+			model: NewHolt(0.4, 0.2),
+			series: func(t int) float64 {
+				return 2*float64(t) + 1
+			},
+		},
+		"holt-winters-additive": {
+			model: NewHoltWinters(0.3, 0.1, 0.3, 4, Additive),
+			series: func(t int) float64 {
+				return 10 + 0.5*float64(t) + pattern[t%4]
+			},
+			delta: 0.01,
+		},
+		"holt-winters-multiplicative": {
+			model: NewHoltWinters(0.3, 0.1, 0.3, 4, Multiplicative),
+			series: func(t int) float64 {
+				return (10 + 0.5*float64(t)) * (1 + 0.1*pattern[t%4])
+			},
+			delta: 0.01,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			data := generate(200, tt.series)
+			_, err := tt.model.Fit(data)
+			assert.NoError(t, err)
+			forecast := tt.model.Forecast(8)
+			assert.Equal(t, 8, len(forecast))
+			for h, f := range forecast {
+				assert.InDelta(t, tt.series(200+h), f, tt.delta)
+			}
+		})
+	}
+
+}
+
+func TestSmoothing_Push(t *testing.T) {
+
+	model := NewHoltWinters(0.5, 0.5, 0.5, 3, Additive)
+
+	// the model needs two seasons to be initialised
+	for i := 0; i < 5; i++ {
+		for _, f := range model.Forecast(2) {
+			assert.True(t, math.IsNaN(f))
+		}
+		model.Push(float64(i))
+	}
+	model.Push(5)
+	assert.InDelta(t, 6, model.Forecast(1)[0], 1e-9)
+
+	// pushing the values is the same as fitting on them
+	fitted := NewHoltWinters(0.5, 0.5, 0.5, 3, Additive)
+	data := generate(20, func(t int) float64 {
+		return math.Sin(float64(t))
+	})
+	_, err := fitted.Fit(data)
+	assert.NoError(t, err)
+	pushed := NewHoltWinters(0.5, 0.5, 0.5, 3, Additive)
+	for _, v := range data {
+		pushed.Push(v)
+	}
+	assert.Equal(t, fitted.Forecast(5), pushed.Forecast(5))
+
+}
+
+func TestSmoothing_FitErrors(t *testing.T) {
+
+	type test struct {
+		model *Smoothing
+		data  xmath.Vector
+	}
+
+	tests := map[string]test{
+		"too-short": {
+			model: NewHoltWinters(0.5, 0.5, 0.5, 4, Additive),
+			data:  xmath.Vec(7),
+		},
+		"nan": {
+			model: NewSimple(0.5),
+			data:  xmath.Vec(3).With(1, math.NaN(), 2),
+		},
+		"multiplicative-zero": {
+			model: NewHoltWinters(0.5, 0.5, 0.5, 2, Multiplicative),
+			data:  xmath.Vec(4).With(1, 2, 0, 3),
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := tt.model.Fit(tt.data)
+			assert.Error(t, err)
+		})
+	}
+
+}
+
+func TestGrid(t *testing.T) {
+
+	data := generate(120, func(t int) float64 {
+		return 20 + 0.2*float64(t) + 3*pattern[t%4] + math.Sin(float64(t)*0.7)
+	})
+
+	model := NewHoltWinters(0.9, 0.9, 0.9, 4, Additive)
+	initial, err := model.Fit(data)
+	assert.NoError(t, err)
+
+	sse, err := Grid(model, data, 10)
+	assert.NoError(t, err)
+	assert.True(t, sse < initial)
+
+	// the model is fitted with the best parameters
+	for _, p := range model.Params() {
+		assert.True(t, *p > 0 && *p <= 1)
+	}
+	refit, err := model.Fit(data)
+	assert.NoError(t, err)
+	assert.Equal(t, sse, refit)
+
+	// a finer grid can only get better on the same values
+	finer, err := Grid(NewHoltWinters(0, 0, 0, 4, Additive), data, 20)
+	assert.NoError(t, err)
+	assert.True(t, finer <= sse)
+
+}
+
+func TestGrid_Error(t *testing.T) {
+	_, err := Grid(NewHolt(0.5, 0.5), xmath.Vec(1), 5)
+	assert.Error(t, err)
+}
@@ -0,0 +1,110 @@
+package xmath
+
+import (
+	"runtime"
+	"sync"
+)
+
+// parallelThreshold is the number of multiply-add operations above which
+// matrix products are split across runtime.NumCPU() goroutines.
+// Below it the overhead of the goroutines outweighs the gain, so products stay single-threaded.
+const parallelThreshold = 1 << 16
+
+// blockSize is the size of the tiles for the blocked matrix multiplication,
+// chosen so that the tiles of both operands fit comfortably in the L1/L2 cache.
+const blockSize = 64
+
+// workers returns the number of goroutines to split the n rows of a product with the given work across.
+// It returns 1 when the work is below the threshold.
+func workers(n, work, threshold int) int {
+	if work < threshold {
+		return 1
+	}
+	return min(runtime.NumCPU(), n)
+}
+
+// gemm accumulates the blocked product of m with the transpose of v into out, split across the given number of workers.
+// The element at (i,j) of the product is at i*len(v)+j of out e.g. the rows of the product are contiguous.
+func gemm(out Vector, m, v Matrix, workers int) {
+	if len(m) == 0 || len(v) == 0 {
+		return
+	}
+	if workers > 1 {
+		parallel(len(m), workers, func(from, to int) {
+			gemmInto(out, m, v, from, to)
+		})
+	} else {
+		gemmInto(out, m, v, 0, len(m))
+	}
+}
+
+// gemmInto accumulates the blocked product of the rows [from,to) of m with the transpose of v into out.
+func gemmInto(out Vector, m, v Matrix, from, to int) {
+	k := len(m[0])
+	n := len(v)
+	for ii := from; ii < to; ii += blockSize {
+		ie := min(ii+blockSize, to)
+		for jj := 0; jj < n; jj += blockSize {
+			je := min(jj+blockSize, n)
+			for kk := 0; kk < k; kk += blockSize {
+				ke := min(kk+blockSize, k)
+				for i := ii; i < ie; i++ {
+					a := m[i][kk:ke]
+					row := out[i*n : (i+1)*n]
+					for j := jj; j < je; j++ {
+						row[j] += dot(a, v[j][kk:ke])
+					}
+				}
+			}
+		}
+	}
+}
+
+// parallel splits the index range [0,n) in contiguous chunks and applies fn to each of them concurrently.
+// Callers should invoke the kernel directly when there is only one worker,
+// to avoid the allocation of the closure in the single-threaded case.
+func parallel(n, workers int, fn func(from, to int)) {
+	chunk := (n + workers - 1) / workers
+	wg := sync.WaitGroup{}
+	for from := 0; from < n; from += chunk {
+		wg.Add(1)
+		go func(from, to int) {
+			defer wg.Done()
+			fn(from, to)
+		}(from, min(from+chunk, n))
+	}
+	wg.Wait()
+}
+
+// dot returns the dot product of two vectors of the same size, without any checks.
+// The loop is unrolled to allow for independent accumulators.
+func dot(v, w Vector) float64 {
+	w = w[:len(v)]
+	var p0, p1, p2, p3 float64
+	i := 0
+	for ; i+4 <= len(v); i += 4 {
+		p0 += v[i] * w[i]
+		p1 += v[i+1] * w[i+1]
+		p2 += v[i+2] * w[i+2]
+		p3 += v[i+3] * w[i+3]
+	}
+	for ; i < len(v); i++ {
+		p0 += v[i] * w[i]
+	}
+	return p0 + p1 + p2 + p3
+}
+
+// axpy adds x scaled by a to y, without any checks.
+func axpy(a float64, x, y Vector) {
+	y = y[:len(x)]
+	for i, v := range x {
+		y[i] += a * v
+	}
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
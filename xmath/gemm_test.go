@@ -0,0 +1,207 @@
+package xmath
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// naiveProd is the plain single-threaded matrix-vector product, used as reference.
+func naiveProd(m Matrix, v Vector) Vector {
+	w := Vec(len(m))
+	for i := range m {
+		w[i] = m[i].Dot(v)
+	}
+	return w
+}
+
+// naiveDot is the plain single-threaded matrix product, used as reference.
+func naiveDot(m, v Matrix) Matrix {
+	w := Mat(len(m)).Of(len(v))
+	for i := range m {
+		for j := range v {
+			w[i][j] = m[i].Dot(v[j])
+		}
+	}
+	return w
+}
+
+func randMatrix(rows, cols int, seed int64) Matrix {
+	rnd := rand.New(rand.NewSource(seed))
+	m := Mat(rows).Of(cols)
+	for i := range m {
+		for j := range m[i] {
+			m[i][j] = rnd.Float64() - 0.5
+		}
+	}
+	return m
+}
+
+func assertMatrixInDelta(t *testing.T, expected, actual Matrix) {
+	assert.Equal(t, len(expected), len(actual))
+	for i := range expected {
+		assert.InDeltaSlice(t, expected[i], actual[i], 1e-9)
+	}
+}
+
+func TestGEMM(t *testing.T) {
+
+	type test struct {
+		m, n, k int
+	}
+
+	tests := map[string]test{
+		"single":     {m: 1, n: 1, k: 1},
+		"vector":     {m: 1, n: 7, k: 3},
+		"small":      {m: 5, n: 3, k: 7},
+		"unaligned":  {m: 67, n: 65, k: 130},
+		"mnist":      {m: 200, n: 10, k: 784},
+		"one-column": {m: 300, n: 1, k: 5},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := randMatrix(tt.m, tt.k, 1)
+			v := randMatrix(tt.n, tt.k, 2)
+			x := v[0]
+			d := DenseOf(m)
+			y := randMatrix(1, tt.m, 3)[0]
+
+			assertMatrixInDelta(t, naiveDot(m, v), m.Dot(v))
+			assert.InDeltaSlice(t, naiveProd(m, x), m.Prod(x), 1e-9)
+			assert.InDeltaSlice(t, naiveProd(m, x), d.Prod(x), 1e-9)
+			assert.InDeltaSlice(t, naiveProd(m.T(), y), d.T().Prod(y), 1e-9)
+
+			// the kernels give the same result for any split across workers, including more workers than rows
+			for _, n := range []int{1, 2, 7, tt.m + 1} {
+				w := Vec(tt.m * tt.n)
+				gemm(w, m, v, n)
+				for i, row := range naiveDot(m, v) {
+					assert.InDeltaSlice(t, row, w[i*tt.n:(i+1)*tt.n], 1e-9)
+				}
+
+				p := Vec(tt.m).Generate(Const(1))
+				m.prodInto(p, x, n)
+				assert.InDeltaSlice(t, naiveProd(m, x), p, 1e-9)
+
+				p = Vec(tt.m)
+				d.prod(p, x, n)
+				assert.InDeltaSlice(t, naiveProd(m, x), p, 1e-9)
+
+				q := Vec(tt.k).Generate(Const(1))
+				d.T().prod(q, y, n)
+				assert.InDeltaSlice(t, naiveProd(m.T(), y), q, 1e-9)
+			}
+		})
+	}
+
+	assert.Panics(t, func() {
+		randMatrix(2, 3, 1).Dot(randMatrix(2, 4, 1))
+	})
+
+}
+
+func TestWorkers(t *testing.T) {
+
+	type test struct {
+		n, work, threshold int
+		workers            int
+	}
+
+	tests := map[string]test{
+		"below-threshold": {n: 100, work: 99, threshold: 100, workers: 1},
+		"at-threshold":    {n: 100, work: 100, threshold: 100, workers: min(runtime.NumCPU(), 100)},
+		"single-row":      {n: 1, work: 1000, threshold: 100, workers: 1},
+		"no-threshold":    {n: 3, work: 0, threshold: 0, workers: min(runtime.NumCPU(), 3)},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.workers, workers(tt.n, tt.work, tt.threshold))
+		})
+	}
+
+}
+
+func TestParallel(t *testing.T) {
+
+	for _, n := range []int{1, 5, 64, 101} {
+		for _, w := range []int{1, 3, 8, 200} {
+			t.Run(fmt.Sprintf("%d-%d", n, w), func(t *testing.T) {
+				// every index is visited exactly once, in contiguous chunks
+				visits := make([]int32, n)
+				var chunks int32
+				parallel(n, w, func(from, to int) {
+					atomic.AddInt32(&chunks, 1)
+					for i := from; i < to; i++ {
+						atomic.AddInt32(&visits[i], 1)
+					}
+				})
+				for i := range visits {
+					assert.Equal(t, int32(1), visits[i])
+				}
+				assert.True(t, int(chunks) <= w)
+			})
+		}
+	}
+
+}
+
+var benchmarkSizes = []struct {
+	m, n, k int
+}{
+	{m: 10, n: 10, k: 10},
+	{m: 64, n: 64, k: 64},
+	{m: 200, n: 200, k: 784},
+	{m: 512, n: 512, k: 512},
+}
+
+func BenchmarkMatrix_Dot(b *testing.B) {
+	for _, s := range benchmarkSizes {
+		m := randMatrix(s.m, s.k, 1)
+		v := randMatrix(s.n, s.k, 2)
+		b.Run(fmt.Sprintf("naive-%dx%dx%d", s.m, s.n, s.k), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				naiveDot(m, v)
+			}
+		})
+		b.Run(fmt.Sprintf("blocked-%dx%dx%d", s.m, s.n, s.k), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				m.Dot(v)
+			}
+		})
+	}
+}
+
+func BenchmarkMatrix_Prod(b *testing.B) {
+	for _, s := range benchmarkSizes {
+		m := randMatrix(s.m, s.k, 1)
+		v := randMatrix(1, s.k, 2)[0]
+		d := DenseOf(m)
+		b.Run(fmt.Sprintf("naive-%dx%d", s.m, s.k), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				naiveProd(m, v)
+			}
+		})
+		b.Run(fmt.Sprintf("matrix-%dx%d", s.m, s.k), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				m.Prod(v)
+			}
+		})
+		b.Run(fmt.Sprintf("dense-%dx%d", s.m, s.k), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				d.Prod(v)
+			}
+		})
+		w := randMatrix(1, s.m, 3)[0]
+		b.Run(fmt.Sprintf("dense-transposed-%dx%d", s.k, s.m), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				d.T().Prod(w)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package xmath
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// The initializers below generate the rows of a weight matrix with fanOut rows and fanIn columns,
+// as the weights of a neuron with fanIn inputs and fanOut outputs.
+// They draw from the given random source, so that the same seed leads to the same weights.
+// A nil source falls back to one seeded with the current time, so that the weights differ on every run.
+
+// Initializer creates the generator for the weights of a layer, once its fan-in and fan-out are known
+// e.g. so that the same initializer can be used for layers of different sizes.
+type Initializer func(fanIn, fanOut int) VectorGenerator
+
+// truncation is the number of standard deviations beyond which the normal values are drawn again.
+const truncation = 2.0
+
+// truncatedStd is the standard deviation of the standard normal distribution truncated at 2 standard deviations.
+// It is used to correct the scale, so that the truncated values keep the intended variance.
+const truncatedStd = 0.87962566103423978
+
+// source returns the given random source, or a new one seeded with the current time.
+func source(rng *rand.Rand) *rand.Rand {
+	if rng == nil {
+		return rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return rng
+}
+
+// uniform generates rows of the given size with values uniformly distributed in [-limit,limit).
+func uniform(limit float64, rng *rand.Rand) VectorGenerator {
+	return func(s, index int) Vector {
+		w := Vec(s)
+		for i := range w {
+			w[i] = (2*rng.Float64() - 1) * limit
+		}
+		return w
+	}
+}
+
+// truncated draws a value from the normal distribution, discarding values further than 2 standard deviations from the mean.
+func truncated(mean, std float64, rng *rand.Rand) float64 {
+	for {
+		x := rng.NormFloat64()
+		if math.Abs(x) <= truncation {
+			return mean + x*std
+		}
+	}
+}
+
+// normal generates rows of the given size with values drawn from a truncated normal distribution with the given standard deviation.
+func normal(std float64, rng *rand.Rand) VectorGenerator {
+	return func(s, index int) Vector {
+		w := Vec(s)
+		for i := range w {
+			w[i] = truncated(0, std/truncatedStd, rng)
+		}
+		return w
+	}
+}
+
+// GlorotUniform generates weights uniformly in [-limit,limit) with limit = sqrt(6/(fanIn+fanOut)).
+// It is also known as xavier initialisation and suits the sigmoid and tanh activations.
+func GlorotUniform(rng *rand.Rand) Initializer {
+	rng = source(rng)
+	return func(fanIn, fanOut int) VectorGenerator {
+		return uniform(math.Sqrt(6/float64(fanIn+fanOut)), rng)
+	}
+}
+
+// GlorotNormal generates weights from a truncated normal distribution with variance 2/(fanIn+fanOut).
+func GlorotNormal(rng *rand.Rand) Initializer {
+	rng = source(rng)
+	return func(fanIn, fanOut int) VectorGenerator {
+		return normal(math.Sqrt(2/float64(fanIn+fanOut)), rng)
+	}
+}
+
+// HeUniform generates weights uniformly in [-limit,limit) with limit = sqrt(6/fanIn).
+// It suits the relu family of activations.
+func HeUniform(rng *rand.Rand) Initializer {
+	rng = source(rng)
+	return func(fanIn, fanOut int) VectorGenerator {
+		return uniform(math.Sqrt(6/float64(fanIn)), rng)
+	}
+}
+
+// HeNormal generates weights from a truncated normal distribution with variance 2/fanIn.
+func HeNormal(rng *rand.Rand) Initializer {
+	rng = source(rng)
+	return func(fanIn, fanOut int) VectorGenerator {
+		return normal(math.Sqrt(2/float64(fanIn)), rng)
+	}
+}
+
+// LeCunUniform generates weights uniformly in [-limit,limit) with limit = sqrt(3/fanIn).
+func LeCunUniform(rng *rand.Rand) Initializer {
+	rng = source(rng)
+	return func(fanIn, fanOut int) VectorGenerator {
+		return uniform(math.Sqrt(3/float64(fanIn)), rng)
+	}
+}
+
+// LeCunNormal generates weights from a truncated normal distribution with variance 1/fanIn.
+func LeCunNormal(rng *rand.Rand) Initializer {
+	rng = source(rng)
+	return func(fanIn, fanOut int) VectorGenerator {
+		return normal(math.Sqrt(1/float64(fanIn)), rng)
+	}
+}
+
+// TruncatedNormal generates vectors of any size with values drawn from a normal distribution with the given mean and standard deviation,
+// discarding values further than 2 standard deviations from the mean.
+func TruncatedNormal(mean, std float64, rng *rand.Rand) VectorGenerator {
+	rng = source(rng)
+	return func(s, index int) Vector {
+		w := Vec(s)
+		for i := range w {
+			w[i] = truncated(mean, std, rng)
+		}
+		return w
+	}
+}
+
+// Orthogonal generates a weight matrix with orthonormal rows, or orthonormal columns if there are more rows than columns,
+// scaled by the given gain.
+// The matrix is the Q factor of the QR decomposition of a random gaussian one.
+// A new matrix is drawn for every fanOut rows, so that each neuron gets its own weights.
+func Orthogonal(gain float64, rng *rand.Rand) Initializer {
+	rng = source(rng)
+	return func(fanIn, fanOut int) VectorGenerator {
+		var q Matrix
+		return func(s, index int) Vector {
+			if q == nil || index%fanOut == 0 {
+				q = orthogonal(fanOut, s, rng).Mult(gain)
+			}
+			return q[index%fanOut].Copy()
+		}
+	}
+}
+
+// orthogonal returns a random rows x cols matrix with orthonormal rows or columns, whichever are fewer.
+func orthogonal(rows, cols int, rng *rand.Rand) Matrix {
+	// decompose the tall orientation, so that the QR is well defined
+	r, c := rows, cols
+	if rows < cols {
+		r, c = cols, rows
+	}
+	a := Mat(r).Of(c)
+	for i := range a {
+		for j := range a[i] {
+			a[i][j] = rng.NormFloat64()
+		}
+	}
+	qr, err := a.QR()
+	if err != nil {
+		panic(fmt.Sprintf("could not create orthogonal matrix: %v", err))
+	}
+	q := qr.Q()
+	rr := qr.R()
+	// fix the signs, so that the matrix is uniformly distributed over the orthogonal ones
+	for j := 0; j < c; j++ {
+		if rr[j][j] < 0 {
+			for i := range q {
+				q[i][j] = -q[i][j]
+			}
+		}
+	}
+	if rows < cols {
+		return q.T()
+	}
+	return q
+}
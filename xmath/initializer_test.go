@@ -0,0 +1,136 @@
+package xmath
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInitializers(t *testing.T) {
+
+	type test struct {
+		init     func(rng *rand.Rand) Initializer
+		variance func(fanIn, fanOut float64) float64
+		limit    func(fanIn, fanOut float64) float64
+	}
+
+	tests := map[string]test{
+		"glorot-uniform": {
+			init:     GlorotUniform,
+			variance: func(fanIn, fanOut float64) float64 { return 2 / (fanIn + fanOut) },
+			limit:    func(fanIn, fanOut float64) float64 { return math.Sqrt(6 / (fanIn + fanOut)) },
+		},
+		"glorot-normal": {
+			init:     GlorotNormal,
+			variance: func(fanIn, fanOut float64) float64 { return 2 / (fanIn + fanOut) },
+			limit:    func(fanIn, fanOut float64) float64 { return 2 * math.Sqrt(2/(fanIn+fanOut)) / truncatedStd },
+		},
+		"he-uniform": {
+			init:     HeUniform,
+			variance: func(fanIn, fanOut float64) float64 { return 2 / fanIn },
+			limit:    func(fanIn, fanOut float64) float64 { return math.Sqrt(6 / fanIn) },
+		},
+		"he-normal": {
+			init:     HeNormal,
+			variance: func(fanIn, fanOut float64) float64 { return 2 / fanIn },
+			limit:    func(fanIn, fanOut float64) float64 { return 2 * math.Sqrt(2/fanIn) / truncatedStd },
+		},
+		"lecun-uniform": {
+			init:     LeCunUniform,
+			variance: func(fanIn, fanOut float64) float64 { return 1 / fanIn },
+			limit:    func(fanIn, fanOut float64) float64 { return math.Sqrt(3 / fanIn) },
+		},
+		"lecun-normal": {
+			init:     LeCunNormal,
+			variance: func(fanIn, fanOut float64) float64 { return 1 / fanIn },
+			limit:    func(fanIn, fanOut float64) float64 { return 2 * math.Sqrt(1/fanIn) / truncatedStd },
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			fanIn, fanOut := 200, 100
+			d := NewDense(fanOut, fanIn).Generate(tt.init(rand.New(rand.NewSource(1)))(fanIn, fanOut))
+
+			// the same seed gives the same weights
+			assert.Equal(t, d, NewDense(fanOut, fanIn).Generate(tt.init(rand.New(rand.NewSource(1)))(fanIn, fanOut)))
+			assert.NotEqual(t, d, NewDense(fanOut, fanIn).Generate(tt.init(rand.New(rand.NewSource(2)))(fanIn, fanOut)))
+
+			var sum, sq float64
+			limit := tt.limit(float64(fanIn), float64(fanOut))
+			for i := 0; i < fanOut; i++ {
+				for _, x := range d.Row(i) {
+					assert.True(t, math.Abs(x) <= limit)
+					sum += x
+					sq += x * x
+				}
+			}
+			n := float64(fanIn * fanOut)
+			variance := tt.variance(float64(fanIn), float64(fanOut))
+			assert.InDelta(t, 0, sum/n, 0.05*math.Sqrt(variance))
+			assert.InDelta(t, variance, sq/n-(sum/n)*(sum/n), 0.05*variance)
+
+			// the same initializer scales to the shape of each layer
+			init := tt.init(rand.New(rand.NewSource(1)))
+			for _, shape := range [][2]int{{3, 2}, {2, 3}, {fanIn, fanOut}} {
+				in, out := shape[0], shape[1]
+				w := NewDense(out, in).Generate(init(in, out))
+				limit := tt.limit(float64(in), float64(out))
+				for i := 0; i < out; i++ {
+					for _, x := range w.Row(i) {
+						assert.True(t, math.Abs(x) <= limit)
+					}
+				}
+			}
+		})
+	}
+
+}
+
+func TestTruncatedNormal(t *testing.T) {
+
+	v := Vec(10000).Generate(TruncatedNormal(5, 2, rand.New(rand.NewSource(1))))
+
+	var sum float64
+	for _, x := range v {
+		assert.True(t, x >= 1 && x <= 9)
+		sum += x
+	}
+	assert.InDelta(t, 5, sum/float64(len(v)), 0.05)
+
+}
+
+func TestOrthogonal(t *testing.T) {
+
+	type test struct {
+		fanIn, fanOut int
+	}
+
+	tests := map[string]test{
+		"wide":   {fanIn: 8, fanOut: 3},
+		"tall":   {fanIn: 3, fanOut: 8},
+		"square": {fanIn: 5, fanOut: 5},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			gen := Orthogonal(2, rand.New(rand.NewSource(1)))(tt.fanIn, tt.fanOut)
+			m := Mat(tt.fanOut).Generate(tt.fanIn, gen)
+
+			// the fewer of the rows or columns are orthogonal, with norm equal to the gain
+			p := m.Dot(m)
+			k := tt.fanOut
+			if tt.fanOut > tt.fanIn {
+				p = m.T().Dot(m.T())
+				k = tt.fanIn
+			}
+			assertMatrixInDelta(t, Identity(k).Mult(4), p)
+
+			// every neuron gets its own matrix
+			assert.NotEqual(t, m, Mat(tt.fanOut).Generate(tt.fanIn, gen))
+		})
+	}
+
+}
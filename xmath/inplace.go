@@ -0,0 +1,119 @@
+package xmath
+
+// In-place operations.
+//
+// All the methods of Vector, Matrix and Dense return new instances and leave their receiver and arguments untouched,
+// unless their name ends with InPlace or Into.
+// InPlace methods mutate and return their receiver, so that they can be chained e.g. v.AddInPlace(w).ScaleInPlace(2).
+// Into methods write their result to the given destination, which must not overlap with the inputs, and return it.
+
+// Zero sets all elements of the vector to zero.
+func (v Vector) Zero() Vector {
+	for i := range v {
+		v[i] = 0
+	}
+	return v
+}
+
+// AddInPlace adds w to v, mutating v.
+func (v Vector) AddInPlace(w Vector) Vector {
+	MustHaveSameSize(v, w)
+	for i := range v {
+		v[i] += w[i]
+	}
+	return v
+}
+
+// DiffInPlace subtracts w from v, mutating v.
+func (v Vector) DiffInPlace(w Vector) Vector {
+	MustHaveSameSize(v, w)
+	for i := range v {
+		v[i] -= w[i]
+	}
+	return v
+}
+
+// AddScaledInPlace adds w scaled by s to v, mutating v.
+func (v Vector) AddScaledInPlace(s float64, w Vector) Vector {
+	MustHaveSameSize(v, w)
+	for i := range v {
+		v[i] += s * w[i]
+	}
+	return v
+}
+
+// ScaleInPlace multiplies all elements of v with s, mutating v.
+func (v Vector) ScaleInPlace(s float64) Vector {
+	for i := range v {
+		v[i] *= s
+	}
+	return v
+}
+
+// XInPlace applies the hadamard product of v and w, mutating v.
+func (v Vector) XInPlace(w Vector) Vector {
+	MustHaveSameSize(v, w)
+	for i := range v {
+		v[i] *= w[i]
+	}
+	return v
+}
+
+// OpInPlace applies the transform to each of the elements of v, mutating v.
+func (v Vector) OpInPlace(transform Op) Vector {
+	for i := range v {
+		v[i] = transform(v[i])
+	}
+	return v
+}
+
+// DopInPlace applies the transform to each pair of corresponding elements of v and w, mutating v.
+func (v Vector) DopInPlace(transform Dop, w Vector) Vector {
+	MustHaveSameSize(v, w)
+	for i := range v {
+		v[i] = transform(v[i], w[i])
+	}
+	return v
+}
+
+// AddInPlace adds n to m, mutating the rows of m.
+func (m Matrix) AddInPlace(n Matrix) Matrix {
+	MustHaveDim(n, len(m))
+	for i := range m {
+		m[i].AddInPlace(n[i])
+	}
+	return m
+}
+
+// ScaleInPlace multiplies all elements of m with s, mutating the rows of m.
+func (m Matrix) ScaleInPlace(s float64) Matrix {
+	for i := range m {
+		m[i].ScaleInPlace(s)
+	}
+	return m
+}
+
+// OpInPlace applies the transform to each of the elements of m, mutating the rows of m.
+func (m Matrix) OpInPlace(transform Op) Matrix {
+	for i := range m {
+		m[i].OpInPlace(transform)
+	}
+	return m
+}
+
+// ProdInto writes the product of the matrix with the vector v into dst.
+// It goes through the same blocked and parallel kernel as Dot, with v as the single row of the other operand.
+func (m Matrix) ProdInto(dst, v Vector) Vector {
+	MustHaveSize(dst, len(m))
+	for i := range m {
+		MustHaveSameSize(m[i], v)
+	}
+	m.prodInto(dst, v, workers(len(m), len(m)*len(v), parallelThreshold))
+	return dst
+}
+
+// prodInto writes the product of m with the vector v into dst, split across the given number of workers.
+func (m Matrix) prodInto(dst, v Vector, workers int) {
+	dst.Zero()
+	gemm(dst, m, Matrix{v}, workers)
+}
@@ -0,0 +1,139 @@
+package xmath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVector_InPlace(t *testing.T) {
+
+	type test struct {
+		inPlace func(v Vector) Vector
+		pure    func(v Vector) Vector
+	}
+
+	w := Vec(3).With(1, 2, 3)
+
+	tests := map[string]test{
+		"add": {
+			inPlace: func(v Vector) Vector { return v.AddInPlace(w) },
+			pure:    func(v Vector) Vector { return v.Add(w) },
+		},
+		"diff": {
+			inPlace: func(v Vector) Vector { return v.DiffInPlace(w) },
+			pure:    func(v Vector) Vector { return v.Diff(w) },
+		},
+		"add-scaled": {
+			inPlace: func(v Vector) Vector { return v.AddScaledInPlace(0.5, w) },
+			pure:    func(v Vector) Vector { return v.Add(w.Mult(0.5)) },
+		},
+		"scale": {
+			inPlace: func(v Vector) Vector { return v.ScaleInPlace(3) },
+			pure:    func(v Vector) Vector { return v.Mult(3) },
+		},
+		"hadamard": {
+			inPlace: func(v Vector) Vector { return v.XInPlace(w) },
+			pure:    func(v Vector) Vector { return v.X(w) },
+		},
+		"op": {
+			inPlace: func(v Vector) Vector { return v.OpInPlace(Square) },
+			pure:    func(v Vector) Vector { return v.Op(Square) },
+		},
+		"dop": {
+			inPlace: func(v Vector) Vector { return v.DopInPlace(Mult, w) },
+			pure:    func(v Vector) Vector { return v.Dop(Mult, w) },
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			v := Vec(3).With(4, 5, 6)
+			expected := tt.pure(v)
+			// the pure operation leaves the vector untouched
+			assert.Equal(t, Vec(3).With(4, 5, 6), v)
+			result := tt.inPlace(v)
+			assert.Equal(t, expected, result)
+			// the in-place operation mutates the vector itself
+			assert.Equal(t, expected, v)
+		})
+	}
+
+	assert.Panics(t, func() {
+		Vec(2).AddInPlace(w)
+	})
+
+}
+
+func TestMatrix_InPlace(t *testing.T) {
+
+	m := testMatrix()
+	v := Vec(2).With(1, 2)
+
+	assert.Equal(t, m.Prod(v), m.ProdInto(Vec(3), v))
+	assert.Equal(t, m.Add(m), m.Copy().AddInPlace(m))
+	assert.Equal(t, m.Mult(2), m.Copy().ScaleInPlace(2))
+	assert.Equal(t, m.Op(Sqrt), m.Copy().OpInPlace(Sqrt))
+
+}
+
+func TestDense_InPlace(t *testing.T) {
+
+	m := testMatrix()
+	v := Vec(3).With(1, 2, 3)
+	w := Vec(2).With(1, 2)
+
+	for name, d := range map[string]Dense{
+		"row-major":  DenseOf(m),
+		"transposed": DenseOf(m.T()).T(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, m.Prod(w), d.ProdInto(Vec(3).Generate(Const(1)), w))
+			assert.Equal(t, m.T().Prod(v), d.T().ProdInto(Vec(2).Generate(Const(1)), v))
+
+			assert.Equal(t, m.Add(m), d.Copy().AddInPlace(d).Matrix())
+			assert.Equal(t, m.Mult(2), d.Copy().ScaleInPlace(2).Matrix())
+			assert.Equal(t, m.Op(Sqrt), d.Copy().OpInPlace(Sqrt).Matrix())
+			assert.Equal(t, m.Add(v.Prod(w).Mult(0.5)), d.Copy().AddOuterInPlace(0.5, v, w).Matrix())
+
+			// the in-place operations mutate the shared data
+			e := d.T()
+			d.ScaleInPlace(0)
+			assert.Equal(t, NewDense(2, 3).Matrix(), e.Matrix())
+		})
+	}
+
+	assert.Panics(t, func() {
+		DenseOf(m).AddOuterInPlace(1, w, v)
+	})
+
+}
+
+func TestPool(t *testing.T) {
+
+	pool := Pool{}
+
+	for _, n := range []int{0, 1, 2, 3, 5, 8, 100} {
+		v := pool.Get(n)
+		assert.Equal(t, n, len(v))
+		assert.True(t, cap(v) >= n)
+		assert.Equal(t, Vec(n), v)
+		for i := range v {
+			v[i] = 1
+		}
+		pool.Put(v)
+		// re-used vectors come back zeroed
+		w := pool.Get(n)
+		assert.Equal(t, Vec(n), w)
+		pool.Put(w)
+	}
+
+}
+
+func BenchmarkPool_Get(b *testing.B) {
+	pool := Pool{}
+	for i := 0; i < b.N; i++ {
+		v := pool.Get(200)
+		pool.Put(v)
+	}
+}
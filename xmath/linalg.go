@@ -0,0 +1,417 @@
+package xmath
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrSingular is returned when a matrix cannot be inverted, or a system cannot be solved uniquely.
+var ErrSingular = errors.New("matrix is singular")
+
+// ErrNotPositiveDefinite is returned when the Cholesky decomposition is applied to a matrix that is not positive definite.
+var ErrNotPositiveDefinite = errors.New("matrix is not positive definite")
+
+// ErrNotSymmetric is returned when an operation for symmetric matrices is applied to a non-symmetric one.
+var ErrNotSymmetric = errors.New("matrix is not symmetric")
+
+// tolerance is the relative magnitude below which pivots are considered to be zero.
+const tolerance = 1e-12
+
+// Identity creates a new identity matrix of the given size.
+func Identity(n int) Matrix {
+	m := Mat(n).Of(n)
+	for i := range m {
+		m[i][i] = 1
+	}
+	return m
+}
+
+// dims returns the dimensions of the matrix, checking that all rows have the same size.
+func dims(m Matrix) (rows, cols int, err error) {
+	if len(m) == 0 {
+		return 0, 0, fmt.Errorf("matrix is empty")
+	}
+	cols = len(m[0])
+	for i := range m {
+		if len(m[i]) != cols {
+			return 0, 0, &ShapeError{
+				Location: fmt.Sprintf("matrix row %d", i),
+				Expected: []int{cols},
+				Actual:   []int{len(m[i])},
+			}
+		}
+	}
+	return len(m), cols, nil
+}
+
+// square returns the size of the square matrix, or an error if the matrix is not square.
+func square(m Matrix) (int, error) {
+	rows, cols, err := dims(m)
+	if err != nil {
+		return 0, err
+	}
+	if rows != cols {
+		return 0, &ShapeError{
+			Location: "square matrix",
+			Expected: []int{rows, rows},
+			Actual:   []int{rows, cols},
+		}
+	}
+	return rows, nil
+}
+
+// maxAbs returns the largest absolute element of the matrix, as the scale for the singularity checks.
+func maxAbs(m Matrix) float64 {
+	var max float64
+	for i := range m {
+		for _, x := range m[i] {
+			max = math.Max(max, math.Abs(x))
+		}
+	}
+	return max
+}
+
+// LU is the LU decomposition with partial pivoting of a square matrix e.g. P*A = L*U.
+type LU struct {
+	// lu holds L below the diagonal, with an implicit unit diagonal, and U on and above it.
+	lu    Matrix
+	pivot []int
+	sign  float64
+	scale float64
+}
+
+// LU decomposes the square matrix into a lower and an upper triangular one, with partial pivoting.
+// The decomposition of a singular matrix succeeds, but it cannot be used to solve systems.
+func (m Matrix) LU() (*LU, error) {
+	n, err := square(m)
+	if err != nil {
+		return nil, fmt.Errorf("could not decompose matrix: %w", err)
+	}
+	lu := m.Copy()
+	pivot := make([]int, n)
+	for i := range pivot {
+		pivot[i] = i
+	}
+	sign := 1.0
+	for k := 0; k < n; k++ {
+		// find the pivot
+		p := k
+		for i := k + 1; i < n; i++ {
+			if math.Abs(lu[i][k]) > math.Abs(lu[p][k]) {
+				p = i
+			}
+		}
+		if p != k {
+			lu[p], lu[k] = lu[k], lu[p]
+			pivot[p], pivot[k] = pivot[k], pivot[p]
+			sign = -sign
+		}
+		if lu[k][k] == 0 {
+			continue
+		}
+		for i := k + 1; i < n; i++ {
+			f := lu[i][k] / lu[k][k]
+			lu[i][k] = f
+			axpy(-f, lu[k][k+1:], lu[i][k+1:])
+		}
+	}
+	return &LU{
+		lu:    lu,
+		pivot: pivot,
+		sign:  sign,
+		scale: maxAbs(m),
+	}, nil
+}
+
+// L returns the lower triangular matrix with unit diagonal.
+func (d *LU) L() Matrix {
+	l := Identity(len(d.lu))
+	for i := range l {
+		copy(l[i][:i], d.lu[i][:i])
+	}
+	return l
+}
+
+// U returns the upper triangular matrix.
+func (d *LU) U() Matrix {
+	u := Mat(len(d.lu)).Of(len(d.lu))
+	for i := range u {
+		copy(u[i][i:], d.lu[i][i:])
+	}
+	return u
+}
+
+// P returns the row permutation matrix.
+func (d *LU) P() Matrix {
+	p := Mat(len(d.lu)).Of(len(d.lu))
+	for i, j := range d.pivot {
+		p[i][j] = 1
+	}
+	return p
+}
+
+// Singular checks if the decomposed matrix is singular.
+func (d *LU) Singular() bool {
+	for i := range d.lu {
+		if math.Abs(d.lu[i][i]) <= tolerance*d.scale {
+			return true
+		}
+	}
+	return false
+}
+
+// Det returns the determinant of the decomposed matrix.
+func (d *LU) Det() float64 {
+	det := d.sign
+	for i := range d.lu {
+		det *= d.lu[i][i]
+	}
+	return det
+}
+
+// Solve solves the system A*x = b for the decomposed matrix A.
+func (d *LU) Solve(b Vector) (Vector, error) {
+	if err := ExpectSize("lu solve", b, len(d.lu)); err != nil {
+		return nil, err
+	}
+	if d.Singular() {
+		return nil, ErrSingular
+	}
+	n := len(d.lu)
+	x := Vec(n)
+	// forward substitution on the permuted vector
+	for i := 0; i < n; i++ {
+		x[i] = b[d.pivot[i]] - dot(d.lu[i][:i], x[:i])
+	}
+	// back substitution
+	for i := n - 1; i >= 0; i-- {
+		x[i] = (x[i] - dot(d.lu[i][i+1:], x[i+1:])) / d.lu[i][i]
+	}
+	return x, nil
+}
+
+// Det returns the determinant of the square matrix.
+func (m Matrix) Det() (float64, error) {
+	lu, err := m.LU()
+	if err != nil {
+		return 0, err
+	}
+	return lu.Det(), nil
+}
+
+// Inverse returns the inverse of the square matrix, or ErrSingular if it cannot be inverted.
+func (m Matrix) Inverse() (Matrix, error) {
+	lu, err := m.LU()
+	if err != nil {
+		return nil, err
+	}
+	n := len(m)
+	inv := Mat(n)
+	e := Vec(n)
+	for j := 0; j < n; j++ {
+		e.Zero()
+		e[j] = 1
+		col, err := lu.Solve(e)
+		if err != nil {
+			return nil, err
+		}
+		inv[j] = col
+	}
+	// we solved for the columns
+	return inv.T(), nil
+}
+
+// Solve solves the linear system a*x = b for a square matrix a,
+// or returns ErrSingular if there is no unique solution.
+func Solve(a Matrix, b Vector) (Vector, error) {
+	lu, err := a.LU()
+	if err != nil {
+		return nil, err
+	}
+	return lu.Solve(b)
+}
+
+// LeastSquares finds the x that minimises |a*x - b| for a matrix with at least as many rows as columns,
+// or returns ErrSingular if the columns of a are linearly dependent.
+func LeastSquares(a Matrix, b Vector) (Vector, error) {
+	qr, err := a.QR()
+	if err != nil {
+		return nil, err
+	}
+	return qr.Solve(b)
+}
+
+// Cholesky is the decomposition of a symmetric positive definite matrix e.g. A = L*L^T.
+type Cholesky struct {
+	l Matrix
+}
+
+// Cholesky decomposes the symmetric positive definite matrix into a lower triangular one.
+func (m Matrix) Cholesky() (*Cholesky, error) {
+	n, err := square(m)
+	if err != nil {
+		return nil, fmt.Errorf("could not decompose matrix: %w", err)
+	}
+	if !m.symmetric() {
+		return nil, ErrNotSymmetric
+	}
+	l := Mat(n).Of(n)
+	for j := 0; j < n; j++ {
+		d := m[j][j] - dot(l[j][:j], l[j][:j])
+		if d <= 0 {
+			return nil, ErrNotPositiveDefinite
+		}
+		l[j][j] = math.Sqrt(d)
+		for i := j + 1; i < n; i++ {
+			l[i][j] = (m[i][j] - dot(l[i][:j], l[j][:j])) / l[j][j]
+		}
+	}
+	return &Cholesky{l: l}, nil
+}
+
+// L returns the lower triangular matrix of the decomposition.
+func (c *Cholesky) L() Matrix {
+	return c.l.Copy()
+}
+
+// Solve solves the system A*x = b for the decomposed matrix A.
+func (c *Cholesky) Solve(b Vector) (Vector, error) {
+	n := len(c.l)
+	if err := ExpectSize("cholesky solve", b, n); err != nil {
+		return nil, err
+	}
+	// L*y = b
+	y := Vec(n)
+	for i := 0; i < n; i++ {
+		y[i] = (b[i] - dot(c.l[i][:i], y[:i])) / c.l[i][i]
+	}
+	// L^T*x = y
+	x := Vec(n)
+	for i := n - 1; i >= 0; i-- {
+		s := y[i]
+		for k := i + 1; k < n; k++ {
+			s -= c.l[k][i] * x[k]
+		}
+		x[i] = s / c.l[i][i]
+	}
+	return x, nil
+}
+
+// symmetric checks if the square matrix is symmetric, within the tolerance.
+func (m Matrix) symmetric() bool {
+	eps := tolerance * maxAbs(m)
+	for i := range m {
+		for j := 0; j < i; j++ {
+			if math.Abs(m[i][j]-m[j][i]) > eps {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// QR is the thin QR decomposition of a matrix with at least as many rows as columns e.g. A = Q*R,
+// where Q has orthonormal columns and R is upper triangular.
+type QR struct {
+	q, r  Matrix
+	scale float64
+}
+
+// QR decomposes the matrix into an orthogonal and an upper triangular one, using Householder reflections.
+func (m Matrix) QR() (*QR, error) {
+	rows, cols, err := dims(m)
+	if err != nil {
+		return nil, fmt.Errorf("could not decompose matrix: %w", err)
+	}
+	if rows < cols {
+		return nil, fmt.Errorf("could not decompose matrix with fewer rows than columns: %w", &ShapeError{
+			Location: "qr",
+			Expected: []int{cols, cols},
+			Actual:   []int{rows, cols},
+		})
+	}
+	a := m.Copy()
+	reflections := make([]Vector, cols)
+	for k := 0; k < cols; k++ {
+		v := Vec(rows - k)
+		for i := range v {
+			v[i] = a[k+i][k]
+		}
+		norm := v.Norm()
+		if norm == 0 {
+			continue
+		}
+		alpha := -norm
+		if v[0] < 0 {
+			alpha = norm
+		}
+		v[0] -= alpha
+		reflect(v, a[k:], k)
+		reflections[k] = v
+	}
+	// accumulate the reflections on the first columns of the identity
+	q := Mat(rows).Of(cols)
+	for j := 0; j < cols; j++ {
+		q[j][j] = 1
+	}
+	for k := cols - 1; k >= 0; k-- {
+		if reflections[k] != nil {
+			reflect(reflections[k], q[k:], 0)
+		}
+	}
+	r := Mat(cols).Of(cols)
+	for i := range r {
+		copy(r[i][i:], a[i][i:cols])
+	}
+	return &QR{
+		q:     q,
+		r:     r,
+		scale: maxAbs(m),
+	}, nil
+}
+
+// reflect applies the Householder reflection I - 2*v*v^T/(v^T*v) to the columns of a, starting from the given one.
+func reflect(v Vector, a Matrix, from int) {
+	vv := v.Dot(v)
+	if vv == 0 {
+		return
+	}
+	for j := from; j < len(a[0]); j++ {
+		var s float64
+		for i := range v {
+			s += v[i] * a[i][j]
+		}
+		f := 2 * s / vv
+		for i := range v {
+			a[i][j] -= f * v[i]
+		}
+	}
+}
+
+// Q returns the matrix with the orthonormal columns.
+func (d *QR) Q() Matrix {
+	return d.q.Copy()
+}
+
+// R returns the upper triangular matrix.
+func (d *QR) R() Matrix {
+	return d.r.Copy()
+}
+
+// Solve finds the least squares solution of the system A*x = b for the decomposed matrix A.
+func (d *QR) Solve(b Vector) (Vector, error) {
+	if err := ExpectSize("qr solve", b, len(d.q)); err != nil {
+		return nil, err
+	}
+	n := len(d.r)
+	// x = R^-1 * Q^T * b
+	x := d.q.T().Prod(b)
+	for i := n - 1; i >= 0; i-- {
+		if math.Abs(d.r[i][i]) <= tolerance*d.scale {
+			return nil, ErrSingular
+		}
+		x[i] = (x[i] - dot(d.r[i][i+1:], x[i+1:])) / d.r[i][i]
+	}
+	return x, nil
+}
@@ -0,0 +1,194 @@
+package xmath
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mul returns the standard matrix product a*b.
+func mul(a, b Matrix) Matrix {
+	return a.Dot(b.T())
+}
+
+func TestMatrix_LU(t *testing.T) {
+
+	type test struct {
+		m        Matrix
+		det      float64
+		singular bool
+	}
+
+	tests := map[string]test{
+		"identity": {
+			m:   Identity(3),
+			det: 1,
+		},
+		"pivoting": {
+			m: Mat(3).With(
+				Vec(3).With(0, 2, 1),
+				Vec(3).With(1, 1, 1),
+				Vec(3).With(2, 1, 3),
+			),
+			det: -3,
+		},
+		"random": {
+			m:   randMatrix(5, 5, 7),
+			det: math.NaN(),
+		},
+		"singular": {
+			m: Mat(3).With(
+				Vec(3).With(1, 2, 3),
+				Vec(3).With(2, 4, 6),
+				Vec(3).With(1, 0, 1),
+			),
+			det:      0,
+			singular: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			lu, err := tt.m.LU()
+			assert.NoError(t, err)
+			assertMatrixInDelta(t, mul(lu.P(), tt.m), mul(lu.L(), lu.U()))
+
+			det, err := tt.m.Det()
+			assert.NoError(t, err)
+			if !math.IsNaN(tt.det) {
+				assert.InDelta(t, tt.det, det, 1e-9)
+			}
+
+			b := Vec(len(tt.m)).Generate(Const(1))
+			x, err := Solve(tt.m, b)
+			inv, invErr := tt.m.Inverse()
+			if tt.singular {
+				assert.Equal(t, ErrSingular, err)
+				assert.Equal(t, ErrSingular, invErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.InDeltaSlice(t, b, tt.m.Prod(x), 1e-9)
+			assert.NoError(t, invErr)
+			assertMatrixInDelta(t, Identity(len(tt.m)), mul(tt.m, inv))
+		})
+	}
+
+	_, err := Mat(2).Of(3).LU()
+	assert.Error(t, err)
+	_, err = Solve(Identity(2), Vec(3))
+	assert.Error(t, err)
+
+}
+
+func TestMatrix_Cholesky(t *testing.T) {
+
+	a := randMatrix(4, 4, 3)
+	// a*a^T + I is symmetric positive definite
+	spd := mul(a, a.T()).Add(Identity(4))
+
+	chol, err := spd.Cholesky()
+	assert.NoError(t, err)
+	l := chol.L()
+	assertMatrixInDelta(t, spd, mul(l, l.T()))
+	for i := range l {
+		for j := i + 1; j < len(l); j++ {
+			assert.Equal(t, 0.0, l[i][j])
+		}
+	}
+
+	b := Vec(4).With(1, 2, 3, 4)
+	x, err := chol.Solve(b)
+	assert.NoError(t, err)
+	assert.InDeltaSlice(t, b, spd.Prod(x), 1e-9)
+
+	_, err = Mat(2).With(Vec(2).With(1, 2), Vec(2).With(2, 1)).Cholesky()
+	assert.Equal(t, ErrNotPositiveDefinite, err)
+	_, err = Mat(2).With(Vec(2).With(1, 2), Vec(2).With(0, 1)).Cholesky()
+	assert.Equal(t, ErrNotSymmetric, err)
+
+}
+
+func TestMatrix_QR(t *testing.T) {
+
+	a := randMatrix(6, 3, 5)
+
+	qr, err := a.QR()
+	assert.NoError(t, err)
+	q := qr.Q()
+	r := qr.R()
+	assertMatrixInDelta(t, a, mul(q, r))
+	assertMatrixInDelta(t, Identity(3), mul(q.T(), q))
+
+	// fit an exact linear relation
+	coefficients := Vec(3).With(1, -2, 0.5)
+	b := a.Prod(coefficients)
+	x, err := LeastSquares(a, b)
+	assert.NoError(t, err)
+	assert.InDeltaSlice(t, coefficients, x, 1e-9)
+
+	// linearly dependent columns
+	dependent := Mat(3).With(
+		Vec(2).With(1, 2),
+		Vec(2).With(2, 4),
+		Vec(2).With(3, 6),
+	)
+	_, err = LeastSquares(dependent, Vec(3))
+	assert.Equal(t, ErrSingular, err)
+
+	_, err = a.T().QR()
+	assert.Error(t, err)
+
+}
+
+func TestMatrix_Eigen(t *testing.T) {
+
+	m := Mat(3).With(
+		Vec(3).With(2, -1, 0),
+		Vec(3).With(-1, 2, -1),
+		Vec(3).With(0, -1, 2),
+	)
+
+	values, vectors, err := m.Eigen()
+	assert.NoError(t, err)
+	assert.InDeltaSlice(t, Vec(3).With(2+math.Sqrt2, 2, 2-math.Sqrt2), values, 1e-9)
+	for i := range values {
+		assert.InDeltaSlice(t, vectors[i].Mult(values[i]), m.Prod(vectors[i]), 1e-9)
+		assert.InDelta(t, 1, vectors[i].Norm(), 1e-9)
+	}
+
+	_, _, err = Mat(2).With(Vec(2).With(1, 2), Vec(2).With(0, 1)).Eigen()
+	assert.Equal(t, ErrNotSymmetric, err)
+
+}
+
+func TestMatrix_SVD(t *testing.T) {
+
+	type test struct {
+		m Matrix
+		k int
+	}
+
+	tests := map[string]test{
+		"tall":   {m: randMatrix(5, 3, 1), k: 3},
+		"wide":   {m: randMatrix(2, 4, 2), k: 2},
+		"square": {m: randMatrix(4, 4, 3), k: 4},
+		"rank-1": {m: Vec(3).With(1, 2, 3).Prod(Vec(2).With(1, -1)), k: 2},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			u, s, v, err := tt.m.SVD()
+			assert.NoError(t, err)
+			assert.Equal(t, tt.k, len(s))
+			for i := 1; i < len(s); i++ {
+				assert.True(t, s[i-1] >= s[i])
+			}
+			// A = U * diag(s) * V^T
+			assertMatrixInDelta(t, tt.m, mul(mul(u, Diag(s)), v.T()))
+			assertMatrixInDelta(t, Identity(tt.k), mul(v.T(), v))
+		})
+	}
+
+}
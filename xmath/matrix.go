@@ -56,26 +56,33 @@ func (m Matrix) Add(v Matrix) Matrix {
 	return w
 }
 
-// Dot returns the product of the given matrix with the matrix
+// Dot returns the product of the matrix with the transpose of the given matrix
+// e.g. the element at (i,j) is the dot product of the i-th row of m with the j-th row of v.
+// Both operands are traversed in blocks along their rows, and large products are split across goroutines.
 func (m Matrix) Dot(v Matrix) Matrix {
 	w := Mat(len(m))
+	if len(m) == 0 {
+		return w
+	}
+	k := len(m[0])
 	for i := range m {
-		for j := 0; j < len(v); j++ {
-			MustHaveSameSize(m[i], v[j])
-			w[i][j] = m[i].Dot(v[j])
-		}
+		MustHaveSize(m[i], k)
+	}
+	for j := range v {
+		MustHaveSize(v[j], k)
+	}
+	n := len(v)
+	out := Vec(len(m) * n)
+	gemm(out, m, v, workers(len(m), len(m)*n*k, parallelThreshold))
+	for i := range w {
+		w[i] = out[i*n : (i+1)*n : (i+1)*n]
 	}
 	return w
 }
 
 // Prod returns the cross product of the given vector with the matrix
 func (m Matrix) Prod(v Vector) Vector {
-	w := Vec(len(m))
-	for i := range m {
-		MustHaveSameSize(m[i], v)
-		w[i] = m[i].Dot(v)
-	}
-	return w
+	return m.ProdInto(Vec(len(m)), v)
 }
 
 // Mult multiplies each element of the matrix with the given factor
@@ -0,0 +1,47 @@
+package xmath
+
+import (
+	"math/bits"
+	"sync"
+)
+
+// Pool is a pool of reusable vectors, to avoid allocations for short-lived buffers in hot paths.
+// Vectors are kept in buckets of power-of-two capacities.
+// The zero value is ready to use and it is safe for concurrent use.
+type Pool struct {
+	buckets [64]sync.Pool
+}
+
+// bucket returns the index of the bucket that holds vectors of capacity at least n.
+func bucket(n int) int {
+	if n <= 1 {
+		return 0
+	}
+	return bits.Len(uint(n - 1))
+}
+
+// Get returns a zero vector of size n.
+// The vector should be returned to the pool with Put once it is no longer in use.
+func (p *Pool) Get(n int) Vector {
+	b := bucket(n)
+	if v, ok := p.buckets[b].Get().(*Vector); ok {
+		return (*v)[:n].Zero()
+	}
+	return make(Vector, n, 1<<b)
+}
+
+// Put returns the vector to the pool.
+// The vector must not be used after this call.
+func (p *Pool) Put(v Vector) {
+	c := cap(v)
+	if c == 0 {
+		return
+	}
+	// only keep vectors that can serve the whole range of their bucket
+	b := bucket(c)
+	if 1<<b != c {
+		b--
+	}
+	v = v[:c]
+	p.buckets[b].Put(&v)
+}
@@ -0,0 +1,365 @@
+package series
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/drakos74/go-ex-machina/xmath"
+)
+
+// Acquisition scores a candidate combination out of the predicted mean and standard deviation of its score,
+// and the best score observed so far, where higher is more promising.
+// Lower scores are better, as for the Halving.
+type Acquisition func(mean, std, best float64) float64
+
+// ExpectedImprovement favours the candidates with the highest expected improvement over the best score.
+// A positive xi favours exploring uncertain candidates, over refining the best one.
+func ExpectedImprovement(xi float64) Acquisition {
+	return func(mean, std, best float64) float64 {
+		improvement := best - mean - xi
+		if std <= 0 {
+			return math.Max(improvement, 0)
+		}
+		z := improvement / std
+		cdf := 0.5 * math.Erfc(-z/math.Sqrt2)
+		pdf := math.Exp(-z*z/2) / math.Sqrt(2*math.Pi)
+		return improvement*cdf + std*pdf
+	}
+}
+
+// UpperConfidenceBound favours the candidates with the lowest optimistic score e.g. mean - kappa * std.
+// A higher kappa favours exploring uncertain candidates.
+func UpperConfidenceBound(kappa float64) Acquisition {
+	return func(mean, std, best float64) float64 {
+		return kappa*std - mean
+	}
+}
+
+// Bayesian is a search that proposes the next combination out of the scores of the previous ones,
+// so that it converges with far fewer evaluations than the exhaustive Evolution.
+// The scores are modelled with a gaussian process over the quantiles of the parameter distributions,
+// and the next combination is the one that maximises the acquisition.
+// After each Next, the combination should be evaluated, and its score given with Report.
+// It is a Search, so that it can also be scheduled by a Halving, where the combinations are proposed as they are applied.
+type Bayesian struct {
+	params       []*Param
+	rng          *rand.Rand
+	limit        int
+	i            int
+	initial      int
+	candidates   int
+	acquisition  Acquisition
+	lengthScales []float64
+	noise        float64
+	// proposals are the quantiles of the proposed combinations, in the order of their index
+	proposals [][]float64
+	// points are the quantiles of the reported combinations, and scores their scores
+	points [][]float64
+	scores []float64
+	// current is the quantiles of the combination waiting for its score
+	current []float64
+}
+
+// NewBayesian creates a new bayesian search of n combinations over the given parameters.
+// By default, it samples 5 random combinations before following the expected improvement.
+func NewBayesian(n int, seed int64, params ...*Param) *Bayesian {
+	return &Bayesian{
+		params:       params,
+		rng:          rand.New(rand.NewSource(seed)),
+		limit:        n,
+		initial:      5,
+		candidates:   1000,
+		acquisition:  ExpectedImprovement(0.01),
+		lengthScales: []float64{0.05, 0.1, 0.2, 0.4, 0.8},
+		noise:        1e-6,
+		proposals:    make([][]float64, 0),
+		points:       make([][]float64, 0),
+		scores:       make([]float64, 0),
+	}
+}
+
+// WithAcquisition sets the acquisition to pick the next combination with.
+func (b *Bayesian) WithAcquisition(acquisition Acquisition) *Bayesian {
+	b.acquisition = acquisition
+	return b
+}
+
+// WithInitial sets the number of random combinations before the model is used.
+func (b *Bayesian) WithInitial(n int) *Bayesian {
+	if n < 1 {
+		n = 1
+	}
+	b.initial = n
+	return b
+}
+
+// WithCandidates sets the number of candidate combinations the acquisition is evaluated on for each proposal.
+func (b *Bayesian) WithCandidates(n int) *Bayesian {
+	if n < 1 {
+		n = 1
+	}
+	b.candidates = n
+	return b
+}
+
+// WithLengthScale fixes the distance in quantiles over which scores are correlated,
+// instead of picking the most likely one out of the reported scores.
+func (b *Bayesian) WithLengthScale(l float64) *Bayesian {
+	b.lengthScales = []float64{l}
+	return b
+}
+
+// WithNoise sets the variance of the scores noise, relative to the variance of the scores
+// e.g. for objectives that give different scores for the same combination.
+func (b *Bayesian) WithNoise(noise float64) *Bayesian {
+	b.noise = noise
+	return b
+}
+
+// Limit returns the number of combinations.
+func (b *Bayesian) Limit() int {
+	return b.limit
+}
+
+// Current returns the number of combinations iterated so far.
+func (b *Bayesian) Current() int {
+	return b.i
+}
+
+// Next updates the parameters with the next combination to evaluate,
+// it returns false once all combinations have been proposed.
+func (b *Bayesian) Next() bool {
+	if b.i >= b.limit {
+		return false
+	}
+	b.Apply(b.i)
+	b.i++
+	return true
+}
+
+// Apply updates the parameters with the combination at the given index, which is then the one waiting for its score.
+// The combinations up to the index are proposed if they have not been already,
+// out of the scores reported so far.
+func (b *Bayesian) Apply(index int) {
+	if index < 0 || index >= b.limit {
+		panic(fmt.Sprintf("combination index out of range '%v' vs '%v'", index, b.limit))
+	}
+	for len(b.proposals) <= index {
+		if len(b.scores) < b.initial {
+			b.proposals = append(b.proposals, b.random())
+		} else {
+			b.proposals = append(b.proposals, b.propose())
+		}
+	}
+	b.current = b.proposals[index]
+	b.apply(b.current)
+}
+
+// Report records the score of the current combination.
+// Scores that are NaN e.g. for failed evaluations, are ignored.
+func (b *Bayesian) Report(score float64) {
+	if b.current == nil {
+		panic("cannot report score before the first combination")
+	}
+	if !math.IsNaN(score) {
+		b.points = append(b.points, b.current)
+		b.scores = append(b.scores, score)
+	}
+	b.current = nil
+}
+
+// Best updates the parameters with the best reported combination and returns its score,
+// or NaN if there is none.
+func (b *Bayesian) Best() float64 {
+	i := b.best()
+	if i < 0 {
+		return math.NaN()
+	}
+	b.apply(b.points[i])
+	return b.scores[i]
+}
+
+// best returns the index of the lowest score.
+func (b *Bayesian) best() int {
+	best := -1
+	for i, s := range b.scores {
+		if best < 0 || s < b.scores[best] {
+			best = i
+		}
+	}
+	return best
+}
+
+// apply updates the parameters with the values at the given quantiles.
+func (b *Bayesian) apply(u []float64) {
+	for j, p := range b.params {
+		*p.value = p.distribution(u[j])
+	}
+}
+
+// random returns uniformly random quantiles.
+func (b *Bayesian) random() []float64 {
+	u := make([]float64, len(b.params))
+	for j := range u {
+		u[j] = b.rng.Float64()
+	}
+	return u
+}
+
+// propose returns the candidate with the highest acquisition.
+// Half of the candidates are random, and the others close to the best combination.
+func (b *Bayesian) propose() []float64 {
+	gp, err := b.fit()
+	if err != nil {
+		// the model could not be fitted, so keep exploring
+		return b.random()
+	}
+	best := b.points[b.best()]
+	var proposal []float64
+	score := math.Inf(-1)
+	for c := 0; c < b.candidates; c++ {
+		u := b.random()
+		if c%2 == 1 {
+			for j := range u {
+				u[j] = clip(best[j] + 0.05*b.rng.NormFloat64())
+			}
+		}
+		mean, std := gp.predict(u)
+		if a := b.acquisition(mean, std, gp.best); a > score || proposal == nil {
+			proposal = u
+			score = a
+		}
+	}
+	return proposal
+}
+
+// clip keeps the quantile within [0,1).
+func clip(u float64) float64 {
+	if u < 0 {
+		return 0
+	}
+	if u >= 1 {
+		return math.Nextafter(1, 0)
+	}
+	return u
+}
+
+// fit returns the gaussian process with the most likely length scale for the reported scores.
+// The scores are standardised, so that the prior of the process fits any scale of scores.
+func (b *Bayesian) fit() (*gaussianProcess, error) {
+	y := xmath.Vec(len(b.scores))
+	m := mean(b.scores)
+	var v float64
+	for _, s := range b.scores {
+		v += (s - m) * (s - m)
+	}
+	std := math.Sqrt(v / float64(len(b.scores)))
+	if std == 0 {
+		std = 1
+	}
+	for i, s := range b.scores {
+		y[i] = (s - m) / std
+	}
+
+	var gp *gaussianProcess
+	var err error
+	likelihood := math.Inf(-1)
+	for _, l := range b.lengthScales {
+		candidate, fitErr := newGaussianProcess(b.points, y, l, b.noise)
+		if fitErr != nil {
+			err = fitErr
+			continue
+		}
+		if ll := candidate.logLikelihood(y); gp == nil || ll > likelihood {
+			gp = candidate
+			likelihood = ll
+		}
+	}
+	if gp == nil {
+		return nil, err
+	}
+	gp.best = y[b.best()]
+	return gp, nil
+}
+
+// mean returns the average of the values.
+func mean(values []float64) float64 {
+	var s float64
+	for _, v := range values {
+		s += v
+	}
+	return s / float64(len(values))
+}
+
+// gaussianProcess predicts the standardised scores with a squared exponential kernel.
+type gaussianProcess struct {
+	points      [][]float64
+	lengthScale float64
+	cholesky    *xmath.Cholesky
+	// alpha is the inverse of the kernel matrix applied to the scores
+	alpha xmath.Vector
+	best  float64
+}
+
+// newGaussianProcess fits a gaussian process on the points and their scores.
+func newGaussianProcess(points [][]float64, y xmath.Vector, lengthScale, noise float64) (*gaussianProcess, error) {
+	gp := &gaussianProcess{
+		points:      points,
+		lengthScale: lengthScale,
+	}
+	k := xmath.Mat(len(points)).Of(len(points))
+	for i := range points {
+		for j := range points {
+			k[i][j] = gp.kernel(points[i], points[j])
+		}
+		k[i][i] += noise
+	}
+	cholesky, err := k.Cholesky()
+	if err != nil {
+		return nil, err
+	}
+	alpha, err := cholesky.Solve(y)
+	if err != nil {
+		return nil, err
+	}
+	gp.cholesky = cholesky
+	gp.alpha = alpha
+	return gp, nil
+}
+
+// kernel returns the prior correlation of the scores at the two points.
+func (gp *gaussianProcess) kernel(a, b []float64) float64 {
+	var d float64
+	for i := range a {
+		d += (a[i] - b[i]) * (a[i] - b[i])
+	}
+	return math.Exp(-d / (2 * gp.lengthScale * gp.lengthScale))
+}
+
+// logLikelihood returns the log marginal likelihood of the scores, up to a constant.
+func (gp *gaussianProcess) logLikelihood(y xmath.Vector) float64 {
+	ll := -y.Dot(gp.alpha) / 2
+	for i, row := range gp.cholesky.L() {
+		ll -= math.Log(row[i])
+	}
+	return ll
+}
+
+// predict returns the mean and standard deviation of the score at the given point.
+func (gp *gaussianProcess) predict(u []float64) (float64, float64) {
+	k := xmath.Vec(len(gp.points))
+	for i, p := range gp.points {
+		k[i] = gp.kernel(u, p)
+	}
+	mean := k.Dot(gp.alpha)
+	v, err := gp.cholesky.Solve(k)
+	if err != nil {
+		return mean, 0
+	}
+	variance := 1 - k.Dot(v)
+	if variance < 0 {
+		variance = 0
+	}
+	return mean, math.Sqrt(variance)
+}
@@ -0,0 +1,144 @@
+package series
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAcquisition(t *testing.T) {
+
+	type test struct {
+		acquisition Acquisition
+	}
+
+	tests := map[string]test{
+		"expected-improvement":   {acquisition: ExpectedImprovement(0)},
+		"upper-confidence-bound": {acquisition: UpperConfidenceBound(2)},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// lower means are more promising
+			assert.True(t, tt.acquisition(-1, 0.1, 0) > tt.acquisition(1, 0.1, 0))
+			// so are more uncertain ones for the same mean
+			assert.True(t, tt.acquisition(1, 1, 0) > tt.acquisition(1, 0.1, 0))
+		})
+	}
+
+	// without uncertainty the expected improvement is the improvement
+	assert.Equal(t, 0.5, ExpectedImprovement(0)(-0.5, 0, 0))
+	assert.Equal(t, 0.0, ExpectedImprovement(0)(0.5, 0, 0))
+
+}
+
+func TestBayesian(t *testing.T) {
+
+	type test struct {
+		acquisition Acquisition
+	}
+
+	tests := map[string]test{
+		"expected-improvement":   {acquisition: ExpectedImprovement(0.01)},
+		"upper-confidence-bound": {acquisition: UpperConfidenceBound(2)},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var x, rate float64
+			// the minimum is at x = 2 and rate = 0.01
+			score := func() float64 {
+				return math.Pow(x-2, 2) + math.Pow(math.Log10(rate)+2, 2)
+			}
+
+			b := NewBayesian(30, 1,
+				NewParam(&x, Uniform(-5, 5)),
+				NewParam(&rate, LogUniform(0.0001, 1)),
+			).WithAcquisition(tt.acquisition)
+
+			for b.Next() {
+				assert.True(t, x >= -5 && x < 5)
+				assert.True(t, rate >= 0.0001 && rate < 1)
+				b.Report(score())
+			}
+			assert.Equal(t, 30, b.Current())
+
+			best := b.Best()
+			assert.Equal(t, score(), best)
+			assert.True(t, best < 0.1, "%v at x = %v , rate = %v", best, x, rate)
+
+			// random sampling does not get as close for the same number of evaluations
+			r := NewRandomSampling(30, 1,
+				NewParam(&x, Uniform(-5, 5)),
+				NewParam(&rate, LogUniform(0.0001, 1)),
+			)
+			random := math.Inf(1)
+			for r.Next() {
+				random = math.Min(random, score())
+			}
+			assert.True(t, best < random, "%v vs %v", best, random)
+		})
+	}
+
+}
+
+func TestBayesian_Report(t *testing.T) {
+
+	var x float64
+	b := NewBayesian(10, 1, NewParam(&x, Uniform(0, 1))).WithInitial(2)
+
+	assert.True(t, math.IsNaN(b.Best()))
+	assert.Panics(t, func() {
+		b.Report(1)
+	})
+
+	for i := 0; b.Next(); i++ {
+		if i%2 == 0 {
+			// failed evaluations are ignored
+			b.Report(math.NaN())
+		} else {
+			b.Report(math.Abs(x - 0.5))
+		}
+	}
+	assert.Equal(t, 10, b.Current())
+	assert.Equal(t, 5, len(b.scores))
+	assert.False(t, b.Next())
+	assert.False(t, math.IsNaN(b.Best()))
+	assert.InDelta(t, 0.5, x, 0.25)
+
+}
+
+func TestBayesian_Search(t *testing.T) {
+
+	var x float64
+	var search Search = NewBayesian(9, 1, NewParam(&x, Uniform(0, 1)))
+
+	// the combinations can be applied again by their index
+	values := make([]float64, 0)
+	for search.Next() {
+		values = append(values, x)
+	}
+	assert.Equal(t, 9, search.Current())
+	for i := range values {
+		search.Apply(i)
+		assert.Equal(t, values[i], x)
+	}
+	assert.Panics(t, func() {
+		search.Apply(9)
+	})
+
+	// a halving schedule proposes the combinations as it applies them
+	b := NewBayesian(9, 1, NewParam(&x, Uniform(0, 1)))
+	h := NewSuccessiveHalving(b, 1, 3)
+	for h.Next() {
+		score := math.Abs(x-0.5) / h.Budget()
+		b.Report(score)
+		h.Report(score)
+	}
+	assert.Equal(t, 0, b.Current())
+	best, score := h.Best()
+	b.Apply(best)
+	assert.Equal(t, math.Abs(x-0.5)/9, score)
+
+}
@@ -1,6 +1,11 @@
 package series
 
-import "github.com/drakos74/go-ex-machina/xmath"
+import (
+	"fmt"
+	"math"
+
+	"github.com/drakos74/go-ex-machina/xmath"
+)
 
 type Evolution struct {
 	i            int
@@ -42,13 +47,19 @@ func (e *Evolution) Next() bool {
 	if e.i >= len(e.combinations) {
 		return false
 	}
-	for i, value := range e.combinations[e.i] {
-		e.procedures[i].set(value)
-	}
+	e.Apply(e.i)
 	e.i++
 	return true
 }
 
+// Apply updates the procedures with the values of the combination at the given index
+// e.g. to revisit a combination that was evaluated before.
+func (e *Evolution) Apply(index int) {
+	for i, value := range e.combinations[index] {
+		e.procedures[i].set(value)
+	}
+}
+
 type Sequence struct {
 	initialValue float64
 	value        *float64
@@ -84,6 +95,51 @@ func RangeSequence(value *float64, start, end float64, limit, rounding int) *Seq
 	return s
 }
 
+// LogSequence creates a sequence of limit values from start to end, evenly spaced on a logarithmic scale
+// e.g. 0.001, 0.01, 0.1, 1 for learning rates.
+func LogSequence(value *float64, start, end float64, limit int) *Sequence {
+	if start <= 0 || end <= 0 {
+		panic(fmt.Sprintf("log sequence must have positive bounds '%v' '%v'", start, end))
+	}
+	ratio := 1.0
+	if limit > 1 {
+		ratio = math.Pow(end/start, 1/float64(limit-1))
+	}
+	s := &Sequence{
+		initialValue: start,
+		value:        value,
+		limit:        limit,
+		Transform: func(v float64) float64 {
+			return v * ratio
+		},
+	}
+	s.set(start)
+	return s
+}
+
+// CategoricalSequence creates a sequence going through the given options in order
+// e.g. the indices of activation functions, or a set of layer sizes.
+func CategoricalSequence(value *float64, options ...float64) *Sequence {
+	if len(options) == 0 {
+		panic("categorical sequence needs at least one option")
+	}
+	s := &Sequence{
+		initialValue: options[0],
+		value:        value,
+		limit:        len(options),
+		Transform: func(v float64) float64 {
+			for i, o := range options {
+				if o == v {
+					return options[(i+1)%len(options)]
+				}
+			}
+			return options[0]
+		},
+	}
+	s.set(options[0])
+	return s
+}
+
 func NewSequence(value *float64, transform Transform, limit int) *Sequence {
 	return &Sequence{
 		initialValue: *value,
@@ -139,3 +139,61 @@ func TestPerturbationSequence(t *testing.T) {
 	}
 
 }
+
+func TestLogSequence(t *testing.T) {
+
+	var value float64
+
+	sequence := LogSequence(&value, 0.001, 1, 4)
+
+	values := sequence.Run()
+	assert.Equal(t, 4, len(values))
+	for i, v := range []float64{0.001, 0.01, 0.1, 1} {
+		assert.InDelta(t, v, values[i], v*1e-9)
+	}
+	// the sequence is reset after the run
+	assert.Equal(t, 0.001, value)
+
+}
+
+func TestCategoricalSequence(t *testing.T) {
+
+	var value float64
+
+	sequence := CategoricalSequence(&value, 3, 1, 2)
+
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, []float64{3, 1, 2}, sequence.Run())
+	}
+
+	ev := NewEvolution(sequence)
+	assert.Equal(t, 3, ev.Limit())
+	var values []float64
+	for ev.Next() {
+		values = append(values, value)
+	}
+	assert.Equal(t, []float64{3, 1, 2}, values)
+
+}
+
+func TestEvolution_Apply(t *testing.T) {
+
+	var a, b float64
+
+	ev := NewEvolution(
+		RangeSequence(&a, 0, 3, 3, 0),
+		CategoricalSequence(&b, 10, 20),
+	)
+
+	combinations := make([][]float64, 0)
+	for ev.Next() {
+		combinations = append(combinations, []float64{a, b})
+	}
+	assert.Equal(t, 6, len(combinations))
+
+	for i := len(combinations) - 1; i >= 0; i-- {
+		ev.Apply(i)
+		assert.Equal(t, combinations[i], []float64{a, b})
+	}
+
+}
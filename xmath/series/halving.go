@@ -0,0 +1,230 @@
+package series
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Halving is a successive halving schedule over the combinations of a search, for a budget e.g. training epochs.
+// All combinations are evaluated on a small budget, and only the best 1/eta of them continue to the next round,
+// with eta times the budget, until a single one is left.
+// After each Next, the combination should be evaluated on the Budget, and its score given with Report.
+// Lower scores are better e.g. the loss.
+type Halving struct {
+	search     Search
+	eta        int
+	budget     float64
+	rounds     int
+	round      int
+	candidates []int
+	scores     []float64
+	i          int
+	done       bool
+	best       int
+	bestScore  float64
+}
+
+// NewSuccessiveHalving creates a successive halving schedule over all the combinations of the search,
+// starting with the given budget.
+func NewSuccessiveHalving(search Search, budget float64, eta int) *Halving {
+	candidates := make([]int, search.Limit())
+	for i := range candidates {
+		candidates[i] = i
+	}
+	return newHalving(search, candidates, budget, eta, 0)
+}
+
+// newHalving creates a schedule over the given combinations, for up to the given rounds, or unlimited if 0.
+func newHalving(search Search, candidates []int, budget float64, eta, rounds int) *Halving {
+	if eta < 2 {
+		panic(fmt.Sprintf("halving rate must be at least '%v' vs '%v'", 2, eta))
+	}
+	return &Halving{
+		search:     search,
+		eta:        eta,
+		budget:     budget,
+		rounds:     rounds,
+		candidates: candidates,
+		scores:     unscored(len(candidates)),
+		best:       -1,
+		bestScore:  math.NaN(),
+	}
+}
+
+// unscored returns the scores of candidates that have not been evaluated yet.
+func unscored(n int) []float64 {
+	scores := make([]float64, n)
+	for i := range scores {
+		scores[i] = math.NaN()
+	}
+	return scores
+}
+
+// Next updates the parameters with the next combination to evaluate,
+// it returns false once the schedule is complete.
+func (h *Halving) Next() bool {
+	if h.done {
+		return false
+	}
+	if h.i >= len(h.candidates) && !h.promote() {
+		h.done = true
+		return false
+	}
+	h.search.Apply(h.candidates[h.i])
+	h.i++
+	return true
+}
+
+// Budget returns the budget to evaluate the current combination on.
+func (h *Halving) Budget() float64 {
+	return h.budget * math.Pow(float64(h.eta), float64(h.round))
+}
+
+// Round returns the index of the current round.
+func (h *Halving) Round() int {
+	return h.round
+}
+
+// Report records the score of the current combination.
+// Combinations without a score are ranked last.
+func (h *Halving) Report(score float64) {
+	if h.i == 0 {
+		panic("cannot report score before the first combination")
+	}
+	h.scores[h.i-1] = score
+}
+
+// Best returns the index of the best combination of the last completed round and its score,
+// or -1 if no round has been completed yet.
+// The parameters can be set to it with the Apply of the search.
+func (h *Halving) Best() (int, float64) {
+	return h.best, h.bestScore
+}
+
+// promote moves the best candidates of the round to the next one,
+// it returns false if there is no next round.
+func (h *Halving) promote() bool {
+	if len(h.candidates) == 0 {
+		return false
+	}
+	h.rank()
+	if len(h.candidates) == 1 || (h.rounds > 0 && h.round+1 >= h.rounds) {
+		return false
+	}
+	keep := len(h.candidates) / h.eta
+	if keep < 1 {
+		keep = 1
+	}
+	h.candidates = h.candidates[:keep]
+	h.scores = unscored(keep)
+	h.round++
+	h.i = 0
+	return true
+}
+
+// rank sorts the candidates of the round by their score, and keeps the best one.
+func (h *Halving) rank() {
+	order := make([]int, len(h.candidates))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := h.scores[order[i]], h.scores[order[j]]
+		if math.IsNaN(b) {
+			return !math.IsNaN(a)
+		}
+		return a < b
+	})
+	candidates := make([]int, len(order))
+	scores := make([]float64, len(order))
+	for i, o := range order {
+		candidates[i] = h.candidates[o]
+		scores[i] = h.scores[o]
+	}
+	h.candidates = candidates
+	h.scores = scores
+	h.best = candidates[0]
+	h.bestScore = scores[0]
+}
+
+// Hyperband runs successive halving schedules with different trade-offs between the number of combinations and their budget,
+// from many combinations on the minimum budget, to a few ones on the maximum budget,
+// so that it does not depend on a single choice of the starting budget.
+// The combinations of the search are split across the schedules in order.
+type Hyperband struct {
+	brackets []*Halving
+	b        int
+}
+
+// NewHyperband creates a hyperband schedule over the combinations of the search, with budgets from min up to max.
+func NewHyperband(search Search, min, max float64, eta int) *Hyperband {
+	if min <= 0 || max < min {
+		panic(fmt.Sprintf("hyperband budget must be within positive bounds '%v' '%v'", min, max))
+	}
+	if eta < 2 {
+		panic(fmt.Sprintf("halving rate must be at least '%v' vs '%v'", 2, eta))
+	}
+	// the tolerance avoids losing a bracket to rounding e.g. when max/min is an exact power of eta
+	sMax := int(math.Floor(math.Log(max/min)/math.Log(float64(eta)) + 1e-9))
+	brackets := make([]*Halving, 0, sMax+1)
+	var next int
+	for s := sMax; s >= 0 && next < search.Limit(); s-- {
+		n := int(math.Ceil(float64(sMax+1) / float64(s+1) * math.Pow(float64(eta), float64(s))))
+		if next+n > search.Limit() {
+			n = search.Limit() - next
+		}
+		candidates := make([]int, n)
+		for i := range candidates {
+			candidates[i] = next + i
+		}
+		next += n
+		budget := max * math.Pow(float64(eta), -float64(s))
+		brackets = append(brackets, newHalving(search, candidates, budget, eta, s+1))
+	}
+	return &Hyperband{
+		brackets: brackets,
+	}
+}
+
+// Next updates the parameters with the next combination to evaluate,
+// it returns false once all schedules are complete.
+func (h *Hyperband) Next() bool {
+	for h.b < len(h.brackets) {
+		if h.brackets[h.b].Next() {
+			return true
+		}
+		h.b++
+	}
+	return false
+}
+
+// Budget returns the budget to evaluate the current combination on.
+func (h *Hyperband) Budget() float64 {
+	return h.current().Budget()
+}
+
+// Report records the score of the current combination.
+func (h *Hyperband) Report(score float64) {
+	h.current().Report(score)
+}
+
+// Best returns the index of the best combination across the completed schedules and its score,
+// or -1 if no schedule has been completed yet.
+func (h *Hyperband) Best() (int, float64) {
+	best, score := -1, math.NaN()
+	for _, bracket := range h.brackets[:h.b] {
+		if i, s := bracket.Best(); i >= 0 && (best < 0 || s < score) {
+			best, score = i, s
+		}
+	}
+	return best, score
+}
+
+// current returns the schedule in progress.
+func (h *Hyperband) current() *Halving {
+	if h.b >= len(h.brackets) {
+		panic("hyperband schedule is complete")
+	}
+	return h.brackets[h.b]
+}
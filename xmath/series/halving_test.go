@@ -0,0 +1,107 @@
+package series
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuccessiveHalving(t *testing.T) {
+
+	type test struct {
+		n       int
+		eta     int
+		budgets map[float64]int
+	}
+
+	tests := map[string]test{
+		"eta-3": {
+			n:       9,
+			eta:     3,
+			budgets: map[float64]int{1: 9, 3: 3, 9: 1},
+		},
+		"eta-2": {
+			n:       10,
+			eta:     2,
+			budgets: map[float64]int{1: 10, 2: 5, 4: 2, 8: 1},
+		},
+		"single": {
+			n:       1,
+			eta:     2,
+			budgets: map[float64]int{1: 1},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var x float64
+			ev := NewEvolution(RangeSequence(&x, 0, float64(tt.n), tt.n, 0))
+			h := NewSuccessiveHalving(ev, 1, tt.eta)
+
+			best, _ := h.Best()
+			assert.Equal(t, -1, best)
+
+			budgets := make(map[float64]int)
+			for h.Next() {
+				budgets[h.Budget()]++
+				// the score improves with the budget, but the order of the combinations stays the same
+				h.Report(math.Abs(x-float64(tt.n/2)) / h.Budget())
+			}
+			assert.Equal(t, tt.budgets, budgets)
+			assert.False(t, h.Next())
+
+			best, score := h.Best()
+			assert.Equal(t, tt.n/2, best)
+			assert.Equal(t, 0.0, score)
+		})
+	}
+
+}
+
+func TestSuccessiveHalving_Unreported(t *testing.T) {
+
+	var x float64
+	ev := NewEvolution(RangeSequence(&x, 0, 4, 4, 0))
+	h := NewSuccessiveHalving(ev, 1, 2)
+
+	for h.Next() {
+		// only the last combination is ever scored
+		if x == 3 {
+			h.Report(1)
+		}
+	}
+
+	best, score := h.Best()
+	assert.Equal(t, 3, best)
+	assert.Equal(t, 1.0, score)
+
+}
+
+func TestHyperband(t *testing.T) {
+
+	var x float64
+	// 9 + 5 + 3 combinations are needed for the brackets from budget 1 up to 9
+	ev := NewEvolution(RangeSequence(&x, 0, 17, 17, 0))
+	h := NewHyperband(ev, 1, 9, 3)
+
+	seen := make(map[float64]struct{})
+	budgets := make(map[float64]int)
+	for h.Next() {
+		seen[x] = struct{}{}
+		budgets[h.Budget()]++
+		h.Report(math.Abs(x - 12))
+	}
+
+	assert.Equal(t, 17, len(seen))
+	// bracket of 9 on 1, 3, 9, bracket of 5 on 3, 9 and bracket of 3 on 9
+	assert.Equal(t, map[float64]int{1: 9, 3: 3 + 5, 9: 1 + 1 + 3}, budgets)
+
+	best, score := h.Best()
+	assert.Equal(t, 12, best)
+	assert.Equal(t, 0.0, score)
+
+	ev.Apply(best)
+	assert.Equal(t, 12.0, x)
+
+}
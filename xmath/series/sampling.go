@@ -0,0 +1,149 @@
+package series
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// Search iterates over combinations of parameter values, updating the parameters in place.
+// Both the exhaustive Evolution and the Sampling strategies are searches.
+type Search interface {
+	// Limit returns the number of combinations.
+	Limit() int
+	// Current returns the number of combinations iterated so far.
+	Current() int
+	// Next updates the parameters with the next combination,
+	// it returns false if there is nothing more to iterate.
+	Next() bool
+	// Apply updates the parameters with the combination at the given index.
+	Apply(index int)
+}
+
+// Distribution maps a quantile within [0,1) to a parameter value.
+type Distribution func(u float64) float64
+
+// Uniform distributes the values evenly between min and max.
+func Uniform(min, max float64) Distribution {
+	return func(u float64) float64 {
+		return min + u*(max-min)
+	}
+}
+
+// LogUniform distributes the values evenly on a logarithmic scale between min and max,
+// e.g. so that there are as many learning rates between 0.001 and 0.01, as between 0.01 and 0.1.
+func LogUniform(min, max float64) Distribution {
+	if min <= 0 || max <= 0 {
+		panic(fmt.Sprintf("log uniform distribution must have positive bounds '%v' '%v'", min, max))
+	}
+	return func(u float64) float64 {
+		return min * math.Pow(max/min, u)
+	}
+}
+
+// Categorical picks one of the options with equal probability.
+func Categorical(options ...float64) Distribution {
+	if len(options) == 0 {
+		panic("categorical distribution needs at least one option")
+	}
+	return func(u float64) float64 {
+		i := int(u * float64(len(options)))
+		if i >= len(options) {
+			i = len(options) - 1
+		}
+		return options[i]
+	}
+}
+
+// Param is a parameter sampled out of a distribution.
+type Param struct {
+	value        *float64
+	distribution Distribution
+}
+
+// NewParam creates a new parameter that updates the given value out of the distribution.
+func NewParam(value *float64, distribution Distribution) *Param {
+	return &Param{
+		value:        value,
+		distribution: distribution,
+	}
+}
+
+// Param returns a parameter that picks one of the sequence values with equal probability.
+// The sequence is reset, as the values are sampled from its start.
+func (p *Sequence) Param() *Param {
+	p.Reset()
+	return NewParam(p.value, Categorical(p.Run()...))
+}
+
+// Sampling is a search over a fixed number of combinations sampled out of the parameter distributions.
+// Unlike the Evolution, its size does not grow with the number of parameters.
+type Sampling struct {
+	i      int
+	points [][]float64
+	params []*Param
+}
+
+// NewRandomSampling creates a search of n combinations sampled independently for each parameter.
+func NewRandomSampling(n int, seed int64, params ...*Param) *Sampling {
+	rng := rand.New(rand.NewSource(seed))
+	points := make([][]float64, n)
+	for i := range points {
+		points[i] = make([]float64, len(params))
+		for j := range params {
+			points[i][j] = rng.Float64()
+		}
+	}
+	return &Sampling{
+		points: points,
+		params: params,
+	}
+}
+
+// NewLatinHypercube creates a search of n combinations where, for each parameter,
+// every one of n equally probable intervals of its distribution is sampled exactly once.
+// It covers the parameter space more evenly than random sampling for the same number of combinations.
+func NewLatinHypercube(n int, seed int64, params ...*Param) *Sampling {
+	rng := rand.New(rand.NewSource(seed))
+	points := make([][]float64, n)
+	for i := range points {
+		points[i] = make([]float64, len(params))
+	}
+	for j := range params {
+		for i, stratum := range rng.Perm(n) {
+			points[i][j] = (float64(stratum) + rng.Float64()) / float64(n)
+		}
+	}
+	return &Sampling{
+		points: points,
+		params: params,
+	}
+}
+
+// Limit returns the number of combinations.
+func (s *Sampling) Limit() int {
+	return len(s.points)
+}
+
+// Current returns the number of combinations iterated so far.
+func (s *Sampling) Current() int {
+	return s.i
+}
+
+// Next updates the parameters with the next combination,
+// it returns false if there is nothing more to iterate.
+func (s *Sampling) Next() bool {
+	if s.i >= len(s.points) {
+		return false
+	}
+	s.Apply(s.i)
+	s.i++
+	return true
+}
+
+// Apply updates the parameters with the combination at the given index.
+func (s *Sampling) Apply(index int) {
+	for j, p := range s.params {
+		*p.value = p.distribution(s.points[index][j])
+	}
+}
@@ -0,0 +1,130 @@
+package series
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistribution(t *testing.T) {
+
+	type test struct {
+		distribution Distribution
+		quantiles    []float64
+		values       []float64
+	}
+
+	tests := map[string]test{
+		"uniform": {
+			distribution: Uniform(-1, 1),
+			quantiles:    []float64{0, 0.25, 0.5, 0.75},
+			values:       []float64{-1, -0.5, 0, 0.5},
+		},
+		"log-uniform": {
+			distribution: LogUniform(0.001, 1),
+			quantiles:    []float64{0, 1.0 / 3, 2.0 / 3, 1},
+			values:       []float64{0.001, 0.01, 0.1, 1},
+		},
+		"categorical": {
+			distribution: Categorical(5, 7, 9),
+			quantiles:    []float64{0, 0.3, 0.34, 0.66, 0.67, 0.99, 1},
+			values:       []float64{5, 5, 7, 7, 9, 9, 9},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			for i, u := range tt.quantiles {
+				assert.InDelta(t, tt.values[i], tt.distribution(u), 1e-9)
+			}
+		})
+	}
+
+}
+
+func TestSampling(t *testing.T) {
+
+	type test struct {
+		sampling func(n int, seed int64, params ...*Param) *Sampling
+	}
+
+	tests := map[string]test{
+		"random": {
+			sampling: NewRandomSampling,
+		},
+		"latin-hypercube": {
+			sampling: NewLatinHypercube,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+
+			var rate, size float64
+			run := func(seed int64) [][]float64 {
+				s := tt.sampling(20, seed,
+					NewParam(&rate, LogUniform(0.0001, 0.1)),
+					NewParam(&size, Categorical(8, 16, 32)),
+				)
+				assert.Equal(t, 20, s.Limit())
+				points := make([][]float64, 0)
+				for s.Next() {
+					assert.True(t, rate >= 0.0001 && rate < 0.1)
+					assert.Contains(t, []float64{8, 16, 32}, size)
+					points = append(points, []float64{rate, size})
+				}
+				assert.Equal(t, 20, s.Current())
+				// revisit a previous combination
+				s.Apply(3)
+				assert.Equal(t, points[3], []float64{rate, size})
+				return points
+			}
+
+			// the same seed gives the same combinations
+			assert.Equal(t, run(1), run(1))
+			assert.NotEqual(t, run(1), run(2))
+		})
+	}
+
+}
+
+func TestNewLatinHypercube(t *testing.T) {
+
+	n := 10
+	var x, y float64
+	s := NewLatinHypercube(n, 1,
+		NewParam(&x, Uniform(0, 1)),
+		NewParam(&y, LogUniform(1, math.Pow(2, float64(n)))),
+	)
+
+	xs := make(map[int]int)
+	ys := make(map[int]int)
+	for s.Next() {
+		xs[int(x*float64(n))]++
+		ys[int(math.Log2(y))]++
+	}
+
+	// every interval of each parameter is sampled exactly once
+	for i := 0; i < n; i++ {
+		assert.Equal(t, 1, xs[i])
+		assert.Equal(t, 1, ys[i])
+	}
+
+}
+
+func TestSequence_Param(t *testing.T) {
+
+	var value float64
+	sequence := RangeSequence(&value, 0, 1, 4, 2)
+	// the value is left by a previous search e.g. the last sampled one
+	value = 0.5
+
+	s := NewRandomSampling(50, 1, sequence.Param())
+	values := make(map[float64]struct{})
+	for s.Next() {
+		values[value] = struct{}{}
+	}
+	assert.Equal(t, map[float64]struct{}{0: {}, 0.25: {}, 0.5: {}, 0.75: {}}, values)
+
+}
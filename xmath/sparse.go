@@ -0,0 +1,302 @@
+package xmath
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// SparseVector is a vector that keeps only its non-zero elements, as pairs of indices and values.
+// The indices are kept in increasing order.
+// It is meant for large mostly-zero vectors e.g. one-hot encoded or bag-of-words features.
+type SparseVector struct {
+	size    int
+	indices []int
+	values  []float64
+}
+
+// SparseVec creates a new sparse vector of the given size with the values at the given indices.
+// The indices must be in increasing order and within the size of the vector.
+func SparseVec(size int, indices []int, values []float64) SparseVector {
+	if len(indices) != len(values) {
+		panic(fmt.Sprintf("sparse vector must have the same number of indices and values '%v' vs '%v'", len(indices), len(values)))
+	}
+	for k, i := range indices {
+		if i < 0 || i >= size || (k > 0 && i <= indices[k-1]) {
+			panic(fmt.Sprintf("sparse vector indices must be increasing and within size '%v' : %v", size, indices))
+		}
+	}
+	return SparseVector{
+		size:    size,
+		indices: indices,
+		values:  values,
+	}
+}
+
+// SparseOf creates a sparse vector out of the non-zero elements of the given vector.
+func SparseOf(v Vector) SparseVector {
+	s := SparseVector{
+		size:    len(v),
+		indices: make([]int, 0),
+		values:  make([]float64, 0),
+	}
+	for i, x := range v {
+		if x != 0 {
+			s.indices = append(s.indices, i)
+			s.values = append(s.values, x)
+		}
+	}
+	return s
+}
+
+// OneHot creates a sparse vector of the given size, with a single 1 at the given index.
+func OneHot(size, index int) SparseVector {
+	return SparseVec(size, []int{index}, []float64{1})
+}
+
+// Size returns the size of the vector, including the zero elements.
+func (s SparseVector) Size() int {
+	return s.size
+}
+
+// NNZ returns the number of non-zero elements.
+func (s SparseVector) NNZ() int {
+	return len(s.indices)
+}
+
+// Indices returns the indices of the non-zero elements.
+func (s SparseVector) Indices() []int {
+	return s.indices
+}
+
+// Values returns the non-zero elements, in the order of their indices.
+func (s SparseVector) Values() []float64 {
+	return s.values
+}
+
+// Dense converts the sparse vector to a Vector.
+func (s SparseVector) Dense() Vector {
+	v := Vec(s.size)
+	for k, i := range s.indices {
+		v[i] = s.values[k]
+	}
+	return v
+}
+
+// Dot returns the dot product of the sparse vector with the given vector.
+func (s SparseVector) Dot(v Vector) float64 {
+	MustHaveSize(v, s.size)
+	var p float64
+	for k, i := range s.indices {
+		p += s.values[k] * v[i]
+	}
+	return p
+}
+
+// Mult multiplies the vector with a constant number.
+func (s SparseVector) Mult(f float64) SparseVector {
+	values := make([]float64, len(s.values))
+	for k, x := range s.values {
+		values[k] = f * x
+	}
+	return SparseVector{
+		size:    s.size,
+		indices: s.indices,
+		values:  values,
+	}
+}
+
+// String prints the sparse vector in an easily readable form.
+func (s SparseVector) String() string {
+	builder := strings.Builder{}
+	builder.WriteString(fmt.Sprintf("(%d)", s.size))
+	builder.WriteString("{ ")
+	for k, i := range s.indices {
+		if k > 0 {
+			builder.WriteString(" , ")
+		}
+		builder.WriteString(fmt.Sprintf("%d:%v", i, s.values[k]))
+	}
+	builder.WriteString(" }")
+	return builder.String()
+}
+
+// DotSparse returns the dot product of the vector with the given sparse vector.
+func (v Vector) DotSparse(s SparseVector) float64 {
+	return s.Dot(v)
+}
+
+// ProdSparse returns the product of the matrix with the given sparse vector,
+// touching only the columns of its non-zero elements.
+func (m Matrix) ProdSparse(s SparseVector) Vector {
+	w := Vec(len(m))
+	for i := range m {
+		w[i] = s.Dot(m[i])
+	}
+	return w
+}
+
+// ProdSparse returns the product of the matrix with the given sparse vector,
+// touching only the columns of its non-zero elements.
+func (d Dense) ProdSparse(s SparseVector) Vector {
+	rows, cols := d.Dims()
+	if s.size != cols {
+		panic(fmt.Sprintf("sparse vector must have size '%v' vs '%v'", s.size, cols))
+	}
+	w := Vec(rows)
+	for i := range w {
+		var p float64
+		for k, j := range s.indices {
+			p += d.At(i, j) * s.values[k]
+		}
+		w[i] = p
+	}
+	return w
+}
+
+// AddOuterSparseInPlace adds the outer product of v and the sparse vector w scaled by s to the matrix,
+// mutating only the columns of the non-zero elements of w.
+func (d Dense) AddOuterSparseInPlace(s float64, v Vector, w SparseVector) Dense {
+	rows, cols := d.Dims()
+	MustHaveSize(v, rows)
+	if w.size != cols {
+		panic(fmt.Sprintf("sparse vector must have size '%v' vs '%v'", w.size, cols))
+	}
+	for i := range v {
+		sv := s * v[i]
+		for k, j := range w.indices {
+			d.data[d.index(i, j)] += sv * w.values[k]
+		}
+	}
+	return d
+}
+
+// ExpectSparse returns a ShapeError if the sparse vector does not have the given size,
+// or a NaNError for its first element that is not a valid number.
+func ExpectSparse(location string, s SparseVector, n int) error {
+	if s.size != n {
+		return &ShapeError{
+			Location: location,
+			Expected: []int{n},
+			Actual:   []int{s.size},
+		}
+	}
+	for k, x := range s.values {
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			return &NaNError{
+				Location: location,
+				Index:    []int{s.indices[k]},
+				Value:    x,
+			}
+		}
+	}
+	return nil
+}
+
+// CSR is a sparse matrix in compressed sparse row format.
+// The non-zero elements of row i are at the positions [indptr[i],indptr[i+1]) of the indices and values.
+type CSR struct {
+	rows, cols int
+	indptr     []int
+	indices    []int
+	values     []float64
+}
+
+// CSROf creates a CSR matrix out of the non-zero elements of the given matrix.
+func CSROf(m Matrix) CSR {
+	var cols int
+	if len(m) > 0 {
+		cols = len(m[0])
+	}
+	rows := make([]SparseVector, len(m))
+	for i := range m {
+		MustHaveSize(m[i], cols)
+		rows[i] = SparseOf(m[i])
+	}
+	return CSRFrom(cols, rows...)
+}
+
+// CSRFrom creates a CSR matrix with the given sparse vectors as rows.
+func CSRFrom(cols int, rows ...SparseVector) CSR {
+	c := CSR{
+		rows:    len(rows),
+		cols:    cols,
+		indptr:  make([]int, len(rows)+1),
+		indices: make([]int, 0),
+		values:  make([]float64, 0),
+	}
+	for i, row := range rows {
+		if row.size != cols {
+			panic(fmt.Sprintf("sparse vector must have size '%v' vs '%v'", row.size, cols))
+		}
+		c.indices = append(c.indices, row.indices...)
+		c.values = append(c.values, row.values...)
+		c.indptr[i+1] = len(c.indices)
+	}
+	return c
+}
+
+// Dims returns the number of rows and columns of the matrix.
+func (c CSR) Dims() (rows, cols int) {
+	return c.rows, c.cols
+}
+
+// NNZ returns the number of non-zero elements.
+func (c CSR) NNZ() int {
+	return len(c.values)
+}
+
+// Row returns the row at the given index as a sparse vector, sharing the underlying data.
+func (c CSR) Row(i int) SparseVector {
+	from, to := c.indptr[i], c.indptr[i+1]
+	return SparseVector{
+		size:    c.cols,
+		indices: c.indices[from:to],
+		values:  c.values[from:to],
+	}
+}
+
+// At returns the element at row i and column j.
+func (c CSR) At(i, j int) float64 {
+	row := c.Row(i)
+	for k, idx := range row.indices {
+		if idx == j {
+			return row.values[k]
+		}
+		if idx > j {
+			break
+		}
+	}
+	return 0
+}
+
+// Prod returns the product of the matrix with the given vector.
+func (c CSR) Prod(v Vector) Vector {
+	MustHaveSize(v, c.cols)
+	w := Vec(c.rows)
+	for i := range w {
+		w[i] = c.Row(i).Dot(v)
+	}
+	return w
+}
+
+// TProd returns the product of the transpose of the matrix with the given vector.
+func (c CSR) TProd(v Vector) Vector {
+	MustHaveSize(v, c.rows)
+	w := Vec(c.cols)
+	for i := 0; i < c.rows; i++ {
+		for k := c.indptr[i]; k < c.indptr[i+1]; k++ {
+			w[c.indices[k]] += c.values[k] * v[i]
+		}
+	}
+	return w
+}
+
+// Matrix converts the CSR matrix to the Matrix form.
+func (c CSR) Matrix() Matrix {
+	m := Mat(c.rows)
+	for i := range m {
+		m[i] = c.Row(i).Dense()
+	}
+	return m
+}
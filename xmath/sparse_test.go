@@ -0,0 +1,164 @@
+package xmath
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSparseVector(t *testing.T) {
+
+	v := Vec(6).With(0, 1.5, 0, 0, -2, 0)
+	s := SparseOf(v)
+
+	assert.Equal(t, 6, s.Size())
+	assert.Equal(t, 2, s.NNZ())
+	assert.Equal(t, []int{1, 4}, s.Indices())
+	assert.Equal(t, []float64{1.5, -2}, s.Values())
+	assert.Equal(t, v, s.Dense())
+	assert.Equal(t, v.Mult(2), s.Mult(2).Dense())
+
+	w := Vec(6).With(1, 2, 3, 4, 5, 6)
+	assert.Equal(t, v.Dot(w), s.Dot(w))
+	assert.Equal(t, v.Dot(w), w.DotSparse(s))
+
+	assert.Equal(t, Vec(4).With(0, 0, 1, 0), OneHot(4, 2).Dense())
+	assert.Equal(t, "(6){ 1:1.5 , 4:-2 }", s.String())
+
+}
+
+func TestSparseVec_Invalid(t *testing.T) {
+
+	type test struct {
+		indices []int
+		values  []float64
+	}
+
+	tests := map[string]test{
+		"size-mismatch":  {indices: []int{0, 1}, values: []float64{1}},
+		"unsorted":       {indices: []int{2, 1}, values: []float64{1, 2}},
+		"duplicate":      {indices: []int{1, 1}, values: []float64{1, 2}},
+		"out-of-range":   {indices: []int{4}, values: []float64{1}},
+		"negative-index": {indices: []int{-1}, values: []float64{1}},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Panics(t, func() {
+				SparseVec(4, tt.indices, tt.values)
+			})
+		})
+	}
+
+}
+
+func TestSparse_Prod(t *testing.T) {
+
+	m := randMatrix(5, 8, 1)
+	v := Vec(8).With(0, 0, 3, 0, 0, 0, -1, 0)
+	s := SparseOf(v)
+
+	assert.InDeltaSlice(t, m.Prod(v), m.ProdSparse(s), 1e-12)
+
+	d := DenseOf(m)
+	assert.InDeltaSlice(t, d.Prod(v), d.ProdSparse(s), 1e-12)
+
+	// the transposed view goes through the strides
+	w := Vec(5).With(0, 2, 0, 0, 1)
+	assert.InDeltaSlice(t, d.T().Prod(w), d.T().ProdSparse(SparseOf(w)), 1e-12)
+
+	assert.Panics(t, func() {
+		d.ProdSparse(OneHot(5, 0))
+	})
+
+}
+
+func TestDense_AddOuterSparseInPlace(t *testing.T) {
+
+	type test struct {
+		dense func() Dense
+	}
+
+	tests := map[string]test{
+		"dense": {dense: func() Dense {
+			return DenseOf(Mat(3).Of(4))
+		}},
+		"transposed": {dense: func() Dense {
+			return DenseOf(Mat(4).Of(3)).T()
+		}},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			v := Vec(3).With(1, 2, 3)
+			w := Vec(4).With(0, 0.5, 0, 2)
+
+			expected := tt.dense().AddOuterInPlace(0.1, v, w)
+			d := tt.dense().AddOuterSparseInPlace(0.1, v, SparseOf(w))
+
+			assert.Equal(t, expected.Matrix(), d.Matrix())
+			for i := 0; i < 3; i++ {
+				assert.Equal(t, 0.0, d.At(i, 0))
+				assert.Equal(t, 0.0, d.At(i, 2))
+			}
+		})
+	}
+
+}
+
+func TestExpectSparse(t *testing.T) {
+
+	s := SparseVec(5, []int{1, 3}, []float64{1, math.NaN()})
+
+	assert.NoError(t, ExpectSparse("input", OneHot(5, 3), 5))
+
+	var shapeErr *ShapeError
+	err := ExpectSparse("input", s, 4)
+	assert.True(t, errors.As(err, &shapeErr))
+	assert.Equal(t, []int{4}, shapeErr.Expected)
+	assert.Equal(t, []int{5}, shapeErr.Actual)
+
+	var nanErr *NaNError
+	err = ExpectSparse("input", s, 5)
+	assert.True(t, errors.As(err, &nanErr))
+	assert.Equal(t, []int{3}, nanErr.Index)
+
+}
+
+func TestCSR(t *testing.T) {
+
+	m := Mat(3).With(
+		Vec(4).With(1, 0, 0, 2),
+		Vec(4).With(0, 0, 0, 0),
+		Vec(4).With(0, 3, 4, 0),
+	)
+	c := CSROf(m)
+
+	rows, cols := c.Dims()
+	assert.Equal(t, 3, rows)
+	assert.Equal(t, 4, cols)
+	assert.Equal(t, 4, c.NNZ())
+	assert.Equal(t, m, c.Matrix())
+
+	for i := range m {
+		assert.Equal(t, m[i], c.Row(i).Dense())
+		for j := range m[i] {
+			assert.Equal(t, m[i][j], c.At(i, j))
+		}
+	}
+
+	v := Vec(4).With(1, 2, 3, 4)
+	assert.Equal(t, m.Prod(v), c.Prod(v))
+	w := Vec(3).With(1, 2, 3)
+	assert.Equal(t, m.T().Prod(w), c.TProd(w))
+
+	// the same matrix out of its sparse rows
+	assert.Equal(t, c, CSRFrom(4, c.Row(0), c.Row(1), c.Row(2)))
+
+	assert.Panics(t, func() {
+		CSRFrom(3, OneHot(4, 0))
+	})
+
+}
@@ -1 +1,482 @@
 package xmath
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Tensor is an n-dimensional array of arbitrary rank.
+// The elements are kept in a flat slice, and the shape and strides define how indices map to positions in it.
+// Reshape, Slice, Index, Transpose and Broadcast return views on the same data where possible,
+// while all other operations return new contiguous tensors and leave their receiver untouched.
+type Tensor struct {
+	data    []float64
+	shape   []int
+	strides []int
+	offset  int
+}
+
+// NewTensor creates a new zero tensor of the given shape.
+func NewTensor(shape ...int) Tensor {
+	return TensorOf(make([]float64, size(shape)), shape...)
+}
+
+// TensorOf creates a tensor of the given shape on top of the given data, in row-major order.
+// The tensor shares the data, so mutations will be reflected on both.
+func TensorOf(data []float64, shape ...int) Tensor {
+	if len(data) != size(shape) {
+		panic(fmt.Sprintf("data of size '%d' cannot fit shape %v", len(data), shape))
+	}
+	return Tensor{
+		data:    data,
+		shape:   append([]int{}, shape...),
+		strides: rowMajor(shape),
+	}
+}
+
+// size returns the number of elements for the given shape.
+func size(shape []int) int {
+	n := 1
+	for _, d := range shape {
+		if d < 0 {
+			panic(fmt.Sprintf("invalid shape %v", shape))
+		}
+		n *= d
+	}
+	return n
+}
+
+// rowMajor returns the strides of a contiguous row-major layout for the given shape.
+func rowMajor(shape []int) []int {
+	strides := make([]int, len(shape))
+	s := 1
+	for i := len(shape) - 1; i >= 0; i-- {
+		strides[i] = s
+		s *= shape[i]
+	}
+	return strides
+}
+
+// Shape returns the size of the tensor along each axis.
+func (t Tensor) Shape() []int {
+	return append([]int{}, t.shape...)
+}
+
+// Rank returns the number of axes of the tensor.
+func (t Tensor) Rank() int {
+	return len(t.shape)
+}
+
+// Size returns the total number of elements of the tensor.
+func (t Tensor) Size() int {
+	return size(t.shape)
+}
+
+// axis resolves the given axis, allowing negative values to count from the last one.
+func (t Tensor) axis(a int) int {
+	if a < 0 {
+		a += len(t.shape)
+	}
+	if a < 0 || a >= len(t.shape) {
+		panic(fmt.Sprintf("axis %d out of range for rank %d", a, len(t.shape)))
+	}
+	return a
+}
+
+// position returns the position in the data of the element at the given index.
+func (t Tensor) position(idx []int) int {
+	if len(idx) != len(t.shape) {
+		panic(fmt.Sprintf("index %v does not match rank %d", idx, len(t.shape)))
+	}
+	pos := t.offset
+	for a, i := range idx {
+		if i < 0 || i >= t.shape[a] {
+			panic(fmt.Sprintf("index %v out of range for shape %v", idx, t.shape))
+		}
+		pos += i * t.strides[a]
+	}
+	return pos
+}
+
+// At returns the element at the given index.
+func (t Tensor) At(idx ...int) float64 {
+	return t.data[t.position(idx)]
+}
+
+// Set sets the element at the given index.
+func (t Tensor) Set(v float64, idx ...int) {
+	t.data[t.position(idx)] = v
+}
+
+// iterate calls fn for each element in row-major order, with its sequence number and its position in the data.
+func (t Tensor) iterate(fn func(i, pos int)) {
+	n := t.Size()
+	if n == 0 {
+		return
+	}
+	idx := make([]int, len(t.shape))
+	pos := t.offset
+	for i := 0; i < n; i++ {
+		fn(i, pos)
+		for a := len(idx) - 1; a >= 0; a-- {
+			idx[a]++
+			pos += t.strides[a]
+			if idx[a] < t.shape[a] {
+				break
+			}
+			pos -= t.strides[a] * t.shape[a]
+			idx[a] = 0
+		}
+	}
+}
+
+// contiguous checks if the elements of the tensor are laid out in row-major order without gaps.
+func (t Tensor) contiguous() bool {
+	s := 1
+	for i := len(t.shape) - 1; i >= 0; i-- {
+		if t.shape[i] != 1 && t.strides[i] != s {
+			return false
+		}
+		s *= t.shape[i]
+	}
+	return true
+}
+
+// Data returns the elements of the tensor in row-major order.
+// For contiguous tensors this shares the underlying data.
+func (t Tensor) Data() []float64 {
+	if t.contiguous() {
+		return t.data[t.offset : t.offset+t.Size()]
+	}
+	return t.Copy().data
+}
+
+// Copy copies the tensor into a new one with the same values and a contiguous row-major layout.
+func (t Tensor) Copy() Tensor {
+	c := NewTensor(t.shape...)
+	t.iterate(func(i, pos int) {
+		c.data[i] = t.data[pos]
+	})
+	return c
+}
+
+// Reshape returns a tensor with the same elements in the given shape.
+// One of the dimensions can be -1, in which case it is inferred from the size of the tensor.
+// The result is a view if the tensor is contiguous, otherwise a copy.
+func (t Tensor) Reshape(shape ...int) Tensor {
+	shape = append([]int{}, shape...)
+	inferred := -1
+	n := 1
+	for i, d := range shape {
+		if d == -1 {
+			if inferred >= 0 {
+				panic(fmt.Sprintf("cannot infer more than one dimension for shape %v", shape))
+			}
+			inferred = i
+			continue
+		}
+		n *= d
+	}
+	if inferred >= 0 && n > 0 {
+		shape[inferred] = t.Size() / n
+	}
+	if size(shape) != t.Size() {
+		panic(fmt.Sprintf("cannot reshape %v into %v", t.shape, shape))
+	}
+	if !t.contiguous() {
+		t = t.Copy()
+	}
+	return TensorOf(t.data[t.offset:t.offset+t.Size()], shape...)
+}
+
+// Slice returns a view on the elements [from,to) along the given axis.
+func (t Tensor) Slice(axis, from, to int) Tensor {
+	a := t.axis(axis)
+	if from < 0 || to > t.shape[a] || from > to {
+		panic(fmt.Sprintf("slice [%d,%d) out of range for axis %d of shape %v", from, to, a, t.shape))
+	}
+	s := t.view()
+	s.shape[a] = to - from
+	s.offset += from * t.strides[a]
+	return s
+}
+
+// Index returns a view on the elements at index i along the given axis, reducing the rank by one.
+func (t Tensor) Index(axis, i int) Tensor {
+	a := t.axis(axis)
+	if i < 0 || i >= t.shape[a] {
+		panic(fmt.Sprintf("index %d out of range for axis %d of shape %v", i, a, t.shape))
+	}
+	s := t.view()
+	s.offset += i * t.strides[a]
+	s.shape = append(s.shape[:a], s.shape[a+1:]...)
+	s.strides = append(s.strides[:a], s.strides[a+1:]...)
+	return s
+}
+
+// Transpose returns a view with the axes permuted in the given order.
+// Without arguments the order of the axes is reversed.
+func (t Tensor) Transpose(axes ...int) Tensor {
+	if len(axes) == 0 {
+		axes = make([]int, len(t.shape))
+		for i := range axes {
+			axes[i] = len(t.shape) - 1 - i
+		}
+	}
+	if len(axes) != len(t.shape) {
+		panic(fmt.Sprintf("axes %v do not match rank %d", axes, len(t.shape)))
+	}
+	s := t.view()
+	seen := make([]bool, len(t.shape))
+	for i, axis := range axes {
+		a := t.axis(axis)
+		if seen[a] {
+			panic(fmt.Sprintf("repeated axis in %v", axes))
+		}
+		seen[a] = true
+		s.shape[i] = t.shape[a]
+		s.strides[i] = t.strides[a]
+	}
+	return s
+}
+
+// T returns the transpose of the tensor e.g. a view with the order of the axes reversed.
+func (t Tensor) T() Tensor {
+	return t.Transpose()
+}
+
+// Broadcast returns a view of the tensor expanded to the given shape, following the NumPy rules.
+// The shapes are aligned on the last axis and dimensions of size 1 are repeated along the target dimension,
+// without copying any data.
+func (t Tensor) Broadcast(shape ...int) Tensor {
+	if len(shape) < len(t.shape) {
+		panic(fmt.Sprintf("cannot broadcast %v to %v", t.shape, shape))
+	}
+	b := Tensor{
+		data:    t.data,
+		shape:   append([]int{}, shape...),
+		strides: make([]int, len(shape)),
+		offset:  t.offset,
+	}
+	shift := len(shape) - len(t.shape)
+	for i, d := range t.shape {
+		switch d {
+		case shape[shift+i]:
+			b.strides[shift+i] = t.strides[i]
+		case 1:
+			// stride 0 repeats the same element
+		default:
+			panic(fmt.Sprintf("cannot broadcast %v to %v", t.shape, shape))
+		}
+	}
+	return b
+}
+
+// BroadcastShape returns the shape that the given shapes broadcast to together.
+func BroadcastShape(a, b []int) []int {
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+	shape := append([]int{}, a...)
+	shift := len(a) - len(b)
+	for i, d := range b {
+		switch {
+		case d == shape[shift+i]:
+		case d == 1:
+		case shape[shift+i] == 1:
+			shape[shift+i] = d
+		default:
+			panic(fmt.Sprintf("shapes %v and %v cannot be broadcast together", a, b))
+		}
+	}
+	return shape
+}
+
+// view returns a shallow copy of the tensor, with its own shape and strides.
+func (t Tensor) view() Tensor {
+	return Tensor{
+		data:    t.data,
+		shape:   append([]int{}, t.shape...),
+		strides: append([]int{}, t.strides...),
+		offset:  t.offset,
+	}
+}
+
+// Op applies to each of the elements a specific function.
+func (t Tensor) Op(transform Op) Tensor {
+	c := t.Copy()
+	for i := range c.data {
+		c.data[i] = transform(c.data[i])
+	}
+	return c
+}
+
+// Dop applies to each pair of corresponding elements a specific function,
+// broadcasting the tensors to a common shape.
+func (t Tensor) Dop(transform Dop, u Tensor) Tensor {
+	shape := BroadcastShape(t.shape, u.shape)
+	c := t.Broadcast(shape...).Copy()
+	u.Broadcast(shape...).iterate(func(i, pos int) {
+		c.data[i] = transform(c.data[i], u.data[pos])
+	})
+	return c
+}
+
+// Add returns the element-wise addition of the two tensors.
+func (t Tensor) Add(u Tensor) Tensor {
+	return t.Dop(func(x, y float64) float64 {
+		return x + y
+	}, u)
+}
+
+// Diff returns the element-wise difference of the two tensors.
+func (t Tensor) Diff(u Tensor) Tensor {
+	return t.Dop(Diff, u)
+}
+
+// X returns the element-wise product of the two tensors.
+func (t Tensor) X(u Tensor) Tensor {
+	return t.Dop(Mult, u)
+}
+
+// Mult multiplies each element of the tensor with the given factor.
+func (t Tensor) Mult(s float64) Tensor {
+	return t.Op(Scale(s))
+}
+
+// Reduce combines the elements along the given axis with the reduce function, starting from the init value.
+// The result has the rank of the tensor reduced by one.
+func (t Tensor) Reduce(axis int, init float64, reduce Dop) Tensor {
+	a := t.axis(axis)
+	// move the axis to the end, so that the elements to combine are consecutive
+	axes := make([]int, 0, len(t.shape))
+	for i := range t.shape {
+		if i != a {
+			axes = append(axes, i)
+		}
+	}
+	c := t.Transpose(append(axes, a)...).Copy()
+	r := NewTensor(c.shape[:len(c.shape)-1]...)
+	n := t.shape[a]
+	for i := range r.data {
+		acc := init
+		for _, x := range c.data[i*n : (i+1)*n] {
+			acc = reduce(acc, x)
+		}
+		r.data[i] = acc
+	}
+	return r
+}
+
+// Sum returns the sum of the elements along the given axis.
+func (t Tensor) Sum(axis int) Tensor {
+	return t.Reduce(axis, 0, func(x, y float64) float64 {
+		return x + y
+	})
+}
+
+// Mean returns the mean of the elements along the given axis.
+func (t Tensor) Mean(axis int) Tensor {
+	return t.Sum(axis).Mult(1 / float64(t.shape[t.axis(axis)]))
+}
+
+// Max returns the maximum of the elements along the given axis.
+func (t Tensor) Max(axis int) Tensor {
+	return t.Reduce(axis, math.Inf(-1), math.Max)
+}
+
+// Min returns the minimum of the elements along the given axis.
+func (t Tensor) Min(axis int) Tensor {
+	return t.Reduce(axis, math.Inf(1), math.Min)
+}
+
+// Vector converts a tensor of rank 1 to a Vector.
+func (t Tensor) Vector() Vector {
+	t.mustHaveRank(1)
+	return t.Copy().data
+}
+
+// Matrix converts a tensor of rank 2 to a Matrix.
+func (t Tensor) Matrix() Matrix {
+	t.mustHaveRank(2)
+	m := Mat(t.shape[0])
+	for i := range m {
+		m[i] = t.Index(0, i).Vector()
+	}
+	return m
+}
+
+// Cube converts a tensor of rank 3 to a Cube.
+func (t Tensor) Cube() Cube {
+	t.mustHaveRank(3)
+	c := Cb(t.shape[0])
+	for i := range c {
+		c[i] = t.Index(0, i).Matrix()
+	}
+	return c
+}
+
+func (t Tensor) mustHaveRank(rank int) {
+	if len(t.shape) != rank {
+		panic(fmt.Sprintf("tensor must have rank '%d' vs '%v'", rank, t.shape))
+	}
+}
+
+// Tensor returns a tensor of rank 1 sharing the data of the vector.
+func (v Vector) Tensor() Tensor {
+	return TensorOf(v, len(v))
+}
+
+// Tensor returns a tensor of rank 2 with the values of the matrix.
+func (m Matrix) Tensor() Tensor {
+	var cols int
+	if len(m) > 0 {
+		cols = len(m[0])
+	}
+	t := NewTensor(len(m), cols)
+	for i := range m {
+		MustHaveSize(m[i], cols)
+		copy(t.data[i*cols:], m[i])
+	}
+	return t
+}
+
+// Tensor returns a tensor of rank 3 with the values of the cube.
+func (c Cube) Tensor() Tensor {
+	var rows, cols int
+	if len(c) > 0 && len(c[0]) > 0 {
+		rows = len(c[0])
+		cols = len(c[0][0])
+	}
+	t := NewTensor(len(c), rows, cols)
+	for i := range c {
+		MustHaveDim(c[i], rows)
+		for j := range c[i] {
+			MustHaveSize(c[i][j], cols)
+			copy(t.data[(i*rows+j)*cols:], c[i][j])
+		}
+	}
+	return t
+}
+
+// Tensor returns a tensor of rank 2 sharing the data of the dense matrix.
+func (d Dense) Tensor() Tensor {
+	t := Tensor{
+		data:    d.data,
+		shape:   []int{d.rows, d.cols},
+		strides: []int{d.stride, 1},
+	}
+	if d.transposed {
+		return t.T()
+	}
+	return t
+}
+
+// String prints the tensor in an easily readable form.
+func (t Tensor) String() string {
+	builder := strings.Builder{}
+	builder.WriteString(fmt.Sprintf("%v", t.shape))
+	builder.WriteString(fmt.Sprintf("%v", Vector(t.Data())))
+	return builder.String()
+}
@@ -0,0 +1,220 @@
+package xmath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// seq creates a tensor of the given shape with the values 0,1,2,... in row-major order.
+func seq(shape ...int) Tensor {
+	t := NewTensor(shape...)
+	for i := range t.data {
+		t.data[i] = float64(i)
+	}
+	return t
+}
+
+func TestTensor_Views(t *testing.T) {
+
+	type test struct {
+		tensor Tensor
+		shape  []int
+		data   []float64
+	}
+
+	tests := map[string]test{
+		"reshape": {
+			tensor: seq(2, 3).Reshape(3, 2),
+			shape:  []int{3, 2},
+			data:   []float64{0, 1, 2, 3, 4, 5},
+		},
+		"reshape-inferred": {
+			tensor: seq(2, 3, 2).Reshape(-1, 4),
+			shape:  []int{3, 4},
+			data:   []float64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11},
+		},
+		"transpose": {
+			tensor: seq(2, 3).T(),
+			shape:  []int{3, 2},
+			data:   []float64{0, 3, 1, 4, 2, 5},
+		},
+		"transpose-axes": {
+			tensor: seq(2, 2, 2).Transpose(1, 0, 2),
+			shape:  []int{2, 2, 2},
+			data:   []float64{0, 1, 4, 5, 2, 3, 6, 7},
+		},
+		"reshape-transposed": {
+			tensor: seq(2, 3).T().Reshape(6),
+			shape:  []int{6},
+			data:   []float64{0, 3, 1, 4, 2, 5},
+		},
+		"slice": {
+			tensor: seq(3, 3).Slice(1, 1, 3),
+			shape:  []int{3, 2},
+			data:   []float64{1, 2, 4, 5, 7, 8},
+		},
+		"slice-negative-axis": {
+			tensor: seq(3, 3).Slice(-2, 2, 3),
+			shape:  []int{1, 3},
+			data:   []float64{6, 7, 8},
+		},
+		"index": {
+			tensor: seq(2, 3, 2).Index(1, 2),
+			shape:  []int{2, 2},
+			data:   []float64{4, 5, 10, 11},
+		},
+		"broadcast-row": {
+			tensor: seq(3).Broadcast(2, 3),
+			shape:  []int{2, 3},
+			data:   []float64{0, 1, 2, 0, 1, 2},
+		},
+		"broadcast-column": {
+			tensor: seq(2, 1).Broadcast(2, 3),
+			shape:  []int{2, 3},
+			data:   []float64{0, 0, 0, 1, 1, 1},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.shape, tt.tensor.Shape())
+			assert.Equal(t, tt.data, tt.tensor.Data())
+			assert.Equal(t, tt.data, tt.tensor.Copy().Data())
+		})
+	}
+
+}
+
+func TestTensor_ViewsShareData(t *testing.T) {
+
+	x := seq(2, 3)
+
+	x.T().Set(-1, 2, 1)
+	assert.Equal(t, -1.0, x.At(1, 2))
+
+	x.Slice(0, 1, 2).Set(-2, 0, 0)
+	assert.Equal(t, -2.0, x.At(1, 0))
+
+	x.Reshape(6).Set(-3, 0)
+	assert.Equal(t, -3.0, x.At(0, 0))
+
+	// copies are detached
+	x.Copy().Set(100, 0, 1)
+	assert.Equal(t, 1.0, x.At(0, 1))
+
+	assert.Panics(t, func() {
+		x.At(2, 0)
+	})
+	assert.Panics(t, func() {
+		x.Reshape(4)
+	})
+	assert.Panics(t, func() {
+		x.Broadcast(3, 2)
+	})
+
+}
+
+func TestTensor_Broadcasting(t *testing.T) {
+
+	type test struct {
+		a, b  Tensor
+		shape []int
+		data  []float64
+	}
+
+	tests := map[string]test{
+		"same-shape": {
+			a:     seq(2, 2),
+			b:     seq(2, 2),
+			shape: []int{2, 2},
+			data:  []float64{0, 2, 4, 6},
+		},
+		"scalar": {
+			a:     seq(2, 2),
+			b:     TensorOf([]float64{10}),
+			shape: []int{2, 2},
+			data:  []float64{10, 11, 12, 13},
+		},
+		"row": {
+			a:     seq(2, 3),
+			b:     seq(3),
+			shape: []int{2, 3},
+			data:  []float64{0, 2, 4, 3, 5, 7},
+		},
+		"outer": {
+			a:     seq(2, 1),
+			b:     seq(1, 3),
+			shape: []int{2, 3},
+			data:  []float64{0, 1, 2, 1, 2, 3},
+		},
+		"rank-3": {
+			a:     seq(2, 1, 2),
+			b:     seq(3, 1),
+			shape: []int{2, 3, 2},
+			data:  []float64{0, 1, 1, 2, 2, 3, 2, 3, 3, 4, 4, 5},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := tt.a.Add(tt.b)
+			assert.Equal(t, tt.shape, c.Shape())
+			assert.Equal(t, tt.data, c.Data())
+			// addition is commutative
+			assert.Equal(t, tt.data, tt.b.Add(tt.a).Data())
+		})
+	}
+
+	assert.Panics(t, func() {
+		seq(2, 3).Add(seq(2))
+	})
+
+}
+
+func TestTensor_Reductions(t *testing.T) {
+
+	x := seq(2, 3)
+
+	assert.Equal(t, []float64{3, 5, 7}, x.Sum(0).Data())
+	assert.Equal(t, []float64{3, 12}, x.Sum(1).Data())
+	assert.Equal(t, []float64{3, 12}, x.Sum(-1).Data())
+	assert.Equal(t, []float64{1, 4}, x.Mean(1).Data())
+	assert.Equal(t, []float64{3, 4, 5}, x.Max(0).Data())
+	assert.Equal(t, []float64{0, 3}, x.Min(1).Data())
+
+	y := seq(2, 3, 4)
+	s := y.Sum(1)
+	assert.Equal(t, []int{2, 4}, s.Shape())
+	assert.Equal(t, 0.0+4+8, s.At(0, 0))
+	assert.Equal(t, 15.0+19+23, s.At(1, 3))
+
+	// reducing all axes results in a scalar
+	all := x.Sum(0).Sum(0)
+	assert.Equal(t, 0, all.Rank())
+	assert.Equal(t, 15.0, all.At())
+
+}
+
+func TestTensor_Conversions(t *testing.T) {
+
+	v := Vec(3).With(1, 2, 3)
+	assert.Equal(t, v, v.Tensor().Vector())
+
+	m := testMatrix()
+	assert.Equal(t, m, m.Tensor().Matrix())
+	assert.Equal(t, m.T(), m.Tensor().T().Matrix())
+
+	c := Cube{m, m.Mult(2)}
+	assert.Equal(t, c, c.Tensor().Cube())
+	assert.Equal(t, m.Mult(2), c.Tensor().Index(0, 1).Matrix())
+
+	d := DenseOf(m)
+	assert.Equal(t, m, d.Tensor().Matrix())
+	assert.Equal(t, m.T(), d.T().Tensor().Matrix())
+
+	assert.Panics(t, func() {
+		m.Tensor().Vector()
+	})
+
+}